@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/spf13/viper"
 	"log"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +14,40 @@ type Config struct {
 	RedisHost string
 	RedisPort string
 	RedisPass string
+	GRPCPort  string
+
+	DashboardCacheTTL time.Duration
+	IdempotencyTTL    time.Duration
+
+	BatchMaxOperations int
+
+	WebhookMaxAttempts  int
+	WebhookRetryBase    time.Duration
+	WebhookPollInterval time.Duration
+	WebhookTimeout      time.Duration
+
+	// RequestTimeout is the deadline of each REST request and gRPC call, queries still
+	// running when it passes are cancelled; 0 disables it
+	RequestTimeout time.Duration
+
+	// ShutdownTimeout is how long in-flight requests get to finish after SIGTERM
+	ShutdownTimeout    time.Duration
+	HealthCheckTimeout time.Duration
+
+	// LogLevel is debug, info, warn or error; debug includes every SQL statement
+	LogLevel string
+
+	// MetricsPort serves /metrics on a port of its own instead of PORT
+	MetricsPort string
+	// MetricsUser and MetricsPassword put /metrics behind basic auth when set
+	MetricsUser     string
+	MetricsPassword string
+
+	// TracingExporter is otlp, stdout or none
+	TracingExporter string
+	// TracingEndpoint is the OTLP gRPC collector URL, e.g. http://otel-collector:4317
+	TracingEndpoint    string
+	TracingSampleRatio float64
 }
 
 func LoadConfig() Config {
@@ -21,6 +56,20 @@ func LoadConfig() Config {
 	viper.AddConfigPath(".")  // current dir (misal saat run dari main.go)
 	viper.AddConfigPath("..") // parent dir (saat test di subfolder)
 	viper.AutomaticEnv()
+	viper.SetDefault("DASHBOARD_CACHE_TTL", "5m")
+	viper.SetDefault("GRPC_PORT", "9090")
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("BATCH_MAX_OPERATIONS", 100)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_RETRY_BASE", "30s")
+	viper.SetDefault("WEBHOOK_POLL_INTERVAL", "5s")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("REQUEST_TIMEOUT", "30s")
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "30s")
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Println("Warning: .env not found, trying .env.test")
@@ -41,5 +90,31 @@ func LoadConfig() Config {
 		RedisHost: viper.GetString("REDIS_HOST"),
 		RedisPort: viper.GetString("REDIS_PORT"),
 		RedisPass: viper.GetString("REDIS_PASS"),
+		GRPCPort:  viper.GetString("GRPC_PORT"),
+
+		DashboardCacheTTL: viper.GetDuration("DASHBOARD_CACHE_TTL"),
+		IdempotencyTTL:    viper.GetDuration("IDEMPOTENCY_TTL"),
+
+		BatchMaxOperations: viper.GetInt("BATCH_MAX_OPERATIONS"),
+
+		WebhookMaxAttempts:  viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		WebhookRetryBase:    viper.GetDuration("WEBHOOK_RETRY_BASE"),
+		WebhookPollInterval: viper.GetDuration("WEBHOOK_POLL_INTERVAL"),
+		WebhookTimeout:      viper.GetDuration("WEBHOOK_TIMEOUT"),
+
+		RequestTimeout: viper.GetDuration("REQUEST_TIMEOUT"),
+
+		ShutdownTimeout:    viper.GetDuration("SHUTDOWN_TIMEOUT"),
+		HealthCheckTimeout: viper.GetDuration("HEALTH_CHECK_TIMEOUT"),
+
+		LogLevel: viper.GetString("LOG_LEVEL"),
+
+		MetricsPort:     viper.GetString("METRICS_PORT"),
+		MetricsUser:     viper.GetString("METRICS_USER"),
+		MetricsPassword: viper.GetString("METRICS_PASSWORD"),
+
+		TracingExporter:    viper.GetString("TRACING_EXPORTER"),
+		TracingEndpoint:    viper.GetString("TRACING_OTLP_ENDPOINT"),
+		TracingSampleRatio: viper.GetFloat64("TRACING_SAMPLE_RATIO"),
 	}
 }
@@ -35,30 +35,30 @@ type RefreshTokenRequest struct {
 // @Produce json
 // @Param credentials body models.LoginRequest true "User credentials"
 // @Success 200 {object} map[string]string
-// @Failure 400,401 {object} utils.StandardErrorResponse
+// @Failure 400,401 {object} utils.Problem
 // @Router /login [post]
 func (c *AuthController) Login(ctx *fiber.Ctx) error {
 	var input models.LoginRequest
 	if err := ctx.BodyParser(&input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 	if err := c.validate.Struct(input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", utils.FormatValidationErrors(err.(validator.ValidationErrors)))
+		return validationFailed(utils.FormatValidationErrors(err.(validator.ValidationErrors)))
 	}
 
-	user, err := c.service.Login(ctx.Context(), input.Username, input.Password)
+	user, err := c.service.Login(ctx.UserContext(), input.Username, input.Password)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 401, "Unauthorized", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	accessToken, err := c.service.GenerateAccessToken(user)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Token generation failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	refreshToken, err := c.service.GenerateRefreshToken(user)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Refresh token generation failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Login successful", fiber.Map{
@@ -74,23 +74,20 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 // @Produce json
 // @Param input body models.RegisterRequest true "User registration"
 // @Success 201 {object} models.User
-// @Failure 400,409,500 {object} utils.StandardErrorResponse
+// @Failure 400,409,500 {object} utils.Problem
 // @Router /register [post]
 func (c *AuthController) Register(ctx *fiber.Ctx) error {
 	var input models.RegisterRequest
 	if err := ctx.BodyParser(&input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", nil)
+		return invalidInput(err)
 	}
 	if err := c.validate.Struct(input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", utils.FormatValidationErrors(err.(validator.ValidationErrors)))
+		return validationFailed(utils.FormatValidationErrors(err.(validator.ValidationErrors)))
 	}
 
-	user, err := c.service.Register(ctx.Context(), &input)
+	user, err := c.service.Register(ctx.UserContext(), &input)
 	if err != nil {
-		if strings.Contains(err.Error(), "username already taken") || strings.Contains(err.Error(), "email already taken") {
-			return utils.ErrorResponse(ctx, 409, "Conflict", []utils.ErrorDetail{{Message: err.Error()}})
-		}
-		return utils.ErrorResponse(ctx, 500, "Registration failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 201, "User registered", user)
@@ -101,7 +98,7 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 // @Tags Auth
 // @Produce json
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} utils.StandardErrorResponse
+// @Failure 401 {object} utils.Problem
 // @Router /me [get]
 // @Security BearerAuth
 func (c *AuthController) Me(ctx *fiber.Ctx) error {
@@ -109,7 +106,7 @@ func (c *AuthController) Me(ctx *fiber.Ctx) error {
 	firstName := ctx.Locals("first_name")
 
 	if userID == nil || firstName == nil {
-		return utils.ErrorResponse(ctx, 401, "Unauthorized", nil)
+		return services.Unauthorized(services.CodeUnauthorized, "Unauthorized")
 	}
 
 	return utils.SuccessResponse(ctx, 200, "User info retrieved", fiber.Map{
@@ -123,13 +120,13 @@ func (c *AuthController) Me(ctx *fiber.Ctx) error {
 // @Tags Auth
 // @Produce json
 // @Success 200 {object} fiber.Map
-// @Failure 400,401 {object} utils.StandardErrorResponse
+// @Failure 400,401 {object} utils.Problem
 // @Router /logout [post]
 // @Security BearerAuth
 func (c *AuthController) Logout(ctx *fiber.Ctx) error {
 	header := ctx.Get("Authorization")
 	if header == "" || !strings.HasPrefix(header, "Bearer ") {
-		return utils.ErrorResponse(ctx, 400, "Invalid token format", nil)
+		return services.Invalid(services.CodeInvalidToken, "Invalid token format", utils.ErrorDetail{Field: "Authorization", Message: "must be a Bearer token"})
 	}
 	tokenStr := strings.TrimPrefix(header, "Bearer ")
 
@@ -137,15 +134,15 @@ func (c *AuthController) Logout(ctx *fiber.Ctx) error {
 		return []byte(c.service.GetSecret()), nil
 	})
 	if err != nil || !token.Valid {
-		return utils.ErrorResponse(ctx, 401, "Invalid token", nil)
+		return services.Unauthorized(services.CodeInvalidToken, "Invalid token")
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
 	expUnix := int64(claims["exp"].(float64))
 	expDuration := time.Until(time.Unix(expUnix, 0))
 
-	if err := c.service.Logout(ctx.Context(), tokenStr, expDuration); err != nil {
-		return utils.ErrorResponse(ctx, 500, "Logout failed", []utils.ErrorDetail{{Message: err.Error()}})
+	if err := c.service.Logout(ctx.UserContext(), tokenStr, expDuration); err != nil {
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Logout successful", nil)
@@ -158,20 +155,20 @@ func (c *AuthController) Logout(ctx *fiber.Ctx) error {
 // @Produce json
 // @Param refresh_token body RefreshTokenRequest true "Refresh token"
 // @Success 200 {object} map[string]string
-// @Failure 400,401,500 {object} utils.StandardErrorResponse
+// @Failure 400,401,500 {object} utils.Problem
 // @Router /refresh [post]
 func (c *AuthController) Refresh(ctx *fiber.Ctx) error {
 	var req RefreshTokenRequest
 	if err := ctx.BodyParser(&req); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", nil)
+		return invalidInput(err)
 	}
 	if err := c.validate.Struct(req); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", utils.FormatValidationErrors(err.(validator.ValidationErrors)))
+		return validationFailed(utils.FormatValidationErrors(err.(validator.ValidationErrors)))
 	}
 
-	token, err := c.service.RefreshToken(ctx.Context(), req.RefreshToken)
+	token, err := c.service.RefreshToken(ctx.UserContext(), req.RefreshToken)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 401, err.Error(), nil)
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Token refreshed", fiber.Map{"access_token": token})
@@ -184,16 +181,16 @@ func (c *AuthController) Refresh(ctx *fiber.Ctx) error {
 // @Produce json
 // @Param email body map[string]string true "Email"
 // @Success 200 {object} fiber.Map
-// @Failure 400 {object} utils.StandardErrorResponse
+// @Failure 400 {object} utils.Problem
 // @Router /send-otp [post]
 func (c *AuthController) SendOTP(ctx *fiber.Ctx) error {
 	var body map[string]string
 	if err := ctx.BodyParser(&body); err != nil || body["email"] == "" {
-		return utils.ErrorResponse(ctx, 400, "Email is required", nil)
+		return services.Invalid(services.CodeValidationFailed, "Email is required", utils.ErrorDetail{Field: "email", Message: "is required"})
 	}
 
-	if err := c.service.SendResetOTP(ctx.Context(), body["email"]); err != nil {
-		return utils.ErrorResponse(ctx, 400, err.Error(), nil)
+	if err := c.service.SendResetOTP(ctx.UserContext(), body["email"]); err != nil {
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "OTP sent to email", nil)
@@ -206,17 +203,20 @@ func (c *AuthController) SendOTP(ctx *fiber.Ctx) error {
 // @Produce json
 // @Param input body map[string]string true "Email and OTP"
 // @Success 200 {object} fiber.Map
-// @Failure 400 {object} utils.StandardErrorResponse
+// @Failure 400 {object} utils.Problem
 // @Router /verify-otp [post]
 func (c *AuthController) VerifyOTP(ctx *fiber.Ctx) error {
 	var body map[string]string
 	if err := ctx.BodyParser(&body); err != nil || body["email"] == "" || body["otp"] == "" {
-		return utils.ErrorResponse(ctx, 400, "Email and OTP are required", nil)
+		return services.Invalid(services.CodeValidationFailed, "Email and OTP are required")
 	}
 
-	ok, err := c.service.VerifyResetOTP(ctx.Context(), body["email"], body["otp"])
-	if err != nil || !ok {
-		return utils.ErrorResponse(ctx, 400, "Invalid OTP", nil)
+	ok, err := c.service.VerifyResetOTP(ctx.UserContext(), body["email"], body["otp"])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return services.Invalid(services.CodeInvalidOTP, "Invalid OTP")
 	}
 
 	return utils.SuccessResponse(ctx, 200, "OTP verified", nil)
@@ -229,16 +229,16 @@ func (c *AuthController) VerifyOTP(ctx *fiber.Ctx) error {
 // @Produce json
 // @Param input body map[string]string true "Email and new password"
 // @Success 200 {object} fiber.Map
-// @Failure 400 {object} utils.StandardErrorResponse
+// @Failure 400 {object} utils.Problem
 // @Router /reset-password [post]
 func (c *AuthController) ResetPassword(ctx *fiber.Ctx) error {
 	var body map[string]string
 	if err := ctx.BodyParser(&body); err != nil || body["email"] == "" || body["new_password"] == "" {
-		return utils.ErrorResponse(ctx, 400, "Email and new password are required", nil)
+		return services.Invalid(services.CodeValidationFailed, "Email and new password are required")
 	}
 
-	if err := c.service.ResetPassword(ctx.Context(), body["email"], body["new_password"]); err != nil {
-		return utils.ErrorResponse(ctx, 400, err.Error(), nil)
+	if err := c.service.ResetPassword(ctx.UserContext(), body["email"], body["new_password"]); err != nil {
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Password reset successful", nil)
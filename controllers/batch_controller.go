@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/azizmasr1001/go-api-northwind/middlewares"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const batchPrefix = "/api"
+
+// batchLocals are copied from the batch request so every operation runs as the caller
+var batchLocals = []string{"user_id", "first_name", "role"}
+
+var errBatchRollback = errors.New("batch rolled back")
+
+// BatchMount registers the routes a batch may call on router, served by svc
+type BatchMount func(router fiber.Router, svc services.Services)
+
+type BatchController struct {
+	uow      services.UnitOfWork
+	mount    BatchMount
+	maxOps   int
+	validate *validator.Validate
+}
+
+func NewBatchController(uow services.UnitOfWork, mount BatchMount, maxOps int) *BatchController {
+	return &BatchController{
+		uow:      uow,
+		mount:    mount,
+		maxOps:   maxOps,
+		validate: validator.New(),
+	}
+}
+
+// Batch godoc
+// @Summary Run several operations in one request
+// @Description Replays each operation against the employee, category, product and order routes, with the caller's token and role.
+// @Description Paths may leave out the /api prefix. A PATCH body that is an array is sent as JSON Patch, anything else as JSON.
+// @Description atomic=true runs everything in one transaction and stops at the first failure, otherwise every operation stands alone.
+// @Tags Batch
+// @Accept json
+// @Produce json
+// @Param batch body models.BatchRequest true "Operations to run"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
+// @Success 200 {object} models.BatchResult
+// @Failure 400,409,413,422,500 {object} utils.Problem
+// @Router /batch [post]
+// @Security BearerAuth
+func (c *BatchController) Batch(ctx *fiber.Ctx) error {
+	req, validationErrs, err := utils.BindAndValidate[models.BatchRequest](ctx, c.validate)
+	if err != nil {
+		return invalidInput(err)
+	}
+	if validationErrs != nil {
+		return validationFailed(validationErrs)
+	}
+	if len(req.Operations) > c.maxOps {
+		return services.NewError(services.KindTooLarge, services.CodeTooManyOperations, "Too many operations",
+			utils.ErrorDetail{Field: "operations", Message: fmt.Sprintf("at most %d operations per batch", c.maxOps)})
+	}
+
+	caller := make(map[string]interface{}, len(batchLocals))
+	for _, key := range batchLocals {
+		caller[key] = ctx.Locals(key)
+	}
+
+	result := models.BatchResult{Atomic: req.Atomic, Results: []models.BatchOperationResult{}}
+	run := func(svc services.Services) error {
+		handler := c.handler(ctx.UserContext(), svc, caller)
+		for i, op := range req.Operations {
+			res := dispatch(handler, i, op)
+			result.Results = append(result.Results, res)
+			if res.Status < 400 {
+				result.Succeeded++
+				continue
+			}
+			result.Failed++
+			if req.Atomic {
+				return errBatchRollback
+			}
+		}
+		return nil
+	}
+
+	if !req.Atomic {
+		_ = run(c.uow.Services())
+	} else if err := c.uow.Transaction(ctx.UserContext(), run); err != nil {
+		if errors.Is(err, errBatchRollback) {
+			return batchRejected(ctx, result.Results[len(result.Results)-1])
+		}
+		return err
+	}
+
+	result.Committed = true
+	return utils.SuccessResponse(ctx, 200, "Batch completed", result)
+}
+
+// handler builds an in-process app serving the batch routes from svc. Every
+// operation runs with the caller's locals and parent, the batch request's context.
+func (c *BatchController) handler(parent context.Context, svc services.Services, caller map[string]interface{}) fasthttp.RequestHandler {
+	app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler})
+	app.Use(func(ctx *fiber.Ctx) error {
+		for key, value := range caller {
+			ctx.Locals(key, value)
+		}
+		ctx.SetUserContext(parent)
+		return ctx.Next()
+	})
+	c.mount(app.Group(batchPrefix), svc)
+	return app.Handler()
+}
+
+// dispatch runs one operation and captures its status and response envelope
+func dispatch(handler fasthttp.RequestHandler, index int, op models.BatchOperation) models.BatchOperationResult {
+	path := op.Path
+	if path != batchPrefix && !strings.HasPrefix(path, batchPrefix+"/") {
+		path = batchPrefix + path
+	}
+
+	var req fasthttp.Request
+	req.Header.SetMethod(op.Method)
+	req.SetRequestURI(path)
+	if body := op.Body; len(body) > 0 && string(body) != "null" {
+		req.SetBody(body)
+		req.Header.SetContentType(fiber.MIMEApplicationJSON)
+		if op.Method == fiber.MethodPatch && strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+			req.Header.SetContentType(utils.MIMEJSONPatch)
+		}
+	}
+
+	var fctx fasthttp.RequestCtx
+	fctx.Init(&req, nil, nil)
+	handler(&fctx)
+
+	res := models.BatchOperationResult{
+		Index:  index,
+		Method: op.Method,
+		Path:   op.Path,
+		Status: fctx.Response.StatusCode(),
+	}
+	if body := fctx.Response.Body(); len(body) > 0 {
+		if json.Valid(body) {
+			res.Body = append(json.RawMessage(nil), body...)
+		} else {
+			// CSV and other non-JSON answers are returned as a string
+			res.Body, _ = json.Marshal(string(body))
+		}
+	}
+	return res
+}
+
+// batchRejected reports the operation that rolled an atomic batch back, like importRejected
+func batchRejected(ctx *fiber.Ctx, failed models.BatchOperationResult) error {
+	var problem utils.Problem
+	_ = json.Unmarshal(failed.Body, &problem)
+
+	field := fmt.Sprintf("operations[%d]", failed.Index)
+	details := []utils.ErrorDetail{{
+		Field:   field,
+		Message: fmt.Sprintf("%s %s returned %d: %s", failed.Method, failed.Path, failed.Status, problem.Title),
+	}}
+	for _, e := range problem.Errors {
+		if e.Field != "" {
+			e.Field = field + "." + e.Field
+		} else {
+			e.Field = field
+		}
+		details = append(details, e)
+	}
+	return services.Unprocessable(services.CodeBatchRolledBack, "Batch rolled back, nothing was written", details...)
+}
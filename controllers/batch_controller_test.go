@@ -0,0 +1,128 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/controllers"
+	"github.com/azizmasr1001/go-api-northwind/middlewares"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUnitOfWork records whether the batch committed instead of touching a database
+type fakeUnitOfWork struct {
+	committed, rolledBack bool
+}
+
+func (u *fakeUnitOfWork) Services() services.Services { return services.Services{} }
+
+func (u *fakeUnitOfWork) Transaction(_ context.Context, fn func(services.Services) error) error {
+	if err := fn(services.Services{}); err != nil {
+		u.rolledBack = true
+		return err
+	}
+	u.committed = true
+	return nil
+}
+
+func batchApp(t *testing.T, role string) (*fiber.App, *fakeUnitOfWork, *[]string) {
+	var calls []string
+	mount := func(router fiber.Router, svc services.Services) {
+		things := router.Group("/things")
+		things.Post("/", func(c *fiber.Ctx) error {
+			calls = append(calls, "create "+string(c.Body()))
+			return utils.SuccessResponse(c, 201, "Thing created", fiber.Map{"created_by": utils.GetUserID(c)})
+		})
+		things.Get("/:id", func(c *fiber.Ctx) error {
+			calls = append(calls, "get "+c.Params("id"))
+			return services.NotFound(services.CodeNotFound, "Thing not found")
+		})
+		things.Delete("/:id/purge", middlewares.RequireRole("admin"), func(c *fiber.Ctx) error {
+			calls = append(calls, "purge "+c.Params("id"))
+			return utils.SuccessResponse(c, 200, "Thing purged", nil)
+		})
+	}
+
+	uow := &fakeUnitOfWork{}
+	ctrl := controllers.NewBatchController(uow, mount, 3)
+	app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler})
+	app.Post("/api/batch", func(c *fiber.Ctx) error {
+		c.Locals("user_id", float64(42))
+		c.Locals("role", role)
+		return c.Next()
+	}, ctrl.Batch)
+	return app, uow, &calls
+}
+
+func postBatch(t *testing.T, app *fiber.App, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest("POST", "/api/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	raw, _ := io.ReadAll(resp.Body)
+
+	var envelope map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &envelope), string(raw))
+	return resp.StatusCode, envelope
+}
+
+func TestBatch_IndependentOperationsReportEachStatus(t *testing.T) {
+	app, uow, calls := batchApp(t, "user")
+
+	status, envelope := postBatch(t, app, `{"operations": [
+		{"method": "POST", "path": "/things", "body": {"name": "a"}},
+		{"method": "GET", "path": "/api/things/9"},
+		{"method": "DELETE", "path": "/things/9/purge"}
+	]}`)
+
+	assert.Equal(t, 200, status)
+	assert.False(t, uow.committed, "independent operations do not open a transaction")
+	assert.Equal(t, []string{`create {"name": "a"}`, "get 9"}, *calls)
+
+	data := envelope["data"].(map[string]interface{})
+	assert.Equal(t, float64(1), data["succeeded"])
+	assert.Equal(t, float64(2), data["failed"])
+	results := data["results"].([]interface{})
+	var statuses []float64
+	for _, r := range results {
+		statuses = append(statuses, r.(map[string]interface{})["status"].(float64))
+	}
+	assert.Equal(t, []float64{201, 404, 403}, statuses)
+
+	created := results[0].(map[string]interface{})["body"].(map[string]interface{})
+	assert.Equal(t, float64(42), created["data"].(map[string]interface{})["created_by"])
+}
+
+func TestBatch_AtomicStopsAndRollsBack(t *testing.T) {
+	app, uow, calls := batchApp(t, "admin")
+
+	status, envelope := postBatch(t, app, `{"atomic": true, "operations": [
+		{"method": "POST", "path": "/things", "body": {}},
+		{"method": "GET", "path": "/things/9"},
+		{"method": "DELETE", "path": "/things/9/purge"}
+	]}`)
+
+	assert.Equal(t, 422, status)
+	assert.True(t, uow.rolledBack)
+	assert.Len(t, *calls, 2, "operations after the failure are not run")
+	errs := envelope["errors"].([]interface{})
+	assert.Equal(t, "operations[1]", errs[0].(map[string]interface{})["field"])
+}
+
+func TestBatch_RejectsOversizedBatch(t *testing.T) {
+	app, _, calls := batchApp(t, "user")
+	op := `{"method": "GET", "path": "/things/1"}`
+
+	status, _ := postBatch(t, app, `{"operations": [`+strings.Repeat(op+",", 3)+op+`]}`)
+
+	assert.Equal(t, 413, status)
+	assert.Empty(t, *calls)
+}
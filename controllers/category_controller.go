@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/services"
 	"github.com/azizmasr1001/go-api-northwind/utils"
@@ -25,17 +27,38 @@ func NewCategoryController(service services.CategoryService) *CategoryController
 // @Description Retrieve a paginated list of product categories
 // @Tags Categories
 // @Accept json
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param page query int false "Page number"
 // @Param limit query int false "Page size"
+// @Param fields query string false "Comma separated JSON fields to return"
+// @Param format query string false "json (default), or csv or xlsx to download the full list as a file"
 // @Success 200 {object} utils.StandardListResponse
-// @Failure 500 {object} utils.StandardErrorResponse
+// @Failure 400,500 {object} utils.Problem
 // @Router /categories [get]
 // @Security BearerAuth
 func (c *CategoryController) GetAll(ctx *fiber.Ctx) error {
-	data, err := c.service.GetAll()
+	fields := utils.GetFields(ctx)
+
+	format, errs := utils.ExportFormat(ctx)
+	if errs != nil {
+		return unsupportedFormat(errs...)
+	}
+	if format != "" {
+		return utils.StreamExport(ctx, format, "categories", models.Category{}, fields, func(reqCtx context.Context, emit utils.RowEmitter) error {
+			return c.service.StreamAll(reqCtx, fields.SelectColumns(), func(cat models.Category) error {
+				return emit(cat)
+			})
+		})
+	}
+
+	categories, err := c.service.GetAll(ctx.UserContext(), fields.SelectColumns()...)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Failed to fetch categories", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
+	}
+
+	data, err := fields.Pick(categories)
+	if err != nil {
+		return err
 	}
 	return utils.ListResponse(ctx, 200, "Categories fetched successfully", data, utils.Meta{})
 }
@@ -46,19 +69,26 @@ func (c *CategoryController) GetAll(ctx *fiber.Ctx) error {
 // @Tags Categories
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param fields query string false "Comma separated JSON fields to return"
 // @Success 200 {object} models.Category
-// @Failure 400,404 {object} utils.StandardErrorResponse
+// @Failure 400,404 {object} utils.Problem
 // @Router /categories/{id} [get]
 // @Security BearerAuth
 func (c *CategoryController) GetByID(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
+	}
+	fields := utils.GetFields(ctx)
+
+	cat, err := c.service.GetByID(ctx.UserContext(), id, fields.SelectColumns()...)
+	if err != nil {
+		return notFound(err, "Category not found")
 	}
 
-	data, err := c.service.GetByID(id)
+	data, err := fields.Pick(cat)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 404, "Category not found", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 	return utils.SuccessResponse(ctx, 200, "Category fetched", data)
 }
@@ -70,22 +100,23 @@ func (c *CategoryController) GetByID(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param category body models.Category true "Category object"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
 // @Success 201 {object} models.Category
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,409,422,500 {object} utils.Problem
 // @Router /categories [post]
 // @Security BearerAuth
 func (c *CategoryController) Create(ctx *fiber.Ctx) error {
 	cat, validationErrs, err := utils.BindAndValidate[models.Category](ctx, c.validate)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 
 	if validationErrs != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", validationErrs)
+		return validationFailed(validationErrs)
 	}
-	created, err := c.service.Create(cat)
+	created, err := c.service.Create(ctx.UserContext(), cat)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Create failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 201, "Category created", created)
@@ -100,35 +131,37 @@ func (c *CategoryController) Create(ctx *fiber.Ctx) error {
 // @Param id path int true "Category ID"
 // @Param category body models.Category true "Updated category object"
 // @Success 200 {object} models.Category
-// @Failure 400,404,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,500 {object} utils.Problem
 // @Router /categories/{id} [put]
 // @Security BearerAuth
 func (c *CategoryController) Update(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
-	}
-
-	_, err = c.service.GetByID(id)
-	if err != nil {
-		return utils.ErrorResponse(ctx, 404, "Category not found", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
 
 	// Parse + Validate
 	input, validationErrs, err := utils.BindAndValidate[models.Category](ctx, c.validate)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 	if validationErrs != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", validationErrs)
+		return validationFailed(validationErrs)
 	}
 
 	// Force ID from URL to match body
 	input.CategoryID = id
-	updated, err := c.service.Update(input)
-
+	var updated models.Category
+	err = c.service.Locked(ctx.UserContext(), id, func(svc services.CategoryService) error {
+		_, err := svc.GetByID(ctx.UserContext(), id)
+		if err != nil {
+			return notFound(err, "Category not found")
+		}
+		updated, err = svc.Update(ctx.UserContext(), input)
+		return err
+	})
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Update failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Category updated", updated)
@@ -136,23 +169,83 @@ func (c *CategoryController) Update(ctx *fiber.Ctx) error {
 
 // DeleteCategory godoc
 // @Summary Delete a category
-// @Description Delete a category by ID
+// @Description Move the category to the trash, it can be restored until an admin purges it
 // @Tags Categories
 // @Produce json
 // @Param id path int true "Category ID"
 // @Success 200 {object} fiber.Map
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,500 {object} utils.Problem
 // @Router /categories/{id} [delete]
 // @Security BearerAuth
 func (c *CategoryController) Delete(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
 
-	if err = c.service.Delete(id); err != nil {
-		return utils.ErrorResponse(ctx, 500, "Delete failed", []utils.ErrorDetail{{Message: err.Error()}})
+	if err = c.service.Delete(ctx.UserContext(), id, utils.GetUserID(ctx)); err != nil {
+		return notFound(err, "Category not found")
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Category deleted", nil)
 }
+
+// ImportCategories godoc
+// @Summary Bulk import categories
+// @Description Upsert categories from a JSON array or a CSV file (header row uses the JSON field names)
+// @Tags Categories
+// @Accept json,text/csv
+// @Produce json
+// @Param rows body []models.Category true "Rows to import"
+// @Param dry_run query bool false "Validate and report without writing"
+// @Param atomic query bool false "All rows in one transaction (default true), false imports only the valid rows"
+// @Param key query string false "Upsert key: id (default) or name"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
+// @Success 200 {object} models.ImportResult
+// @Failure 400,409,422,500 {object} utils.Problem
+// @Router /categories/import [post]
+// @Security BearerAuth
+func (c *CategoryController) Import(ctx *fiber.Ctx) error {
+	return runImport[models.Category](ctx, c.validate, c.service.Import)
+}
+
+// CategoryTrash godoc
+// @Summary List deleted categories
+// @Tags Categories
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /categories/trash [get]
+// @Security BearerAuth
+func (c *CategoryController) Trash(ctx *fiber.Ctx) error {
+	return listTrash(ctx, "categories", c.service.Trash)
+}
+
+// RestoreCategory godoc
+// @Summary Restore a deleted category
+// @Tags Categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,404,500 {object} utils.Problem
+// @Router /categories/{id}/restore [post]
+// @Security BearerAuth
+func (c *CategoryController) Restore(ctx *fiber.Ctx) error {
+	return restoreFromTrash(ctx, "Category", c.service.Restore)
+}
+
+// PurgeCategory godoc
+// @Summary Permanently delete a category
+// @Description Admin only. The category must be in the trash already
+// @Tags Categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,401,403,404,500 {object} utils.Problem
+// @Router /categories/{id}/purge [delete]
+// @Security BearerAuth
+func (c *CategoryController) Purge(ctx *fiber.Ctx) error {
+	return purgeFromTrash(ctx, "Category", c.service.Purge)
+}
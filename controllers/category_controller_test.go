@@ -0,0 +1,122 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCategory_CRUD(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+
+	res := client.Post("/api/categories", map[string]string{"category_name": "Snacks", "description": "Crisps"})
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+	var created models.Category
+	res.Data(&created)
+	path := fmt.Sprintf("/api/categories/%d", created.CategoryID)
+
+	res = client.Put(path, map[string]string{"category_name": "Savoury snacks"})
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+
+	var fetched models.Category
+	res = client.Get(path)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	res.Data(&fetched)
+	assert.Equal(t, "Savoury snacks", fetched.CategoryName)
+
+	res = client.Post("/api/categories", map[string]string{"description": "no name"})
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res = client.Get("/api/categories/999")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res = env.Client(t).Get(path)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestCategory_TrashRestorePurge(t *testing.T) {
+	env := apptest.New(t)
+	user := env.AsUser(t)
+	category := env.Category(t)
+	path := fmt.Sprintf("/api/categories/%d", category.CategoryID)
+
+	require.Equal(t, http.StatusOK, user.Delete(path).StatusCode)
+	assert.Equal(t, http.StatusNotFound, user.Get(path).StatusCode)
+
+	var trash []models.Category
+	res := user.Get("/api/categories/trash")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	res.Data(&trash)
+	require.Len(t, trash, 1)
+	assert.Equal(t, category.CategoryID, trash[0].CategoryID)
+
+	require.Equal(t, http.StatusOK, user.Post(path+"/restore", nil).StatusCode)
+	assert.Equal(t, http.StatusOK, user.Get(path).StatusCode)
+
+	require.Equal(t, http.StatusOK, user.Delete(path).StatusCode)
+	assert.Equal(t, http.StatusForbidden, user.Delete(path+"/purge").StatusCode)
+
+	admin := env.AsAdmin(t)
+	assert.Equal(t, http.StatusOK, admin.Delete(path+"/purge").StatusCode)
+	assert.Equal(t, http.StatusNotFound, admin.Post(path+"/restore", nil).StatusCode)
+}
+
+func TestCategory_ExportCSV(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	category := env.Category(t)
+
+	// the rows are read once fiber has released the request, after the handler returned
+	res := client.Get("/api/categories?format=csv&fields=category_id,category_name")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Equal(t, fmt.Sprintf("category_id,category_name\n%d,%s\n", category.CategoryID, category.CategoryName), string(res.Body))
+}
+
+func TestCategory_ExportFormats(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	env.Category(t)
+
+	res := client.Get("/api/categories?format=json")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Contains(t, res.Header.Get("Content-Type"), "json")
+
+	res = client.Get("/api/categories?format=pdf")
+	require.Equal(t, http.StatusBadRequest, res.StatusCode, string(res.Body))
+	assert.Contains(t, string(res.Body), `"format"`)
+}
+
+func TestCategory_PutDoesNotRestoreATrashedRow(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	category := env.Category(t)
+	path := fmt.Sprintf("/api/categories/%d", category.CategoryID)
+
+	// the PUT's existence check is slow, the DELETE lands between it and the write
+	err := env.DB.Callback().Query().After("gorm:query").Register("test:slow_reads", func(db *gorm.DB) {
+		if db.Statement.Table == category.TableName() {
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	require.NoError(t, err)
+
+	put := make(chan int)
+	go func() {
+		c := *client
+		put <- c.Put(path, map[string]string{"category_name": "Renamed"}).StatusCode
+	}()
+	time.Sleep(10 * time.Millisecond)
+	deleted := client.Delete(path).StatusCode
+	assert.Equal(t, http.StatusOK, <-put)
+	require.Equal(t, http.StatusOK, deleted)
+
+	assert.Equal(t, http.StatusNotFound, client.Get(path).StatusCode)
+}
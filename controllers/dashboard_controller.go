@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DashboardController struct {
+	service services.DashboardService
+}
+
+func NewDashboardController(service services.DashboardService) *DashboardController {
+	return &DashboardController{service: service}
+}
+
+// GetDashboard godoc
+// @Summary Get dashboard KPIs
+// @Description Revenue this month vs last month, order count, average order value, top products and customers, late shipments and low stock
+// @Tags Dashboard
+// @Produce json
+// @Param as_of query string false "Reference date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} models.Dashboard
+// @Failure 400,500 {object} utils.Problem
+// @Router /dashboard [get]
+// @Security BearerAuth
+func (c *DashboardController) Get(ctx *fiber.Ctx) error {
+	asOf := time.Now()
+	if raw := ctx.Query("as_of"); raw != "" {
+		t, err := time.Parse(reportDateLayout, raw)
+		if err != nil {
+			return services.Invalid(services.CodeInvalidParameter, "Invalid as_of", utils.ErrorDetail{Field: "as_of", Message: "must be a date in YYYY-MM-DD format"})
+		}
+		asOf = t
+	}
+
+	data, err := c.service.Get(ctx.UserContext(), asOf)
+	if err != nil {
+		return err
+	}
+	return utils.SuccessResponse(ctx, 200, "Dashboard retrieved", data)
+}
@@ -0,0 +1,64 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dashboardPath = "/api/dashboard?as_of=2024-03-15"
+
+func dashboard(t *testing.T, client *apptest.Client) models.Dashboard {
+	t.Helper()
+	res := client.Get(dashboardPath)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	var out models.Dashboard
+	res.Data(&out)
+	return out
+}
+
+func TestDashboard_EmptyListsAreArrays(t *testing.T) {
+	env := apptest.New(t)
+
+	res := env.AsUser(t).Get(dashboardPath)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Contains(t, string(res.Body), `"top_products":[]`)
+	assert.Contains(t, string(res.Body), `"top_customers":[]`)
+}
+
+func TestDashboard_WritesInvalidateTheCache(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	product := env.Product(t)
+	env.Order(t, []*models.Product{product})
+
+	before := dashboard(t, client)
+	assert.Equal(t, 1, before.OrderCount)
+	assert.Equal(t, 0, before.LowStockCount)
+	require.True(t, env.Miniredis.Exists("dashboard:2024-03-15"))
+
+	// a write behind the service's back is not seen while the cache is fresh
+	env.Order(t, []*models.Product{product})
+	assert.Equal(t, 1, dashboard(t, client).OrderCount)
+
+	res := client.Post("/api/orders", map[string]interface{}{
+		"employee_id":   env.Employee(t).EmployeeID,
+		"order_date":    "2024-03-10T00:00:00Z",
+		"order_details": []map[string]interface{}{{"product_id": product.ProductID, "unit_price": 10, "quantity": 2}},
+	})
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+	assert.False(t, env.Miniredis.Exists("dashboard:2024-03-15"), "an order write clears the cache")
+	assert.Equal(t, 3, dashboard(t, client).OrderCount)
+
+	client.Header.Set("Content-Type", "application/merge-patch+json")
+	res = client.Patch(fmt.Sprintf("/api/products/%d", product.ProductID), map[string]int{"units_in_stock": 1})
+	client.Header.Del("Content-Type")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.False(t, env.Miniredis.Exists("dashboard:2024-03-15"), "a product write clears the cache")
+	assert.Equal(t, 1, dashboard(t, client).LowStockCount)
+}
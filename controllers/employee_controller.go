@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/services"
 	"github.com/azizmasr1001/go-api-northwind/utils"
@@ -25,19 +27,39 @@ func NewEmployeeController(service services.EmployeeService) *EmployeeController
 // @Description Retrieve a paginated list of employees
 // @Tags Employees
 // @Accept json
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param page query int false "Page number"
 // @Param limit query int false "Page size"
+// @Param fields query string false "Comma separated JSON fields to return"
+// @Param format query string false "json (default), or csv or xlsx to download the full list as a file"
 // @Success 200 {object} utils.StandardListResponse
-// @Failure 500 {object} utils.StandardErrorResponse
+// @Failure 400,500 {object} utils.Problem
 // @Router /employees [get]
 // @Security BearerAuth
 func (c *EmployeeController) GetAll(ctx *fiber.Ctx) error {
 	page, limit := utils.GetPagination(ctx)
+	fields := utils.GetFields(ctx)
 
-	data, total, err := c.service.GetAllPaginated(page, limit)
+	format, errs := utils.ExportFormat(ctx)
+	if errs != nil {
+		return unsupportedFormat(errs...)
+	}
+	if format != "" {
+		return utils.StreamExport(ctx, format, "employees", models.Employee{}, fields, func(reqCtx context.Context, emit utils.RowEmitter) error {
+			return c.service.StreamAll(reqCtx, fields.SelectColumns(), func(emp models.Employee) error {
+				return emit(emp)
+			})
+		})
+	}
+
+	employees, total, err := c.service.GetAllPaginated(ctx.UserContext(), page, limit, fields.SelectColumns()...)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Failed to retrieve data", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
+	}
+
+	data, err := fields.Pick(employees)
+	if err != nil {
+		return err
 	}
 
 	meta := utils.Meta{
@@ -55,21 +77,28 @@ func (c *EmployeeController) GetAll(ctx *fiber.Ctx) error {
 // @Tags Employees
 // @Produce json
 // @Param id path int true "Employee ID"
+// @Param fields query string false "Comma separated JSON fields to return"
 // @Success 200 {object} models.Employee
-// @Failure 400,404 {object} utils.StandardErrorResponse
+// @Failure 400,404 {object} utils.Problem
 // @Router /employees/{id} [get]
 // @Security BearerAuth
 func (c *EmployeeController) GetByID(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
+	}
+	fields := utils.GetFields(ctx)
+
+	emp, err := c.service.GetByID(ctx.UserContext(), id, fields.SelectColumns()...)
+	if err != nil {
+		return notFound(err, "Employee not found")
 	}
 
-	emp, err := c.service.GetByID(id)
+	data, err := fields.Pick(emp)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 404, "Employee not found", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
-	return utils.SuccessResponse(ctx, 200, "Data retrieved successfully", emp)
+	return utils.SuccessResponse(ctx, 200, "Data retrieved successfully", data)
 }
 
 // CreateEmployee godoc
@@ -79,86 +108,182 @@ func (c *EmployeeController) GetByID(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param employee body models.Employee true "Employee object"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
 // @Success 201 {object} models.Employee
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,409,422,500 {object} utils.Problem
 // @Router /employees [post]
 // @Security BearerAuth
 func (c *EmployeeController) Create(ctx *fiber.Ctx) error {
 	emp, validationErrs, err := utils.BindAndValidate[models.Employee](ctx, c.validate)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 
 	if validationErrs != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", validationErrs)
+		return validationFailed(validationErrs)
 	}
-	created, err := c.service.Create(emp)
+	created, err := c.service.Create(ctx.UserContext(), emp)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Failed to create employee", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 201, "Employee created successfully", created)
 }
 
 // UpdateEmployee godoc
-// @Summary Update an employee
-// @Description Update employee information by ID
+// @Summary Replace an employee
+// @Description Replace employee information by ID, the ID is always taken from the path
 // @Tags Employees
 // @Accept json
 // @Produce json
 // @Param id path int true "Employee ID"
 // @Param employee body models.Employee true "Updated employee object"
 // @Success 200 {object} models.Employee
-// @Failure 400,404,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,500 {object} utils.Problem
 // @Router /employees/{id} [put]
 // @Security BearerAuth
 func (c *EmployeeController) Update(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
-	}
-
-	_, err = c.service.GetByID(id)
-	if err != nil {
-		return utils.ErrorResponse(ctx, 404, "Employee not found", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
 
 	input, validationErrs, err := utils.BindAndValidate[models.Employee](ctx, c.validate)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 	if validationErrs != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", validationErrs)
+		return validationFailed(validationErrs)
 	}
 
 	input.EmployeeID = id
-	updated, err := c.service.Update(input)
+	var updated models.Employee
+	err = c.service.Locked(ctx.UserContext(), id, func(svc services.EmployeeService) error {
+		_, err := svc.GetByID(ctx.UserContext(), id)
+		if err != nil {
+			return notFound(err, "Employee not found")
+		}
+		updated, err = svc.Update(ctx.UserContext(), input)
+		return err
+	})
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Update failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Employee updated successfully", updated)
 }
 
+// PatchEmployee godoc
+// @Summary Partially update an employee
+// @Description Apply a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902), only the changed fields are written
+// @Tags Employees
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param patch body object true "Merge patch object or JSON Patch operations"
+// @Success 200 {object} models.Employee
+// @Failure 400,404,415,500 {object} utils.Problem
+// @Router /employees/{id} [patch]
+// @Security BearerAuth
+func (c *EmployeeController) Patch(ctx *fiber.Ctx) error {
+	id, err := utils.ParseID(ctx)
+	if err != nil {
+		return invalidID()
+	}
+
+	return runPatch(ctx, c.validate, "Employee",
+		func(fn func(services.EmployeeService) error) error {
+			return c.service.Locked(ctx.UserContext(), id, fn)
+		},
+		func(svc services.EmployeeService) (*models.Employee, error) {
+			return svc.GetByID(ctx.UserContext(), id)
+		},
+		func(emp *models.Employee) { emp.EmployeeID = id },
+		func(svc services.EmployeeService, emp *models.Employee, columns []string) (interface{}, error) {
+			return svc.Patch(ctx.UserContext(), emp, columns)
+		},
+	)
+}
+
 // DeleteEmployee godoc
 // @Summary Delete an employee
-// @Description Remove employee record by ID
+// @Description Move the employee to the trash, it can be restored until an admin purges it
 // @Tags Employees
 // @Produce json
 // @Param id path int true "Employee ID"
 // @Success 200 {object} fiber.Map
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,500 {object} utils.Problem
 // @Router /employees/{id} [delete]
 // @Security BearerAuth
 func (c *EmployeeController) Delete(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
 
-	if err = c.service.Delete(id); err != nil {
-		return utils.ErrorResponse(ctx, 500, "Delete failed", []utils.ErrorDetail{{Message: err.Error()}})
+	if err = c.service.Delete(ctx.UserContext(), id, utils.GetUserID(ctx)); err != nil {
+		return notFound(err, "Employee not found")
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Employee deleted successfully", nil)
 }
+
+// ImportEmployees godoc
+// @Summary Bulk import employees
+// @Description Upsert employees from a JSON array or a CSV file (header row uses the JSON field names)
+// @Tags Employees
+// @Accept json,text/csv
+// @Produce json
+// @Param rows body []models.Employee true "Rows to import"
+// @Param dry_run query bool false "Validate and report without writing"
+// @Param atomic query bool false "All rows in one transaction (default true), false imports only the valid rows"
+// @Param key query string false "Upsert key: id (default) or name"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
+// @Success 200 {object} models.ImportResult
+// @Failure 400,409,422,500 {object} utils.Problem
+// @Router /employees/import [post]
+// @Security BearerAuth
+func (c *EmployeeController) Import(ctx *fiber.Ctx) error {
+	return runImport[models.Employee](ctx, c.validate, c.service.Import)
+}
+
+// EmployeeTrash godoc
+// @Summary List deleted employees
+// @Tags Employees
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /employees/trash [get]
+// @Security BearerAuth
+func (c *EmployeeController) Trash(ctx *fiber.Ctx) error {
+	return listTrash(ctx, "employees", c.service.Trash)
+}
+
+// RestoreEmployee godoc
+// @Summary Restore a deleted employee
+// @Tags Employees
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,404,500 {object} utils.Problem
+// @Router /employees/{id}/restore [post]
+// @Security BearerAuth
+func (c *EmployeeController) Restore(ctx *fiber.Ctx) error {
+	return restoreFromTrash(ctx, "Employee", c.service.Restore)
+}
+
+// PurgeEmployee godoc
+// @Summary Permanently delete a employee
+// @Description Admin only. The employee must be in the trash already
+// @Tags Employees
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,401,403,404,500 {object} utils.Problem
+// @Router /employees/{id}/purge [delete]
+// @Security BearerAuth
+func (c *EmployeeController) Purge(ctx *fiber.Ctx) error {
+	return purgeFromTrash(ctx, "Employee", c.service.Purge)
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"gorm.io/gorm"
+)
+
+// invalidInput reports a body that could not be parsed
+func invalidInput(err error) error {
+	return services.Invalid(services.CodeInvalidBody, "Invalid input", utils.ErrorDetail{Message: err.Error()})
+}
+
+func validationFailed(details []utils.ErrorDetail) error {
+	return services.Invalid(services.CodeValidationFailed, "Validation failed", details...)
+}
+
+func unsupportedFormat(details ...utils.ErrorDetail) error {
+	return services.Invalid(services.CodeInvalidParameter, "Unsupported format", details...)
+}
+
+func invalidID() error {
+	return services.Invalid(services.CodeInvalidParameter, "Invalid ID", utils.ErrorDetail{Field: "id", Message: "must be a valid positive number"})
+}
+
+// notFound answers 404 when the row does not exist, other errors are passed on
+func notFound(err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return services.NotFound(services.CodeNotFound, message).Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"sort"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// preconditionFailed answers a write whose If-Match no longer matches the stored row
+func preconditionFailed(err error) error {
+	return services.NewError(services.KindPrecondition, services.CodePreconditionFailed, "Precondition failed",
+		utils.ErrorDetail{Field: "If-Match", Message: err.Error()})
+}
+
+// orderVersion is what an order ETag is computed from: the header plus its lines
+// in a stable order
+func orderVersion(order *models.Order, details []models.OrderDetail) fiber.Map {
+	lines := append([]models.OrderDetail(nil), details...)
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+	return fiber.Map{"order": order, "details": lines}
+}
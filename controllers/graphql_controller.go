@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/gql"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+type GraphQLController struct {
+	schema   graphql.Schema
+	services services.Services
+}
+
+func NewGraphQLController(schema graphql.Schema, services services.Services) *GraphQLController {
+	return &GraphQLController{schema: schema, services: services}
+}
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Handle godoc
+// @Summary GraphQL endpoint
+// @Description Queries and mutations over employees, categories, products and orders. GET takes query, operationName and variables from the query string and runs queries only.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Param request body GraphQLRequest true "GraphQL request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} utils.Problem
+// @Router /graphql [post]
+// @Security BearerAuth
+func (c *GraphQLController) Handle(ctx *fiber.Ctx) error {
+	var req GraphQLRequest
+	if ctx.Method() == fiber.MethodGet {
+		req.Query = ctx.Query("query")
+		req.OperationName = ctx.Query("operationName")
+		if raw := ctx.Query("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+				return services.Invalid(services.CodeInvalidParameter, "Invalid variables",
+					utils.ErrorDetail{Field: "variables", Message: "must be a JSON object"})
+			}
+		}
+	} else if err := ctx.BodyParser(&req); err != nil {
+		return invalidInput(err)
+	}
+	if req.Query == "" {
+		return services.Invalid(services.CodeInvalidParameter, "Missing query")
+	}
+	if ctx.Method() == fiber.MethodGet {
+		// a GET can be triggered cross-site by a link or an image, it may only read
+		if op, ok := gql.Operation(req.Query, req.OperationName); ok && op != ast.OperationTypeQuery {
+			ctx.Set(fiber.HeaderAllow, fiber.MethodPost)
+			return fiber.NewError(fiber.StatusMethodNotAllowed, "Only queries can be sent with GET, use POST for "+op+"s")
+		}
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         c.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        gql.WithUserID(gql.WithLoaders(ctx.UserContext(), gql.NewLoaders(c.services)), utils.GetUserID(ctx)),
+	})
+	result.Errors = gql.FormatErrors(ctx.UserContext(), result.Errors)
+	return ctx.JSON(result)
+}
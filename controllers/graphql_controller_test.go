@@ -0,0 +1,87 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type graphQLResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func graphQL(t *testing.T, client *apptest.Client, query string) (*apptest.Response, graphQLResponse) {
+	t.Helper()
+	res := client.Post("/graphql", map[string]string{"query": query})
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	var out graphQLResponse
+	res.Decode(&out)
+	return res, out
+}
+
+func TestGraphQL_ErrorsAreTranslated(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+
+	res, out := graphQL(t, client, `mutation { createProduct(input: {product_name: "Chai", category_id: 9999}) { product_id } }`)
+	require.Len(t, out.Errors, 1, string(res.Body))
+	assert.Equal(t, "Referenced row does not exist", out.Errors[0].Message)
+	assert.Equal(t, "invalid_reference", out.Errors[0].Extensions["code"])
+	assert.NotContains(t, string(res.Body), "FOREIGN KEY")
+
+	_, out = graphQL(t, client, `{ category(id: 999) { category_name } }`)
+	require.Len(t, out.Errors, 1)
+	assert.Equal(t, "not_found", out.Errors[0].Extensions["code"])
+
+	_, out = graphQL(t, client, `{ categories { category_name } }`)
+	assert.Empty(t, out.Errors)
+
+	// a failure the services do not know stays in the server log
+	require.NoError(t, env.DB.Migrator().DropTable(&models.Category{}))
+	res, out = graphQL(t, client, `{ categories { category_name } }`)
+	require.Len(t, out.Errors, 1, string(res.Body))
+	assert.Equal(t, "internal error", out.Errors[0].Message)
+	assert.Equal(t, "internal_error", out.Errors[0].Extensions["code"])
+	assert.NotContains(t, string(res.Body), "no such table")
+}
+
+func TestGraphQL_GetRunsQueriesOnly(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	category := env.Category(t)
+
+	query := url.Values{
+		"query":     {`query ($id: Int!) { category(id: $id) { category_name } }`},
+		"variables": {fmt.Sprintf(`{"id": %d}`, category.CategoryID)},
+	}
+	res := client.Get("/graphql?" + query.Encode())
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Contains(t, string(res.Body), category.CategoryName)
+
+	query = url.Values{"query": {`mutation { deleteCategory(id: ` + fmt.Sprint(category.CategoryID) + `) }`}}
+	res = client.Get("/graphql?" + query.Encode())
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode, string(res.Body))
+	assert.Equal(t, http.MethodPost, res.Header.Get("Allow"))
+
+	// a mutation picked by operationName out of a document that also has a query
+	query = url.Values{
+		"query":         {`query Read { categories { category_name } } mutation Write { deleteCategory(id: 1) }`},
+		"operationName": {"Write"},
+	}
+	assert.Equal(t, http.StatusMethodNotAllowed, client.Get("/graphql?"+query.Encode()).StatusCode)
+
+	assert.Equal(t, http.StatusBadRequest, client.Get("/graphql?query={categories{category_name}}&variables=nope").StatusCode)
+
+	var stored models.Category
+	assert.NoError(t, env.DB.First(&stored, category.CategoryID).Error)
+}
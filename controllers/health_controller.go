@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/health"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthController struct {
+	timeout time.Duration
+	checks  []health.Check
+}
+
+// NewHealthController reports ready while every check answers within timeout
+func NewHealthController(timeout time.Duration, checks ...health.Check) *HealthController {
+	return &HealthController{timeout: timeout, checks: checks}
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description 200 while the process is serving requests, dependencies are not checked
+// @Tags Health
+// @Produce json
+// @Success 200 {object} fiber.Map
+// @Router /healthz [get]
+func (c *HealthController) Live(ctx *fiber.Ctx) error {
+	return ctx.JSON(fiber.Map{"status": health.StatusOK})
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Pings the database and Redis and reports each latency, 503 when any of them is down
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (c *HealthController) Ready(ctx *fiber.Ctx) error {
+	report := health.Run(ctx.UserContext(), c.timeout, c.checks...)
+	if report.Status != health.StatusOK {
+		for name, res := range report.Checks {
+			if res.Cause != nil {
+				slog.WarnContext(ctx.UserContext(), "Readiness check failed", "check", name, "error", res.Cause)
+			}
+		}
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return ctx.JSON(report)
+}
@@ -0,0 +1,48 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth_Live(t *testing.T) {
+	env := apptest.New(t)
+	env.Miniredis.Close()
+
+	// liveness does not care about dependencies
+	assert.Equal(t, http.StatusOK, env.Client(t).Get("/healthz").StatusCode)
+}
+
+func TestHealth_Ready(t *testing.T) {
+	env := apptest.New(t)
+
+	res := env.Client(t).Get("/readyz")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	var report health.Report
+	res.Decode(&report)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, health.StatusOK, report.Checks["redis"].Status)
+	assert.Contains(t, string(res.Body), `"latency_ms"`)
+}
+
+func TestHealth_NotReadyWithoutRedis(t *testing.T) {
+	env := apptest.New(t)
+	addr := env.Miniredis.Addr()
+	env.Miniredis.Close()
+
+	res := env.Client(t).Get("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode, string(res.Body))
+	var report health.Report
+	res.Decode(&report)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, health.StatusDown, report.Checks["redis"].Status)
+	assert.Equal(t, "unavailable", report.Checks["redis"].Error)
+	assert.NotContains(t, string(res.Body), addr)
+}
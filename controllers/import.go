@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseImportOptions reads ?dry_run=, ?atomic= (default true) and ?key=id|name (default id)
+func parseImportOptions(ctx *fiber.Ctx) (models.ImportOptions, []utils.ErrorDetail) {
+	opts := models.ImportOptions{
+		DryRun: ctx.QueryBool("dry_run", false),
+		Atomic: ctx.QueryBool("atomic", true),
+		Key:    ctx.Query("key", "id"),
+	}
+	if opts.Key != "id" && opts.Key != "name" {
+		return opts, []utils.ErrorDetail{{Field: "key", Message: "must be id or name"}}
+	}
+	return opts, nil
+}
+
+// runImport decodes and validates the uploaded rows, hands the valid ones to write
+// and renders a per-row report. Atomic imports with any bad row write nothing.
+func runImport[T any](ctx *fiber.Ctx, validate *validator.Validate, write func(context.Context, []T, models.ImportOptions) ([]services.ImportOutcome, error)) error {
+	opts, errs := parseImportOptions(ctx)
+	if errs != nil {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid import options", errs...)
+	}
+
+	rows, rowErrs, err := utils.DecodeRows[T](ctx)
+	if err != nil {
+		return invalidInput(err)
+	}
+	if len(rows) == 0 {
+		return services.Invalid(services.CodeInvalidParameter, "No rows to import")
+	}
+	utils.ValidateRows(rows, validate, rowErrs)
+
+	result := models.ImportResult{Total: len(rows), DryRun: opts.DryRun, Atomic: opts.Atomic}
+	var valid []T
+	var lines []int
+	for i, row := range rows {
+		line := i + 1
+		if details, bad := rowErrs[line]; bad {
+			for _, d := range details {
+				result.Errors = append(result.Errors, models.ImportError{Row: line, Field: d.Field, Message: d.Message})
+			}
+			result.Failed++
+			continue
+		}
+		valid = append(valid, row)
+		lines = append(lines, line)
+	}
+
+	if opts.Atomic && result.Failed > 0 {
+		return importRejected(result)
+	}
+
+	if len(valid) > 0 {
+		outcomes, err := write(ctx.UserContext(), valid, opts)
+		if err != nil {
+			return err
+		}
+		for i, o := range outcomes {
+			switch o.Action {
+			case services.ImportCreated:
+				result.Created++
+			case services.ImportUpdated:
+				result.Updated++
+			}
+			if o.Err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, rowFailure(lines[i], o.Err))
+			}
+		}
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+	if opts.Atomic && result.Failed > 0 {
+		return importRejected(result)
+	}
+
+	result.Committed = !opts.DryRun
+	if opts.DryRun {
+		return utils.SuccessResponse(ctx, 200, "Dry run completed, nothing was written", result)
+	}
+	return utils.SuccessResponse(ctx, 200, "Import completed", result)
+}
+
+func importRejected(result models.ImportResult) error {
+	details := make([]utils.ErrorDetail, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		field := fmt.Sprintf("rows[%d]", e.Row)
+		if e.Field != "" {
+			field += "." + e.Field
+		}
+		details = append(details, utils.ErrorDetail{Field: field, Message: e.Message})
+	}
+	return services.Unprocessable(services.CodeImportRejected, "Import rejected, nothing was written", details...)
+}
+
+// rowFailure reports why a row was not written without passing database messages on
+func rowFailure(line int, err error) models.ImportError {
+	svcErr := services.AsError(err)
+	if svcErr == nil {
+		slog.Error("Import row could not be written", "row", line, "error", err)
+		return models.ImportError{Row: line, Message: "row could not be written"}
+	}
+	if len(svcErr.Details) > 0 {
+		return models.ImportError{Row: line, Field: svcErr.Details[0].Field, Message: svcErr.Details[0].Message}
+	}
+	return models.ImportError{Row: line, Message: svcErr.Message}
+}
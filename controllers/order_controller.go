@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/services"
 	"github.com/azizmasr1001/go-api-northwind/utils"
@@ -20,35 +22,31 @@ func NewOrderController(service services.OrderService) *OrderController {
 	}
 }
 
-type OrderInput struct {
-	models.Order
-	OrderDetails []models.OrderDetail `json:"order_details" validate:"required,min=1,dive"`
-}
-
 // CreateOrder godoc
 // @Summary Create new order
 // @Description Create order and order details in one transaction
 // @Tags Orders
 // @Accept json
 // @Produce json
-// @Param order body OrderInput true "Order with details"
+// @Param order body models.OrderInput true "Order with details"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
 // @Success 201 {object} models.Order
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,409,422,500 {object} utils.Problem
 // @Router /orders [post]
 // @Security BearerAuth
 func (c *OrderController) Create(ctx *fiber.Ctx) error {
-	var input OrderInput
+	var input models.OrderInput
 	if err := ctx.BodyParser(&input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 
 	if err := c.validate.Struct(input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", utils.FormatValidationErrors(err.(validator.ValidationErrors)))
+		return validationFailed(utils.FormatValidationErrors(err.(validator.ValidationErrors)))
 	}
 
-	created, err := c.service.Create(&input.Order, input.OrderDetails)
+	created, err := c.service.Create(ctx.UserContext(), &input.Order, input.OrderDetails)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Failed to create order", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 201, "Order created successfully", created)
@@ -57,20 +55,40 @@ func (c *OrderController) Create(ctx *fiber.Ctx) error {
 // GetAllOrders godoc
 // @Summary Get list of orders
 // @Tags Orders
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param page query int false "Page number"
 // @Param limit query int false "Page size"
+// @Param fields query string false "Comma separated JSON fields to return"
+// @Param format query string false "json (default), or csv or xlsx to download the full list as a file"
 // @Success 200 {object} utils.StandardListResponse
-// @Failure 500 {object} utils.StandardErrorResponse
+// @Failure 400,500 {object} utils.Problem
 // @Router /orders [get]
 // @Security BearerAuth
 func (c *OrderController) GetAll(ctx *fiber.Ctx) error {
 	page := ctx.QueryInt("page", 1)
 	limit := ctx.QueryInt("limit", 10)
+	fields := utils.GetFields(ctx)
+
+	format, errs := utils.ExportFormat(ctx)
+	if errs != nil {
+		return unsupportedFormat(errs...)
+	}
+	if format != "" {
+		return utils.StreamExport(ctx, format, "orders", models.Order{}, fields, func(reqCtx context.Context, emit utils.RowEmitter) error {
+			return c.service.StreamAll(reqCtx, fields.SelectColumns(), func(order models.Order) error {
+				return emit(order)
+			})
+		})
+	}
 
-	data, total, err := c.service.GetAll(page, limit)
+	orders, total, err := c.service.GetAll(ctx.UserContext(), page, limit, fields.SelectColumns()...)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Failed to fetch orders", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
+	}
+
+	data, err := fields.Pick(orders)
+	if err != nil {
+		return err
 	}
 
 	meta := utils.Meta{Page: page, Limit: limit, Total: int(total)}
@@ -82,44 +100,74 @@ func (c *OrderController) GetAll(ctx *fiber.Ctx) error {
 // @Tags Orders
 // @Produce json
 // @Param id path int true "Order ID"
+// @Param fields query string false "Comma separated JSON fields to return, use details.<field> for order lines"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} fiber.Map
-// @Failure 400,404 {object} utils.StandardErrorResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Entity tag over the order and its lines, weak when fields is used"
+// @Failure 400,404 {object} utils.Problem
 // @Router /orders/{id} [get]
 // @Security BearerAuth
 func (c *OrderController) GetByID(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
+	fields := utils.GetFields(ctx)
+	detailFields := fields.Include("details")
 
-	order, details, err := c.service.GetByID(id)
+	order, details, err := c.service.GetByID(ctx.UserContext(), id, fields.SelectColumns(), detailFields.SelectColumns())
 	if err != nil {
-		return utils.ErrorResponse(ctx, 404, "Order not found", []utils.ErrorDetail{{Message: err.Error()}})
+		return notFound(err, "Order not found")
+	}
+
+	orderData, err := fields.Pick(order)
+	if err != nil {
+		return err
+	}
+	detailData, err := detailFields.Pick(details)
+	if err != nil {
+		return err
+	}
+
+	etag := utils.ETag(orderVersion(order, details))
+	if fields != nil {
+		etag = utils.WeakETag(fiber.Map{"order": orderData, "details": detailData})
+	}
+	if utils.NotModified(ctx, etag) {
+		return ctx.SendStatus(fiber.StatusNotModified)
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Order found", fiber.Map{
-		"order":   order,
-		"details": details,
+		"order":   orderData,
+		"details": detailData,
 	})
 }
 
 // DeleteOrder godoc
 // @Summary Delete order by ID
+// @Description Move the order to the trash, it can be restored until an admin purges it
 // @Tags Orders
 // @Produce json
 // @Param id path int true "Order ID"
+// @Param If-Match header string false "ETag the delete is based on"
 // @Success 200 {object} fiber.Map
-// @Failure 400,404,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,412,500 {object} utils.Problem
 // @Router /orders/{id} [delete]
 // @Security BearerAuth
 func (c *OrderController) Delete(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
-
-	if err = c.service.Delete(id); err != nil {
-		return utils.ErrorResponse(ctx, 500, "Delete failed", []utils.ErrorDetail{{Message: err.Error()}})
+	err = c.ifMatch(ctx, id, func(svc services.OrderService) error {
+		if err := svc.Delete(ctx.UserContext(), id, utils.GetUserID(ctx)); err != nil {
+			return notFound(err, "Order not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Order deleted", nil)
@@ -131,29 +179,93 @@ func (c *OrderController) Delete(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Order ID"
-// @Param order body OrderInput true "Updated order and details"
+// @Param order body models.OrderInput true "Updated order and details"
+// @Param If-Match header string false "ETag the update is based on"
 // @Success 200 {object} models.Order
-// @Failure 400,404,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,412,500 {object} utils.Problem
 // @Router /orders/{id} [put]
 // @Security BearerAuth
 func (c *OrderController) Update(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
 
-	var input OrderInput
+	var input models.OrderInput
 	if err := ctx.BodyParser(&input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 	if err := c.validate.Struct(input); err != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", utils.FormatValidationErrors(err.(validator.ValidationErrors)))
+		return validationFailed(utils.FormatValidationErrors(err.(validator.ValidationErrors)))
 	}
-
-	updated, err := c.service.Update(id, &input.Order, input.OrderDetails)
+	var updated *models.Order
+	err = c.ifMatch(ctx, id, func(svc services.OrderService) error {
+		updated, err = svc.Update(ctx.UserContext(), id, &input.Order, input.OrderDetails)
+		return err
+	})
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Update failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	return utils.SuccessResponse(ctx, 200, "Order updated", updated)
 }
+
+// ifMatch runs write directly when the client sent no If-Match. Otherwise it locks
+// the order, compares the header with the ETag GetByID would return and writes
+// in the same transaction, answering 404 or 412 without writing.
+func (c *OrderController) ifMatch(ctx *fiber.Ctx, id int, write func(services.OrderService) error) error {
+	if ctx.Get(fiber.HeaderIfMatch) == "" {
+		return write(c.service)
+	}
+	return c.service.Locked(ctx.UserContext(), id, func(svc services.OrderService) error {
+		order, details, err := svc.GetByID(ctx.UserContext(), id, nil, nil)
+		if err != nil {
+			return notFound(err, "Order not found")
+		}
+		if err := utils.CheckIfMatch(ctx, orderVersion(order, details)); err != nil {
+			return preconditionFailed(err)
+		}
+		return write(svc)
+	})
+}
+
+// OrderTrash godoc
+// @Summary List deleted orders
+// @Tags Orders
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /orders/trash [get]
+// @Security BearerAuth
+func (c *OrderController) Trash(ctx *fiber.Ctx) error {
+	return listTrash(ctx, "orders", c.service.Trash)
+}
+
+// RestoreOrder godoc
+// @Summary Restore a deleted order
+// @Tags Orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,404,500 {object} utils.Problem
+// @Router /orders/{id}/restore [post]
+// @Security BearerAuth
+func (c *OrderController) Restore(ctx *fiber.Ctx) error {
+	return restoreFromTrash(ctx, "Order", c.service.Restore)
+}
+
+// PurgeOrder godoc
+// @Summary Permanently delete a order
+// @Description Admin only. The order must be in the trash already, its details are removed with it
+// @Tags Orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,401,403,404,500 {object} utils.Problem
+// @Router /orders/{id}/purge [delete]
+// @Security BearerAuth
+func (c *OrderController) Purge(ctx *fiber.Ctx) error {
+	return purgeFromTrash(ctx, "Order", c.service.Purge)
+}
@@ -0,0 +1,79 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrder_GetByIDWithDetails(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	products := []*models.Product{env.Product(t), env.Product(t)}
+	order, _ := env.Order(t, products)
+	path := fmt.Sprintf("/api/orders/%d", order.OrderID)
+
+	res := client.Get(path)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	var got struct {
+		Order   models.Order         `json:"order"`
+		Details []models.OrderDetail `json:"details"`
+	}
+	res.Data(&got)
+	assert.Equal(t, order.OrderID, got.Order.OrderID)
+	assert.Equal(t, "Test Shipment", got.Order.ShipName)
+	require.Len(t, got.Details, 2)
+
+	etag := res.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+	client.Header.Set("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, client.Get(path).StatusCode)
+}
+
+func TestOrder_ListIsPaginated(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	for range 3 {
+		env.Order(t, nil)
+	}
+
+	res := client.Get("/api/orders?page=1&limit=2")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	var list struct {
+		Data []models.Order `json:"data"`
+		Meta struct {
+			Total int `json:"total"`
+		} `json:"meta"`
+	}
+	res.Decode(&list)
+	assert.Len(t, list.Data, 2)
+	assert.Equal(t, 3, list.Meta.Total)
+
+	assert.Equal(t, http.StatusBadRequest, client.Get("/api/orders?page=0").StatusCode)
+}
+
+func TestOrder_ConcurrentUpdatesWithOneETag(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	order, details := env.Order(t, nil)
+	path := fmt.Sprintf("/api/orders/%d", order.OrderID)
+	slowWrites(t, env)
+
+	res := client.Get(path)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+
+	statuses := race(client, 4, http.Header{"If-Match": {res.Header.Get("ETag")}}, func(c *apptest.Client, i int) *apptest.Response {
+		return c.Put(path, map[string]interface{}{
+			"employee_id":   order.EmployeeID,
+			"ship_name":     fmt.Sprintf("Shipment %d", i),
+			"order_details": details,
+		})
+	})
+	assert.Equal(t, 1, count(statuses, http.StatusOK), statuses)
+	assert.Equal(t, len(statuses)-1, count(statuses, http.StatusPreconditionFailed), statuses)
+}
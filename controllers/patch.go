@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// runPatch applies the request body to the stored row as a JSON Merge Patch (RFC 7396)
+// or JSON Patch (RFC 6902), validates the merged row and writes only the changed columns.
+// pin restores the primary key from the path so a patch can never move to another row.
+// If-Match, when sent, must match the ETag of the stored row. locked holds the row
+// from the load to the write, so two patches based on one ETag cannot both succeed.
+func runPatch[T, S any](ctx *fiber.Ctx, validate *validator.Validate, name string, locked func(func(S) error) error,
+	load func(S) (*T, error), pin func(*T), write func(S, *T, []string) (interface{}, error)) error {
+	var updated interface{}
+	err := locked(func(svc S) error {
+		var err error
+		updated, err = patchRow(ctx, validate, name, svc, load, pin, write)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return utils.SuccessResponse(ctx, 200, name+" updated", updated)
+}
+
+func patchRow[T, S any](ctx *fiber.Ctx, validate *validator.Validate, name string, svc S,
+	load func(S) (*T, error), pin func(*T), write func(S, *T, []string) (interface{}, error)) (interface{}, error) {
+	current, err := load(svc)
+	if err != nil {
+		return nil, notFound(err, name+" not found")
+	}
+	if err := utils.CheckIfMatch(ctx, current); err != nil {
+		return nil, preconditionFailed(err)
+	}
+
+	var patched T
+	if err := utils.ApplyPatch(ctx.Get(fiber.HeaderContentType), ctx.Body(), current, &patched); err != nil {
+		if errors.Is(err, utils.ErrUnsupportedPatch) {
+			return nil, services.NewError(services.KindUnsupported, services.CodeUnsupportedPatch, "Unsupported patch format",
+				utils.ErrorDetail{Message: "use " + utils.MIMEMergePatch + " or " + utils.MIMEJSONPatch})
+		}
+		return nil, services.Invalid(services.CodeInvalidBody, "Invalid patch", utils.ErrorDetail{Message: err.Error()})
+	}
+	pin(&patched)
+
+	if err := validate.Struct(patched); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			return nil, validationFailed(utils.FormatValidationErrors(errs))
+		}
+		return nil, invalidInput(err)
+	}
+
+	return write(svc, &patched, utils.ChangedColumns(current, &patched))
+}
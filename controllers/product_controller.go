@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/services"
 	"github.com/azizmasr1001/go-api-northwind/utils"
@@ -25,21 +27,41 @@ func NewProductController(service services.ProductService) *ProductController {
 // @Description Retrieve a paginated list of products
 // @Tags Products
 // @Accept json
-// @Produce json
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param page query int false "Page number"
 // @Param limit query int false "Page size"
+// @Param fields query string false "Comma separated JSON fields to return"
+// @Param format query string false "json (default), or csv or xlsx to download the full list as a file"
 // @Success 200 {object} utils.StandardListResponse
-// @Failure 500 {object} utils.StandardErrorResponse
+// @Failure 400,500 {object} utils.Problem
 // @Router /products [get]
 // @Security BearerAuth
 func (c *ProductController) GetAll(ctx *fiber.Ctx) error {
 	//page, limit := utils.GetPagination(ctx)
 	page := ctx.Locals("page").(int)
 	limit := ctx.Locals("limit").(int)
+	fields := utils.GetFields(ctx)
+
+	format, errs := utils.ExportFormat(ctx)
+	if errs != nil {
+		return unsupportedFormat(errs...)
+	}
+	if format != "" {
+		return utils.StreamExport(ctx, format, "products", models.Product{}, fields, func(reqCtx context.Context, emit utils.RowEmitter) error {
+			return c.service.StreamAll(reqCtx, fields.SelectColumns(), func(prod models.Product) error {
+				return emit(prod)
+			})
+		})
+	}
+
+	products, total, err := c.service.GetAllPaginated(ctx.UserContext(), page, limit, fields.SelectColumns()...)
+	if err != nil {
+		return err
+	}
 
-	products, total, err := c.service.GetAllPaginated(page, limit)
+	data, err := fields.Pick(products)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Failed to retrieve products", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 
 	meta := utils.Meta{
@@ -48,7 +70,7 @@ func (c *ProductController) GetAll(ctx *fiber.Ctx) error {
 		Total: int(total),
 	}
 
-	return utils.ListResponse(ctx, 200, "Products retrieved", products, meta)
+	return utils.ListResponse(ctx, 200, "Products retrieved", data, meta)
 }
 
 // SearchProducts godoc
@@ -58,23 +80,30 @@ func (c *ProductController) GetAll(ctx *fiber.Ctx) error {
 // @Param name query string true "Search keyword"
 // @Param page query int false "Page"
 // @Param limit query int false "Limit"
+// @Param fields query string false "Comma separated JSON fields to return"
 // @Success 200 {object} utils.StandardListResponse
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,500 {object} utils.Problem
 // @Router /products/search [get]
 // @Security BearerAuth
 func (c *ProductController) Search(ctx *fiber.Ctx) error {
 	name := ctx.Query("name", "")
 	if name == "" {
-		return utils.ErrorResponse(ctx, 400, "Missing query parameter: name", nil)
+		return services.Invalid(services.CodeInvalidParameter, "Missing query parameter: name")
 	}
 
 	//page := ctx.QueryInt("page", 1)
 	//limit := ctx.QueryInt("limit", 10)
 	page, limit := utils.GetPagination(ctx)
+	fields := utils.GetFields(ctx)
 
-	products, total, err := c.service.SearchByName(name, page, limit)
+	products, total, err := c.service.SearchByName(ctx.UserContext(), name, page, limit, fields.SelectColumns()...)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Search failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
+	}
+
+	data, err := fields.Pick(products)
+	if err != nil {
+		return err
 	}
 
 	meta := utils.Meta{
@@ -83,7 +112,7 @@ func (c *ProductController) Search(ctx *fiber.Ctx) error {
 		Total: int(total),
 	}
 
-	return utils.ListResponse(ctx, 200, "Search results", products, meta)
+	return utils.ListResponse(ctx, 200, "Search results", data, meta)
 }
 
 // GetProductByID godoc
@@ -92,19 +121,37 @@ func (c *ProductController) Search(ctx *fiber.Ctx) error {
 // @Tags Products
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param fields query string false "Comma separated JSON fields to return"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} models.Product
-// @Failure 400,404 {object} utils.StandardErrorResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Entity tag, weak when fields is used"
+// @Failure 400,404 {object} utils.Problem
 // @Router /products/{id} [get]
 // @Security BearerAuth
 func (c *ProductController) GetByID(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
+	fields := utils.GetFields(ctx)
 
-	data, err := c.service.GetByID(id)
+	prod, err := c.service.GetByID(ctx.UserContext(), id, fields.SelectColumns()...)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 404, "Product not found", []utils.ErrorDetail{{Message: err.Error()}})
+		return notFound(err, "Product not found")
+	}
+
+	data, err := fields.Pick(prod)
+	if err != nil {
+		return err
+	}
+
+	etag := utils.ETag(prod)
+	if fields != nil {
+		etag = utils.WeakETag(data)
+	}
+	if utils.NotModified(ctx, etag) {
+		return ctx.SendStatus(fiber.StatusNotModified)
 	}
 	return utils.SuccessResponse(ctx, 200, "Product retrieved", data)
 }
@@ -116,81 +163,200 @@ func (c *ProductController) GetByID(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param product body models.Product true "Product object"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
 // @Success 201 {object} models.Product
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,409,422,500 {object} utils.Problem
 // @Router /products [post]
 // @Security BearerAuth
 func (c *ProductController) Create(ctx *fiber.Ctx) error {
 	prod, validationErrs, err := utils.BindAndValidate[models.Product](ctx, c.validate)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 
 	if validationErrs != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", validationErrs)
+		return validationFailed(validationErrs)
 	}
 
-	created, err := c.service.Create(prod)
+	created, err := c.service.Create(ctx.UserContext(), prod)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Create failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 	return utils.SuccessResponse(ctx, 201, "Product created", created)
 }
 
 // UpdateProduct godoc
-// @Summary Update a product
-// @Description Update product data by ID
+// @Summary Replace a product
+// @Description Replace product data by ID, the ID is always taken from the path
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
 // @Param product body models.Product true "Updated product object"
+// @Param If-Match header string false "ETag the update is based on"
 // @Success 200 {object} models.Product
-// @Failure 400,404,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,412,500 {object} utils.Problem
 // @Router /products/{id} [put]
 // @Security BearerAuth
 func (c *ProductController) Update(ctx *fiber.Ctx) error {
 	id, err := utils.ParseID(ctx)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid ID", []utils.ErrorDetail{{Message: err.Error()}})
-	}
-
-	_, err = c.service.GetByID(id)
-	if err != nil {
-		return utils.ErrorResponse(ctx, 404, "Product not found", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidID()
 	}
 
 	prod, validationErrs, err := utils.BindAndValidate[models.Product](ctx, c.validate)
 	if err != nil {
-		return utils.ErrorResponse(ctx, 400, "Invalid input", []utils.ErrorDetail{{Message: err.Error()}})
+		return invalidInput(err)
 	}
 
 	if validationErrs != nil {
-		return utils.ErrorResponse(ctx, 400, "Validation failed", validationErrs)
+		return validationFailed(validationErrs)
 	}
 
-	updated, err := c.service.Update(prod)
+	prod.ProductID = id
+	var updated *models.Product
+	err = c.service.Locked(ctx.UserContext(), id, func(svc services.ProductService) error {
+		current, err := svc.GetByID(ctx.UserContext(), id)
+		if err != nil {
+			return notFound(err, "Product not found")
+		}
+		if err := utils.CheckIfMatch(ctx, current); err != nil {
+			return preconditionFailed(err)
+		}
+		updated, err = svc.Update(ctx.UserContext(), prod)
+		return err
+	})
 	if err != nil {
-		return utils.ErrorResponse(ctx, 500, "Update failed", []utils.ErrorDetail{{Message: err.Error()}})
+		return err
 	}
 	return utils.SuccessResponse(ctx, 200, "Product updated", updated)
 }
 
+// PatchProduct godoc
+// @Summary Partially update a product
+// @Description Apply a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902), only the changed fields are written
+// @Tags Products
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param patch body object true "Merge patch object or JSON Patch operations"
+// @Param If-Match header string false "ETag the update is based on"
+// @Success 200 {object} models.Product
+// @Failure 400,404,412,415,500 {object} utils.Problem
+// @Router /products/{id} [patch]
+// @Security BearerAuth
+func (c *ProductController) Patch(ctx *fiber.Ctx) error {
+	id := ctx.Locals("id").(int)
+
+	return runPatch(ctx, c.validate, "Product",
+		func(fn func(services.ProductService) error) error { return c.service.Locked(ctx.UserContext(), id, fn) },
+		func(svc services.ProductService) (*models.Product, error) { return svc.GetByID(ctx.UserContext(), id) },
+		func(prod *models.Product) { prod.ProductID = id },
+		func(svc services.ProductService, prod *models.Product, columns []string) (interface{}, error) {
+			return svc.Patch(ctx.UserContext(), prod, columns)
+		},
+	)
+}
+
 // DeleteProduct godoc
 // @Summary Delete a product
-// @Description Remove product by ID
+// @Description Move the product to the trash, it can be restored until an admin purges it
 // @Tags Products
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag the delete is based on"
 // @Success 200 {object} fiber.Map
-// @Failure 400,500 {object} utils.StandardErrorResponse
+// @Failure 400,404,412,500 {object} utils.Problem
 // @Router /products/{id} [delete]
 // @Security BearerAuth
 func (c *ProductController) Delete(ctx *fiber.Ctx) error {
 	id := ctx.Locals("id").(int)
 
-	if err := c.service.Delete(id); err != nil {
-		return utils.ErrorResponse(ctx, 500, "Delete failed", []utils.ErrorDetail{{Message: err.Error()}})
+	del := func(svc services.ProductService) error {
+		if err := svc.Delete(ctx.UserContext(), id, utils.GetUserID(ctx)); err != nil {
+			return notFound(err, "Product not found")
+		}
+		return nil
+	}
+	if ctx.Get(fiber.HeaderIfMatch) == "" {
+		if err := del(c.service); err != nil {
+			return err
+		}
+		return utils.SuccessResponse(ctx, 200, "Product deleted", nil)
+	}
+
+	err := c.service.Locked(ctx.UserContext(), id, func(svc services.ProductService) error {
+		current, err := svc.GetByID(ctx.UserContext(), id)
+		if err != nil {
+			return notFound(err, "Product not found")
+		}
+		if err := utils.CheckIfMatch(ctx, current); err != nil {
+			return preconditionFailed(err)
+		}
+		return del(svc)
+	})
+	if err != nil {
+		return err
 	}
 	return utils.SuccessResponse(ctx, 200, "Product deleted", nil)
 }
+
+// ImportProducts godoc
+// @Summary Bulk import products
+// @Description Upsert products from a JSON array or a CSV file (header row uses the JSON field names)
+// @Tags Products
+// @Accept json,text/csv
+// @Produce json
+// @Param rows body []models.Product true "Rows to import"
+// @Param dry_run query bool false "Validate and report without writing"
+// @Param atomic query bool false "All rows in one transaction (default true), false imports only the valid rows"
+// @Param key query string false "Upsert key: id (default) or name"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
+// @Success 200 {object} models.ImportResult
+// @Failure 400,409,422,500 {object} utils.Problem
+// @Router /products/import [post]
+// @Security BearerAuth
+func (c *ProductController) Import(ctx *fiber.Ctx) error {
+	return runImport[models.Product](ctx, c.validate, c.service.Import)
+}
+
+// ProductTrash godoc
+// @Summary List deleted products
+// @Tags Products
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /products/trash [get]
+// @Security BearerAuth
+func (c *ProductController) Trash(ctx *fiber.Ctx) error {
+	return listTrash(ctx, "products", c.service.Trash)
+}
+
+// RestoreProduct godoc
+// @Summary Restore a deleted product
+// @Tags Products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,404,500 {object} utils.Problem
+// @Router /products/{id}/restore [post]
+// @Security BearerAuth
+func (c *ProductController) Restore(ctx *fiber.Ctx) error {
+	return restoreFromTrash(ctx, "Product", c.service.Restore)
+}
+
+// PurgeProduct godoc
+// @Summary Permanently delete a product
+// @Description Admin only. The product must be in the trash already
+// @Tags Products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,401,403,404,500 {object} utils.Problem
+// @Router /products/{id}/purge [delete]
+// @Security BearerAuth
+func (c *ProductController) Purge(ctx *fiber.Ctx) error {
+	return purgeFromTrash(ctx, "Product", c.service.Purge)
+}
@@ -0,0 +1,142 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// slowWrites delays every UPDATE, so requests that read the same row all do so
+// before the first of them writes unless something serializes them
+func slowWrites(t *testing.T, env *apptest.Env) {
+	err := env.DB.Callback().Update().Before("gorm:update").Register("test:slow_writes", func(*gorm.DB) {
+		time.Sleep(20 * time.Millisecond)
+	})
+	require.NoError(t, err)
+}
+
+// race sends n requests at once, each from its own copy of client, and returns the statuses
+func race(client *apptest.Client, n int, header http.Header, send func(c *apptest.Client, i int) *apptest.Response) []int {
+	statuses := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		c := *client
+		c.Header = header.Clone()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = send(&c, i).StatusCode
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+func TestProduct_ConcurrentPatchesWithOneETag(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	product := env.Product(t)
+	path := fmt.Sprintf("/api/products/%d", product.ProductID)
+	slowWrites(t, env)
+
+	for round := range 3 {
+		res := client.Get(path)
+		require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+		etag := res.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+
+		header := http.Header{"If-Match": {etag}, "Content-Type": {"application/merge-patch+json"}}
+		statuses := race(client, 6, header, func(c *apptest.Client, i int) *apptest.Response {
+			return c.Patch(path, map[string]int{"units_in_stock": 100*round + i})
+		})
+		assert.Equal(t, 1, count(statuses, http.StatusOK), "round %d: %v", round, statuses)
+		assert.Equal(t, len(statuses)-1, count(statuses, http.StatusPreconditionFailed), "round %d: %v", round, statuses)
+	}
+
+	var stored models.Product
+	require.NoError(t, env.DB.First(&stored, product.ProductID).Error)
+	assert.GreaterOrEqual(t, stored.UnitsInStock, 200)
+}
+
+func TestProduct_ImportReportsMistypedElements(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	category := env.Category(t)
+
+	body := fmt.Sprintf(`[{"product_name":"Chai","category_id":%d},{"product_name":"Chang","category_id":"x"},{"unit_price":1}]`, category.CategoryID)
+	res := client.Post("/api/products/import?atomic=false", body)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	var result models.ImportResult
+	res.Data(&result)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 2, result.Failed)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, models.ImportError{Row: 2, Field: "category_id", Message: "expected int, got string"}, result.Errors[0])
+	assert.Equal(t, 3, result.Errors[1].Row)
+
+	res = client.Post("/api/products/import", body)
+	require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode, string(res.Body))
+	assert.Contains(t, string(res.Body), "rows[2].category_id")
+}
+
+func TestProduct_ImportPublishesStockChanges(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsAdmin(t)
+	res := client.Post("/api/webhooks", map[string]interface{}{
+		"url":    "https://93.184.215.14/hooks",
+		"events": []string{models.EventProductStockChanged, models.EventProductOutOfStock},
+	})
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+
+	emptied, untouched := env.Product(t), env.Product(t)
+	row := func(prod *models.Product, stock int) map[string]interface{} {
+		return map[string]interface{}{
+			"product_id":     prod.ProductID,
+			"product_name":   prod.ProductName,
+			"category_id":    *prod.CategoryID,
+			"unit_price":     prod.UnitPrice,
+			"units_in_stock": stock,
+		}
+	}
+	events := func() []string {
+		var deliveries []models.WebhookDelivery
+		require.NoError(t, env.DB.Order(`"ID"`).Find(&deliveries).Error)
+		types := make([]string, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			types = append(types, delivery.EventType)
+		}
+		return types
+	}
+	body := []map[string]interface{}{row(emptied, 0), row(untouched, untouched.UnitsInStock)}
+
+	res = client.Post("/api/products/import?dry_run=true", body)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Empty(t, events())
+
+	res = client.Post("/api/products/import", body)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Equal(t, []string{models.EventProductStockChanged, models.EventProductOutOfStock}, events())
+
+	res = client.Post("/api/products/import?key=name", []map[string]interface{}{row(untouched, 7)})
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Equal(t, []string{models.EventProductStockChanged, models.EventProductOutOfStock, models.EventProductStockChanged}, events())
+}
+
+func count(statuses []int, status int) int {
+	n := 0
+	for _, s := range statuses {
+		if s == status {
+			n++
+		}
+	}
+	return n
+}
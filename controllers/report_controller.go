@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const reportDateLayout = "2006-01-02"
+
+type ReportController struct {
+	service services.ReportService
+}
+
+func NewReportController(service services.ReportService) *ReportController {
+	return &ReportController{service: service}
+}
+
+// parseRange reads ?from= and ?to= (inclusive, YYYY-MM-DD) or ?year=
+func parseRange(ctx *fiber.Ctx) (models.DateRange, []utils.ErrorDetail) {
+	var r models.DateRange
+	var errs []utils.ErrorDetail
+
+	if year := ctx.QueryInt("year", 0); year > 0 {
+		r.From = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		r.To = r.From.AddDate(1, 0, 0)
+		return r, nil
+	} else if ctx.Query("year") != "" {
+		return r, []utils.ErrorDetail{{Field: "year", Message: "must be a valid positive number"}}
+	}
+
+	if from := ctx.Query("from"); from != "" {
+		t, err := time.Parse(reportDateLayout, from)
+		if err != nil {
+			errs = append(errs, utils.ErrorDetail{Field: "from", Message: "must be a date in YYYY-MM-DD format"})
+		}
+		r.From = t
+	}
+	if to := ctx.Query("to"); to != "" {
+		t, err := time.Parse(reportDateLayout, to)
+		if err != nil {
+			errs = append(errs, utils.ErrorDetail{Field: "to", Message: "must be a date in YYYY-MM-DD format"})
+		} else {
+			r.To = t.AddDate(0, 0, 1)
+		}
+	}
+	if errs == nil && !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		errs = append(errs, utils.ErrorDetail{Field: "from", Message: "must not be after to"})
+	}
+	return r, errs
+}
+
+// report loads the rows and renders them as CSV or as the standard list response.
+// ?format= other than json or csv is refused before anything is queried.
+func report[T any](ctx *fiber.Ctx, name, message string, load func() ([]T, error)) error {
+	switch strings.ToLower(ctx.Query("format")) {
+	case "", "json", "csv":
+	default:
+		return unsupportedFormat(utils.ErrorDetail{Field: "format", Message: "must be json or csv"})
+	}
+
+	rows, err := load()
+	if err != nil {
+		return err
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	if utils.WantsCSV(ctx) {
+		return utils.CSVResponse(ctx, fmt.Sprintf("%s.csv", name), rows)
+	}
+	return utils.ListResponse(ctx, 200, message, rows, utils.Meta{Total: len(rows)})
+}
+
+// SalesByCategory godoc
+// @Summary Sales by category and year
+// @Description Shipped sales per category, grouped by year
+// @Tags Reports
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param format query string false "json or csv"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /reports/sales-by-category [get]
+// @Security BearerAuth
+func (c *ReportController) SalesByCategory(ctx *fiber.Ctx) error {
+	r, errs := parseRange(ctx)
+	if errs != nil {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid date range", errs...)
+	}
+
+	return report(ctx, "sales-by-category", "Sales by category retrieved", func() ([]models.CategorySales, error) {
+		return c.service.SalesByCategory(ctx.UserContext(), r)
+	})
+}
+
+// ProductSales godoc
+// @Summary Product sales for a year
+// @Description Shipped sales per product for the given year or date range
+// @Tags Reports
+// @Produce json,text/csv
+// @Param year query int false "Calendar year, overrides from/to"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param format query string false "json or csv"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /reports/product-sales [get]
+// @Security BearerAuth
+func (c *ReportController) ProductSales(ctx *fiber.Ctx) error {
+	r, errs := parseRange(ctx)
+	if errs != nil {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid date range", errs...)
+	}
+
+	return report(ctx, "product-sales", "Product sales retrieved", func() ([]models.ProductSales, error) {
+		return c.service.ProductSales(ctx.UserContext(), r)
+	})
+}
+
+// SalesByEmployee godoc
+// @Summary Sales by employee
+// @Description Shipped orders and sales per employee over a period
+// @Tags Reports
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param format query string false "json or csv"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /reports/employee-sales [get]
+// @Security BearerAuth
+func (c *ReportController) SalesByEmployee(ctx *fiber.Ctx) error {
+	r, errs := parseRange(ctx)
+	if errs != nil {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid date range", errs...)
+	}
+
+	return report(ctx, "employee-sales", "Sales by employee retrieved", func() ([]models.EmployeeSales, error) {
+		return c.service.SalesByEmployee(ctx.UserContext(), r)
+	})
+}
+
+// QuarterlyOrders godoc
+// @Summary Quarterly orders
+// @Description Order count and sales per quarter, by order date
+// @Tags Reports
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param format query string false "json or csv"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /reports/quarterly-orders [get]
+// @Security BearerAuth
+func (c *ReportController) QuarterlyOrders(ctx *fiber.Ctx) error {
+	r, errs := parseRange(ctx)
+	if errs != nil {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid date range", errs...)
+	}
+
+	return report(ctx, "quarterly-orders", "Quarterly orders retrieved", func() ([]models.QuarterlyOrders, error) {
+		return c.service.QuarterlyOrders(ctx.UserContext(), r)
+	})
+}
+
+// OrderSubtotals godoc
+// @Summary Order subtotals
+// @Description Subtotal of every order placed in the period
+// @Tags Reports
+// @Produce json,text/csv
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param format query string false "json or csv"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /reports/order-subtotals [get]
+// @Security BearerAuth
+func (c *ReportController) OrderSubtotals(ctx *fiber.Ctx) error {
+	r, errs := parseRange(ctx)
+	if errs != nil {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid date range", errs...)
+	}
+
+	return report(ctx, "order-subtotals", "Order subtotals retrieved", func() ([]models.OrderSubtotal, error) {
+		return c.service.OrderSubtotals(ctx.UserContext(), r)
+	})
+}
+
+// MostExpensiveProducts godoc
+// @Summary Ten most expensive products
+// @Tags Reports
+// @Produce json,text/csv
+// @Param limit query int false "Number of products (default 10)"
+// @Param format query string false "json or csv"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,500 {object} utils.Problem
+// @Router /reports/top-products [get]
+// @Security BearerAuth
+func (c *ReportController) MostExpensiveProducts(ctx *fiber.Ctx) error {
+	limit := ctx.QueryInt("limit", 10)
+	if limit <= 0 {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid limit", utils.ErrorDetail{Field: "limit", Message: "must be a positive number"})
+	}
+
+	return report(ctx, "top-products", "Most expensive products retrieved", func() ([]models.ExpensiveProduct, error) {
+		return c.service.MostExpensiveProducts(ctx.UserContext(), limit)
+	})
+}
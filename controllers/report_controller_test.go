@@ -0,0 +1,64 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_DateRange(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	order, _ := env.Order(t, nil) // placed on 2024-03-01
+
+	subtotals := func(query string) []models.OrderSubtotal {
+		t.Helper()
+		res := client.Get("/api/reports/order-subtotals?" + query)
+		require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+		var rows []models.OrderSubtotal
+		res.Data(&rows)
+		return rows
+	}
+
+	rows := subtotals("from=2024-03-01&to=2024-03-01")
+	require.Len(t, rows, 1, "to is inclusive")
+	assert.Equal(t, order.OrderID, rows[0].OrderID)
+	assert.Equal(t, 10.0, rows[0].Subtotal)
+
+	assert.Empty(t, subtotals("from=2024-03-02"))
+	assert.Empty(t, subtotals("to=2024-02-29"))
+	assert.Len(t, subtotals("year=2024"), 1)
+
+	res := client.Get("/api/reports/order-subtotals?year=2023")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.Contains(t, string(res.Body), `"data":[]`)
+
+	for _, query := range []string{"from=2024-03-02&to=2024-03-01", "from=03/01/2024", "to=tomorrow", "year=abc"} {
+		res := client.Get("/api/reports/order-subtotals?" + query)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+	}
+}
+
+func TestReport_Formats(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+	order, _ := env.Order(t, nil)
+
+	res := client.Get("/api/reports/order-subtotals?format=csv")
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	assert.True(t, strings.HasPrefix(res.Header.Get("Content-Type"), "text/csv"))
+	assert.Contains(t, res.Header.Get("Content-Disposition"), "order-subtotals.csv")
+	assert.Equal(t, fmt.Sprintf("order_id,subtotal\n%d,10\n", order.OrderID), string(res.Body))
+
+	for _, format := range []string{"xlsx", "pdf"} {
+		res := client.Get("/api/reports/order-subtotals?format=" + format)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, format)
+	}
+	assert.Equal(t, http.StatusOK, client.Get("/api/reports/order-subtotals?format=json").StatusCode)
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/stream"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const streamHeartbeat = 15 * time.Second
+
+type StreamController struct {
+	hub *stream.Hub
+}
+
+func NewStreamController(hub *stream.Hub) *StreamController {
+	return &StreamController{hub: hub}
+}
+
+// Stream godoc
+// @Summary Live order and stock events
+// @Description Server-Sent Events stream of order.created, order.updated, order.shipped, product.stock_changed and product.out_of_stock.
+// @Description Browsers that cannot set headers on EventSource may pass the JWT as ?access_token=.
+// @Tags Stream
+// @Produce text/event-stream
+// @Param topics query string false "Comma separated event types or prefixes, e.g. order.*,product.stock_changed"
+// @Param access_token query string false "JWT, when the Authorization header cannot be set"
+// @Success 200 {string} string "event stream"
+// @Failure 400,401 {object} utils.Problem
+// @Router /stream [get]
+// @Security BearerAuth
+func (c *StreamController) Stream(ctx *fiber.Ctx) error {
+	topics, errs := parseTopics(ctx.Query("topics"))
+	if errs != nil {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid topics", errs...)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	sub := c.hub.Subscribe(topics)
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer c.hub.Unsubscribe(sub)
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		fmt.Fprint(w, "retry: 3000\n: connected\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+		for {
+			select {
+			case msg, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, msg.Data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			// a failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// parseTopics accepts known event types and "<prefix>.*" wildcards
+func parseTopics(raw string) ([]string, []utils.ErrorDetail) {
+	var topics []string
+	var errs []utils.ErrorDetail
+	for _, topic := range strings.Split(raw, ",") {
+		topic = strings.TrimSpace(topic)
+		if topic == "" {
+			continue
+		}
+		if !knownTopic(topic) {
+			errs = append(errs, utils.ErrorDetail{Field: "topics", Message: fmt.Sprintf("unknown topic: %s", topic)})
+			continue
+		}
+		topics = append(topics, topic)
+	}
+	return topics, errs
+}
+
+func knownTopic(topic string) bool {
+	prefix, wildcard := strings.CutSuffix(topic, "*")
+	for _, t := range models.EventTypes {
+		if !stream.Streamed(t) {
+			continue
+		}
+		if t == topic || (wildcard && strings.HasPrefix(t, prefix)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream_RejectsUserTopics(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+
+	for _, topics := range []string{"user.*", "user.registered", "order.*,user.registered", "invoice.*"} {
+		res := client.Get("/api/stream?topics=" + topics)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, topics)
+	}
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// listTrash renders one page of soft deleted rows, page and limit come from ValidateQueryPagination
+func listTrash[T any](ctx *fiber.Ctx, name string, list func(context.Context, int, int) ([]T, int64, error)) error {
+	page := ctx.Locals("page").(int)
+	limit := ctx.Locals("limit").(int)
+
+	rows, total, err := list(ctx.UserContext(), page, limit)
+	if err != nil {
+		return err
+	}
+	return utils.ListResponse(ctx, 200, "Deleted "+name+" retrieved", rows, utils.Meta{Page: page, Limit: limit, Total: int(total)})
+}
+
+// restoreFromTrash puts the row at :id back, 404 when it is not in the trash
+func restoreFromTrash(ctx *fiber.Ctx, name string, restore func(context.Context, int) error) error {
+	if err := restore(ctx.UserContext(), ctx.Locals("id").(int)); err != nil {
+		return notFound(err, name+" not found in trash")
+	}
+	return utils.SuccessResponse(ctx, 200, name+" restored", nil)
+}
+
+// purgeFromTrash removes the row at :id for good, 404 when it is not in the trash
+func purgeFromTrash(ctx *fiber.Ctx, name string, purge func(context.Context, int) error) error {
+	if err := purge(ctx.UserContext(), ctx.Locals("id").(int)); err != nil {
+		return notFound(err, name+" not found in trash")
+	}
+	return utils.SuccessResponse(ctx, 200, name+" permanently deleted", nil)
+}
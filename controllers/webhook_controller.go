@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookController struct {
+	service  services.WebhookService
+	validate *validator.Validate
+}
+
+func NewWebhookController(service services.WebhookService) *WebhookController {
+	return &WebhookController{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// ListWebhookEvents godoc
+// @Summary List webhook event types
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} string
+// @Router /webhooks/events [get]
+// @Security BearerAuth
+func (c *WebhookController) Events(ctx *fiber.Ctx) error {
+	return utils.SuccessResponse(ctx, 200, "Webhook events retrieved", models.EventTypes)
+}
+
+// ListWebhooks godoc
+// @Summary List webhook subscriptions
+// @Description Subscriptions created by the current user
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 500 {object} utils.Problem
+// @Router /webhooks [get]
+// @Security BearerAuth
+func (c *WebhookController) List(ctx *fiber.Ctx) error {
+	subs, err := c.service.List(ctx.UserContext(), utils.GetUserID(ctx))
+	if err != nil {
+		return err
+	}
+	return utils.ListResponse(ctx, 200, "Webhooks retrieved", subs, utils.Meta{Total: len(subs)})
+}
+
+// CreateWebhook godoc
+// @Summary Subscribe to webhook events
+// @Description Admins only. The URL must resolve to a public address. Deliveries are signed with X-Webhook-Signature: t=<unix>,v1=<hex HMAC-SHA256 of "<unix>.<body>">. The secret is only returned here.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.WebhookSubscriptionInput true "Target URL and event types"
+// @Param Idempotency-Key header string false "Retry-safe key, a repeat with the same key replays the first response"
+// @Success 201 {object} models.WebhookSubscriptionCreated
+// @Failure 400,403,409,422,500 {object} utils.Problem
+// @Router /webhooks [post]
+// @Security BearerAuth
+func (c *WebhookController) Create(ctx *fiber.Ctx) error {
+	input, validationErrs, err := utils.BindAndValidate[models.WebhookSubscriptionInput](ctx, c.validate)
+	if err != nil {
+		return invalidInput(err)
+	}
+	if validationErrs != nil {
+		return validationFailed(validationErrs)
+	}
+
+	created, err := c.service.Subscribe(ctx.UserContext(), utils.GetUserID(ctx), *input)
+	if err != nil {
+		return err
+	}
+	return utils.SuccessResponse(ctx, 201, "Webhook created, store the secret now", created)
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook subscription
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400,404 {object} utils.Problem
+// @Router /webhooks/{id} [get]
+// @Security BearerAuth
+func (c *WebhookController) GetByID(ctx *fiber.Ctx) error {
+	sub, err := c.service.Get(ctx.UserContext(), utils.GetUserID(ctx), ctx.Locals("id").(int))
+	if err != nil {
+		return notFound(err, "Webhook not found")
+	}
+	return utils.SuccessResponse(ctx, 200, "Webhook retrieved", sub)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook subscription
+// @Description Removes the subscription and its delivery log
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} fiber.Map
+// @Failure 400,404,500 {object} utils.Problem
+// @Router /webhooks/{id} [delete]
+// @Security BearerAuth
+func (c *WebhookController) Delete(ctx *fiber.Ctx) error {
+	if err := c.service.Unsubscribe(ctx.UserContext(), utils.GetUserID(ctx), ctx.Locals("id").(int)); err != nil {
+		return webhookError(err)
+	}
+	return utils.SuccessResponse(ctx, 200, "Webhook deleted", nil)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary Webhook delivery log
+// @Description Delivery attempts for a subscription, newest first
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "pending, delivered or dead"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} utils.StandardListResponse
+// @Failure 400,404,500 {object} utils.Problem
+// @Router /webhooks/{id}/deliveries [get]
+// @Security BearerAuth
+func (c *WebhookController) Deliveries(ctx *fiber.Ctx) error {
+	page, limit := utils.GetPagination(ctx)
+	status := ctx.Query("status")
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return services.Invalid(services.CodeInvalidParameter, "Invalid status", utils.ErrorDetail{Field: "status", Message: "must be pending, delivered or dead"})
+	}
+
+	deliveries, total, err := c.service.Deliveries(ctx.UserContext(), utils.GetUserID(ctx), ctx.Locals("id").(int), status, page, limit)
+	if err != nil {
+		return webhookError(err)
+	}
+	return utils.ListResponse(ctx, 200, "Deliveries retrieved", deliveries, utils.Meta{Page: page, Limit: limit, Total: int(total)})
+}
+
+// RedeliverWebhook godoc
+// @Summary Retry a webhook delivery
+// @Description Puts a delivery, typically a dead-lettered one, back in the queue with a fresh set of retries
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} fiber.Map
+// @Failure 400,404,500 {object} utils.Problem
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+// @Security BearerAuth
+func (c *WebhookController) Redeliver(ctx *fiber.Ctx) error {
+	deliveryID, err := ctx.ParamsInt("deliveryId")
+	if err != nil || deliveryID <= 0 {
+		return services.Invalid(services.CodeInvalidParameter, "Invalid ID parameter", utils.ErrorDetail{Field: "deliveryId", Message: "must be a valid positive number"})
+	}
+
+	if err := c.service.Redeliver(ctx.UserContext(), utils.GetUserID(ctx), ctx.Locals("id").(int), deliveryID); err != nil {
+		return webhookError(err)
+	}
+	return utils.SuccessResponse(ctx, 202, "Delivery queued", nil)
+}
+
+func webhookError(err error) error {
+	return notFound(err, "Webhook not found")
+}
@@ -0,0 +1,75 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_OnlyAdminsSubscribeToPublicTargets(t *testing.T) {
+	env := apptest.New(t)
+	subscription := func(url string) map[string]interface{} {
+		return map[string]interface{}{"url": url, "events": []string{models.EventUserRegistered}}
+	}
+
+	res := env.AsUser(t).Post("/api/webhooks", subscription("https://93.184.215.14/hooks"))
+	assert.Equal(t, http.StatusForbidden, res.StatusCode, string(res.Body))
+
+	admin := env.AsAdmin(t)
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost:8080/hooks", "http://10.0.0.5/hooks"} {
+		res = admin.Post("/api/webhooks", subscription(target))
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, target)
+	}
+
+	res = admin.Post("/api/webhooks", subscription("https://93.184.215.14/hooks"))
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+
+	res = env.Client(t).Post("/api/register", map[string]string{
+		"username": "newcomer",
+		"email":    "newcomer@example.com",
+		"password": "secret123",
+	})
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, env.DB.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1)
+	assert.Contains(t, deliveries[0].Payload, `"username":"newcomer"`)
+	assert.NotContains(t, deliveries[0].Payload, "newcomer@example.com")
+}
+
+func TestWebhook_DeliveriesCommitWithTheWrite(t *testing.T) {
+	env := apptest.New(t)
+	admin := env.AsAdmin(t)
+	res := admin.Post("/api/webhooks", map[string]interface{}{
+		"url":    "https://93.184.215.14/hooks",
+		"events": []string{models.EventOrderCreated},
+	})
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+
+	product := env.Product(t)
+	employee := env.Employee(t)
+	order := map[string]interface{}{
+		"employee_id":   employee.EmployeeID,
+		"ship_name":     "Outbox",
+		"order_details": []map[string]interface{}{{"product_id": product.ProductID, "unit_price": 10, "quantity": 1}},
+	}
+
+	res = admin.Post("/api/orders", order)
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+	var deliveries int64
+	require.NoError(t, env.DB.Model(&models.WebhookDelivery{}).Count(&deliveries).Error)
+	assert.EqualValues(t, 1, deliveries)
+
+	// an order whose delivery cannot be queued is not created either
+	require.NoError(t, env.DB.Migrator().DropTable(&models.WebhookDelivery{}))
+	res = admin.Post("/api/orders", order)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode, string(res.Body))
+	var orders int64
+	require.NoError(t, env.DB.Model(&models.Order{}).Where(`"ShipName" = ?`, "Outbox").Count(&orders).Error)
+	assert.EqualValues(t, 1, orders)
+}
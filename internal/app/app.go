@@ -0,0 +1,192 @@
+// Package app wires the repositories, services and controllers into the Fiber app,
+// the gRPC server and the background workers. main and the test harness share it.
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/config"
+	"github.com/azizmasr1001/go-api-northwind/controllers"
+	"github.com/azizmasr1001/go-api-northwind/internal/gql"
+	"github.com/azizmasr1001/go-api-northwind/internal/grpcserver"
+	"github.com/azizmasr1001/go-api-northwind/internal/health"
+	"github.com/azizmasr1001/go-api-northwind/internal/metrics"
+	"github.com/azizmasr1001/go-api-northwind/internal/netguard"
+	redisClient "github.com/azizmasr1001/go-api-northwind/internal/redis"
+	"github.com/azizmasr1001/go-api-northwind/internal/stream"
+	"github.com/azizmasr1001/go-api-northwind/middlewares"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"github.com/azizmasr1001/go-api-northwind/routes"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/swagger"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+type App struct {
+	Fiber *fiber.App
+	GRPC  *grpc.Server
+	// Metrics serves /metrics on METRICS_PORT, nil when it is served by Fiber
+	Metrics *http.Server
+
+	hub        *stream.Hub
+	dispatcher *services.WebhookDispatcher
+	workers    sync.WaitGroup
+	cfg        config.Config
+}
+
+// New builds the REST and gRPC servers over db and redis. Nothing runs until
+// the servers are started and Run is called for the background workers.
+func New(cfg config.Config, db *gorm.DB, redis *redis.Client) (*App, error) {
+	webhookRepo := repositories.NewWebhookRepository(db)
+	webhookService := services.NewWebhookService(webhookRepo)
+	webhookController := controllers.NewWebhookController(webhookService)
+	webhookDispatcher := services.NewWebhookDispatcher(webhookRepo, &http.Client{Timeout: cfg.WebhookTimeout, Transport: netguard.Transport()}, services.WebhookDispatcherConfig{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		RetryBase:   cfg.WebhookRetryBase,
+	})
+
+	// Live events fan out to every instance through Redis pub/sub
+	hub := stream.NewHub(redis, stream.Channel)
+	streamController := controllers.NewStreamController(hub)
+
+	empRepo := repositories.NewEmployeeRepository(db)
+	empService := services.NewEmployeeService(empRepo)
+	empController := controllers.NewEmployeeController(empService)
+
+	dashboardRepo := repositories.NewDashboardRepository(db)
+	dashboardService := services.NewDashboardService(dashboardRepo, redis, cfg.DashboardCacheTTL)
+	dashboardController := controllers.NewDashboardController(dashboardService)
+
+	// webhook deliveries are queued in the transaction of the write that raised the
+	// event, the stream and metrics only hear about it once the write committed
+	outbox := services.NewOutbox(db, dashboardService, services.Publishers{hub, metrics.Events{}})
+
+	authRepo := repositories.NewAuthRepository(db)
+	authService := services.NewAuthService(authRepo, cfg.JWTSecret, redis, outbox)
+	authController := controllers.NewAuthController(authService)
+
+	categoryRepo := repositories.NewCategoryRepository(db)
+	categoryService := services.NewCategoryService(categoryRepo)
+	categoryController := controllers.NewCategoryController(categoryService)
+
+	productRepo := repositories.NewProductRepository(db)
+	productService := services.NewProductService(productRepo, dashboardService, outbox)
+	productController := controllers.NewProductController(productService)
+
+	orderRepo := repositories.NewOrderRepository(db)
+	orderService := services.NewOrderService(orderRepo, dashboardService, outbox)
+	orderController := controllers.NewOrderController(orderService)
+
+	// the service layer REST batches, GraphQL and gRPC share
+	shared := services.Services{
+		Employees:  empService,
+		Categories: categoryService,
+		Products:   productService,
+		Orders:     orderService,
+	}
+	unitOfWork := services.NewUnitOfWork(outbox, shared)
+	batchController := controllers.NewBatchController(unitOfWork, routes.MountBatch, cfg.BatchMaxOperations)
+
+	reportRepo := repositories.NewReportRepository(db)
+	reportService := services.NewReportService(reportRepo)
+	reportController := controllers.NewReportController(reportService)
+
+	schema, err := gql.NewSchema(shared)
+	if err != nil {
+		return nil, err
+	}
+	graphqlController := controllers.NewGraphQLController(schema, shared)
+
+	grpcServer := grpcserver.NewServer(cfg.JWTSecret, cfg.RequestTimeout, func(ctx context.Context, token string) (bool, error) {
+		return redisClient.IsTokenBlacklisted(ctx, redis, token)
+	}, shared)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	healthController := controllers.NewHealthController(cfg.HealthCheckTimeout,
+		health.Check{Name: "database", Ping: sqlDB.PingContext},
+		health.Check{Name: "redis", Ping: func(ctx context.Context) error { return redis.Ping(ctx).Err() }},
+	)
+
+	app := fiber.New(fiber.Config{ErrorHandler: middlewares.ErrorHandler, DisableStartupMessage: true})
+	app.Use(middlewares.RequestID(), middlewares.Tracing(), middlewares.Metrics(), middlewares.AccessLog(), middlewares.CancelOnClientClose(), middlewares.Timeout(cfg.RequestTimeout))
+
+	var metricsServer *http.Server
+	metricsHandler := metrics.Handler(metrics.NewRegistry(sqlDB), cfg.MetricsUser, cfg.MetricsPassword)
+	if cfg.MetricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricsHandler)
+		metricsServer = &http.Server{Addr: ":" + cfg.MetricsPort, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	} else {
+		app.Get("/metrics", adaptor.HTTPHandler(metricsHandler))
+	}
+	app.Get("/swagger/*", swagger.HandlerDefault)
+
+	routes.SetupRoutes(app, cfg, redis, authController, empController, categoryController, productController, orderController, reportController, dashboardController, graphqlController, webhookController, streamController, batchController, healthController)
+
+	return &App{Fiber: app, GRPC: grpcServer, Metrics: metricsServer, hub: hub, dispatcher: webhookDispatcher, cfg: cfg}, nil
+}
+
+// Run delivers webhooks and relays live events until ctx is cancelled, Shutdown
+// waits for both to return
+func (a *App) Run(ctx context.Context) {
+	a.workers.Add(2)
+	go func() {
+		defer a.workers.Done()
+		a.dispatcher.Run(ctx, a.cfg.WebhookPollInterval)
+	}()
+	go func() {
+		defer a.workers.Done()
+		if err := a.hub.Run(ctx); err != nil {
+			slog.Error("Event stream stopped", "error", err)
+		}
+	}()
+}
+
+// Shutdown stops accepting connections and gives in-flight REST and gRPC calls up to
+// timeout to finish. Cancel the context passed to Run first, it ends the live event
+// streams that would otherwise keep their connections open until the timeout.
+// It returns once the workers started by Run have stopped too, so the DB and Redis
+// pools can be closed after it.
+func (a *App) Shutdown(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	grpcStopped := make(chan struct{})
+	go func() {
+		a.GRPC.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	err := a.Fiber.ShutdownWithTimeout(timeout)
+	select {
+	case <-grpcStopped:
+	case <-time.After(time.Until(deadline)):
+		a.GRPC.Stop()
+	}
+	if a.Metrics != nil {
+		// nothing to drain, a scrape cut short is retried
+		err = errors.Join(err, a.Metrics.Close())
+	}
+
+	workersStopped := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(workersStopped)
+	}()
+	select {
+	case <-workersStopped:
+	case <-time.After(time.Until(deadline)):
+		err = errors.Join(err, errors.New("background workers did not stop within the shutdown timeout"))
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/config"
+	"github.com/azizmasr1001/go-api-northwind/internal/apptest"
+	"github.com/azizmasr1001/go-api-northwind/internal/logging"
+	"github.com/azizmasr1001/go-api-northwind/internal/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestShutdown_DrainsInFlightRequests(t *testing.T) {
+	env := apptest.New(t)
+	started := make(chan struct{})
+	env.App.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.SendString("done")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = env.App.Listener(ln) }()
+	url := "http://" + ln.Addr().String()
+
+	type result struct {
+		status int
+		err    error
+	}
+	slow := make(chan result)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		resp.Body.Close()
+		slow <- result{status: resp.StatusCode}
+	}()
+	<-started
+
+	require.NoError(t, env.Server.Shutdown(5*time.Second))
+	res := <-slow
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.status)
+
+	_, err = http.Get(url + "/healthz")
+	assert.Error(t, err, "no new connections after shutdown")
+}
+
+func TestShutdown_WaitsForTheWorkers(t *testing.T) {
+	env := apptest.New(t)
+
+	// the dispatcher's first poll is still reading when shutdown starts
+	started := make(chan struct{})
+	var once sync.Once
+	var polled atomic.Bool
+	err := env.DB.Callback().Query().After("gorm:query").Register("test:slow_poll", func(db *gorm.DB) {
+		if db.Statement.Table == "webhook_deliveries" {
+			once.Do(func() { close(started) })
+			time.Sleep(200 * time.Millisecond)
+			polled.Store(true)
+		}
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	env.Server.Run(ctx)
+	<-started
+	cancel()
+
+	require.NoError(t, env.Server.Shutdown(5*time.Second))
+	assert.True(t, polled.Load(), "Shutdown returned while the dispatcher was still polling")
+}
+
+func TestMetrics_Exposed(t *testing.T) {
+	env := apptest.New(t)
+	failed := testutil.ToFloat64(metrics.Logins.WithLabelValues(metrics.LoginFailed))
+	orders := testutil.ToFloat64(metrics.OrdersCreated)
+
+	client := env.AsUser(t)
+	env.Client(t).Post("/api/login", map[string]string{"username": "nobody", "password": "wrong"})
+	product := env.Product(t)
+	employee := env.Employee(t)
+	res := client.Post("/api/orders", map[string]interface{}{
+		"employee_id":   employee.EmployeeID,
+		"ship_name":     "Metrics",
+		"order_details": []map[string]interface{}{{"product_id": product.ProductID, "unit_price": 10, "quantity": 2}},
+	})
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(res.Body))
+	client.Get("/api/nope/1")
+	env.Client(t).Get("/api/employees/3")
+
+	assert.Equal(t, failed+1, testutil.ToFloat64(metrics.Logins.WithLabelValues(metrics.LoginFailed)))
+	assert.Equal(t, orders+1, testutil.ToFloat64(metrics.OrdersCreated))
+
+	res = env.Client(t).Get("/metrics")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body := string(res.Body)
+	for _, want := range []string{
+		`http_requests_total{method="POST",route="/api/login",status="401"}`,
+		`http_requests_total{method="GET",route="unmatched",status="404"}`,
+		`http_requests_total{method="GET",route="/api/employees",status="401"}`,
+		`http_request_duration_seconds_bucket{method="POST",route="/api/orders/",status="201"`,
+		`db_query_duration_seconds_count{operation="create",table="Orders"}`,
+		`redis_command_duration_seconds_count{command="get"}`,
+		`go_sql_open_connections{db_name="northwind"}`,
+		`northwind_logins_total{result="success"}`,
+		`northwind_orders_created_total`,
+	} {
+		assert.Contains(t, body, want)
+	}
+}
+
+func TestMetrics_BasicAuth(t *testing.T) {
+	env := apptest.New(t, func(cfg *config.Config) {
+		cfg.MetricsUser = "prometheus"
+		cfg.MetricsPassword = "scrape"
+	})
+
+	client := env.Client(t)
+	assert.Equal(t, http.StatusUnauthorized, client.Get("/metrics").StatusCode)
+
+	client.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("prometheus:wrong")))
+	assert.Equal(t, http.StatusUnauthorized, client.Get("/metrics").StatusCode)
+
+	client.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("prometheus:scrape")))
+	assert.Equal(t, http.StatusOK, client.Get("/metrics").StatusCode)
+}
+
+func TestMetrics_OwnPort(t *testing.T) {
+	env := apptest.New(t, func(cfg *config.Config) { cfg.MetricsPort = "0" })
+
+	assert.Equal(t, http.StatusNotFound, env.Client(t).Get("/metrics").StatusCode)
+	require.NotNil(t, env.Server.Metrics)
+
+	rec := httptest.NewRecorder()
+	env.Server.Metrics.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
+
+func TestLogging_SQLCarriesTheRequestID(t *testing.T) {
+	env := apptest.New(t)
+	client := env.AsUser(t)
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "debug")
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	env.DB.Logger = logging.NewGorm(time.Second)
+
+	client.Header.Set("X-Request-ID", "req-sql-1")
+	require.Equal(t, http.StatusOK, client.Get("/api/products").StatusCode)
+
+	var sql, access int
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		switch {
+		case entry["sql"] != nil:
+			sql++
+			assert.Equal(t, "req-sql-1", entry["request_id"], line)
+		case entry["msg"] == "request":
+			access++
+			assert.Equal(t, "req-sql-1", entry["request_id"], line)
+		}
+	}
+	assert.Positive(t, sql, buf.String())
+	assert.Equal(t, 1, access, buf.String())
+}
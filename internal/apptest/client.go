@@ -0,0 +1,122 @@
+package apptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/require"
+)
+
+// Client sends requests to the app, with a bearer token when Token is set
+type Client struct {
+	t     testing.TB
+	env   *Env
+	Token string
+	// Header is added to every request
+	Header http.Header
+}
+
+// Response is a finished response with its body read
+type Response struct {
+	*http.Response
+	Body []byte
+	t    testing.TB
+}
+
+// Decode unmarshals the whole body into v
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	require.NoError(r.t, json.Unmarshal(r.Body, v), string(r.Body))
+}
+
+// Data unmarshals the data field of a success envelope into v
+func (r *Response) Data(v interface{}) {
+	r.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	r.Decode(&envelope)
+	require.NoError(r.t, json.Unmarshal(envelope.Data, v), string(r.Body))
+}
+
+// Client returns a client that is not logged in
+func (e *Env) Client(t testing.TB) *Client {
+	return &Client{t: t, env: e, Header: http.Header{}}
+}
+
+// LoginAs logs user in through /api/login, user must have the password Password
+func (e *Env) LoginAs(t testing.TB, user *models.User) *Client {
+	t.Helper()
+	c := e.Client(t)
+	res := c.Post("/api/login", map[string]string{"username": user.Username, "password": Password})
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+
+	var tokens struct {
+		Token string `json:"token"`
+	}
+	res.Data(&tokens)
+	c.Token = tokens.Token
+	return c
+}
+
+// AsUser logs in a new user with role user
+func (e *Env) AsUser(t testing.TB) *Client { return e.LoginAs(t, e.User(t)) }
+
+// AsAdmin logs in a new user with role admin
+func (e *Env) AsAdmin(t testing.TB) *Client { return e.LoginAs(t, e.Admin(t)) }
+
+// Do sends body as JSON, or as is when it is a string or []byte, nil sends no body
+func (c *Client) Do(method, path string, body interface{}) *Response {
+	c.t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		raw, err := json.Marshal(b)
+		require.NoError(c.t, err)
+		reader = bytes.NewReader(raw)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.env.App.Test(req, -1)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	return &Response{Response: resp, Body: raw, t: c.t}
+}
+
+func (c *Client) Get(path string) *Response { return c.Do(http.MethodGet, path, nil) }
+
+func (c *Client) Delete(path string) *Response { return c.Do(http.MethodDelete, path, nil) }
+
+func (c *Client) Post(path string, body interface{}) *Response {
+	return c.Do(http.MethodPost, path, body)
+}
+
+func (c *Client) Put(path string, body interface{}) *Response {
+	return c.Do(http.MethodPut, path, body)
+}
+
+func (c *Client) Patch(path string, body interface{}) *Response {
+	return c.Do(http.MethodPatch, path, body)
+}
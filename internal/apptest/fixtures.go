@@ -0,0 +1,111 @@
+package apptest
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password is the password of every user made by User
+const Password = "secret123"
+
+// sequence keeps generated names unique within a test binary
+var sequence atomic.Int64
+
+func next() int64 { return sequence.Add(1) }
+
+// create inserts row after applying opts to it
+func create[T any](t testing.TB, e *Env, row T, opts []func(*T)) *T {
+	t.Helper()
+	for _, opt := range opts {
+		opt(&row)
+	}
+	require.NoError(t, e.DB.Create(&row).Error)
+	return &row
+}
+
+// User inserts an active user with role user and the password Password
+func (e *Env) User(t testing.TB, opts ...func(*models.User)) *models.User {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+	require.NoError(t, err)
+	n := next()
+	return create(t, e, models.User{
+		Username:     fmt.Sprintf("user%d", n),
+		Email:        fmt.Sprintf("user%d@example.com", n),
+		PasswordHash: string(hashed),
+		Role:         models.RoleUser,
+		IsActive:     true,
+	}, opts)
+}
+
+// Admin inserts a user with role admin
+func (e *Env) Admin(t testing.TB, opts ...func(*models.User)) *models.User {
+	return e.User(t, append([]func(*models.User){func(u *models.User) { u.Role = models.RoleAdmin }}, opts...)...)
+}
+
+func (e *Env) Category(t testing.TB, opts ...func(*models.Category)) *models.Category {
+	return create(t, e, models.Category{
+		CategoryName: fmt.Sprintf("Category %d", next()),
+		Description:  "Made by a test",
+	}, opts)
+}
+
+// Product inserts a product in stock, in a category of its own unless opts set one
+func (e *Env) Product(t testing.TB, opts ...func(*models.Product)) *models.Product {
+	product := models.Product{
+		ProductName:     fmt.Sprintf("Product %d", next()),
+		QuantityPerUnit: "10 boxes",
+		UnitPrice:       10,
+		UnitsInStock:    50,
+		ReorderLevel:    5,
+	}
+	for _, opt := range opts {
+		opt(&product)
+	}
+	if product.CategoryID == nil {
+		product.CategoryID = &e.Category(t).CategoryID
+	}
+	return create(t, e, product, nil)
+}
+
+func (e *Env) Employee(t testing.TB, opts ...func(*models.Employee)) *models.Employee {
+	hired := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	return create(t, e, models.Employee{
+		LastName:  fmt.Sprintf("Tester%d", next()),
+		FirstName: "Terry",
+		Title:     "Sales Representative",
+		HireDate:  &hired,
+		City:      "London",
+		Country:   "UK",
+	}, opts)
+}
+
+// Order inserts an order taken by a new employee with one line per product,
+// a new product when none are given
+func (e *Env) Order(t testing.TB, products []*models.Product, opts ...func(*models.Order)) (*models.Order, []models.OrderDetail) {
+	employee := e.Employee(t)
+	ordered := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	order := create(t, e, models.Order{
+		EmployeeID:  &employee.EmployeeID,
+		OrderDate:   &ordered,
+		Freight:     12.5,
+		ShipName:    "Test Shipment",
+		ShipCity:    "London",
+		ShipCountry: "UK",
+	}, opts)
+
+	if len(products) == 0 {
+		products = []*models.Product{e.Product(t)}
+	}
+	details := make([]models.OrderDetail, len(products))
+	for i, p := range products {
+		details[i] = models.OrderDetail{OrderID: order.OrderID, ProductID: p.ProductID, UnitPrice: p.UnitPrice, Quantity: 1}
+	}
+	require.NoError(t, e.DB.Create(&details).Error)
+	return order, details
+}
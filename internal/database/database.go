@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/logging"
+	"github.com/azizmasr1001/go-api-northwind/internal/metrics"
+	"github.com/azizmasr1001/go-api-northwind/internal/tracing"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+// slowQuery is how long a statement may take before it is logged as a warning
+const slowQuery = 200 * time.Millisecond
+
+const (
+	DriverSQLServer = "sqlserver"
+	DriverPostgres  = "postgres"
+	DriverMySQL     = "mysql"
+	DriverSQLite    = "sqlite"
+)
+
+// Open connects to source with the dialector picked by driver and installs the
+// repository error translator. An empty driver means SQL Server.
+func Open(driver, source string) (*gorm.DB, error) {
+	dialector, err := Dialector(driver, source)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logging.NewGorm(slowQuery)})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(repositories.ErrorTranslator{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(metrics.Gorm{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.Gorm{}); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Dialector returns the GORM dialector for driver
+func Dialector(driver, source string) (gorm.Dialector, error) {
+	switch strings.ToLower(driver) {
+	case "", DriverSQLServer, "mssql":
+		return sqlserver.Open(source), nil
+	case DriverPostgres, "postgresql":
+		return postgres.Open(source), nil
+	case DriverMySQL:
+		cfg, err := mysqldriver.ParseDSN(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mysql DSN: %w", err)
+		}
+		// repositories quote identifiers with double quotes
+		if cfg.Params == nil {
+			cfg.Params = map[string]string{}
+		}
+		cfg.Params["sql_mode"] = "CONCAT(@@sql_mode, ',ANSI_QUOTES')"
+		cfg.ParseTime = true
+		return mysql.New(mysql.Config{DSNConfig: cfg}), nil
+	case DriverSQLite, "sqlite3":
+		return sqlite.Open(withForeignKeys(source)), nil
+	}
+	return nil, fmt.Errorf("unsupported DB_DRIVER %q, use sqlserver, postgres, mysql or sqlite", driver)
+}
+
+// withForeignKeys turns on foreign key enforcement, SQLite leaves it off by default
+func withForeignKeys(source string) string {
+	if strings.Contains(source, "_foreign_keys=") || strings.Contains(source, "_fk=") {
+		return source
+	}
+	if strings.Contains(source, "?") {
+		return source + "&_foreign_keys=1"
+	}
+	return source + "?_foreign_keys=1"
+}
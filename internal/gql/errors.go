@@ -0,0 +1,51 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// FormatErrors rewrites the errors of a result the way the REST error handler does.
+// A resolver error that services.AsError translates keeps its message, its code goes
+// to extensions; any other resolver error is logged and shown as "internal error" so
+// driver and GORM messages never reach the client. Syntax and validation errors of
+// the query itself are returned as they are.
+func FormatErrors(ctx context.Context, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i, ferr := range errs {
+		cause := ferr.OriginalError()
+		var located *gqlerrors.Error
+		if errors.As(cause, &located) {
+			cause = located.OriginalError
+		}
+		if cause == nil {
+			continue
+		}
+
+		formatted := gqlerrors.FormattedError{Locations: ferr.Locations, Path: ferr.Path}
+		if svcErr := services.AsError(cause); svcErr != nil {
+			// svcErr.Error() would add the wrapped driver message
+			formatted.Message = svcErr.Message
+			formatted.Extensions = map[string]interface{}{"code": svcErr.Code}
+			if len(svcErr.Details) > 0 {
+				formatted.Extensions["errors"] = svcErr.Details
+			}
+		} else if errors.Is(cause, context.DeadlineExceeded) {
+			slog.WarnContext(ctx, "GraphQL request timed out", "path", ferr.Path, "error", cause)
+			formatted.Message = "Request timed out"
+			formatted.Extensions = map[string]interface{}{"code": services.CodeTimeout}
+		} else if located == nil {
+			// an error of the query itself, e.g. an unknown operation name
+			continue
+		} else {
+			slog.ErrorContext(ctx, "Unhandled GraphQL error", "path", ferr.Path, "error", cause)
+			formatted.Message = "internal error"
+			formatted.Extensions = map[string]interface{}{"code": services.CodeInternal}
+		}
+		errs[i] = formatted
+	}
+	return errs
+}
@@ -0,0 +1,78 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+type loadersKey struct{}
+
+// Loaders batch the relationship lookups of one GraphQL request so a list of N orders
+// costs one query for their lines instead of N
+type Loaders struct {
+	Employee   *dataloader.Loader[int, *models.Employee]
+	Category   *dataloader.Loader[int, *models.Category]
+	Product    *dataloader.Loader[int, *models.Product]
+	OrderLines *dataloader.Loader[int, []models.OrderDetail]
+}
+
+// NewLoaders creates fresh, request scoped loaders; their caches must not outlive the request
+func NewLoaders(svc services.Services) *Loaders {
+	return &Loaders{
+		Employee: dataloader.NewBatchedLoader(byID(func(ctx context.Context, ids []int) ([]models.Employee, error) {
+			return svc.Employees.GetByIDs(ctx, ids)
+		}, func(e models.Employee) int { return e.EmployeeID })),
+		Category: dataloader.NewBatchedLoader(byID(func(ctx context.Context, ids []int) ([]models.Category, error) {
+			return svc.Categories.GetByIDs(ctx, ids)
+		}, func(c models.Category) int { return c.CategoryID })),
+		Product: dataloader.NewBatchedLoader(byID(func(ctx context.Context, ids []int) ([]models.Product, error) { return svc.Products.GetByIDs(ctx, ids) }, func(p models.Product) int { return p.ProductID })),
+		OrderLines: dataloader.NewBatchedLoader(func(ctx context.Context, ids []int) []*dataloader.Result[[]models.OrderDetail] {
+			results := make([]*dataloader.Result[[]models.OrderDetail], len(ids))
+			details, err := svc.Orders.GetDetailsByOrderIDs(ctx, ids)
+			grouped := map[int][]models.OrderDetail{}
+			for _, d := range details {
+				grouped[d.OrderID] = append(grouped[d.OrderID], d)
+			}
+			for i, id := range ids {
+				results[i] = &dataloader.Result[[]models.OrderDetail]{Data: grouped[id], Error: err}
+			}
+			return results
+		}),
+	}
+}
+
+// WithLoaders attaches loaders to ctx for the resolvers
+func WithLoaders(ctx context.Context, l *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+// byID adapts a GetByIDs service method into a batch function that keeps the key order
+func byID[T any](fetch func(context.Context, []int) ([]T, error), idOf func(T) int) dataloader.BatchFunc[int, *T] {
+	return func(ctx context.Context, ids []int) []*dataloader.Result[*T] {
+		results := make([]*dataloader.Result[*T], len(ids))
+		rows, err := fetch(ctx, ids)
+		found := make(map[int]*T, len(rows))
+		for i := range rows {
+			found[idOf(rows[i])] = &rows[i]
+		}
+		for i, id := range ids {
+			switch {
+			case err != nil:
+				results[i] = &dataloader.Result[*T]{Error: err}
+			case found[id] == nil:
+				results[i] = &dataloader.Result[*T]{Error: services.NotFound(services.CodeNotFound, fmt.Sprintf("record %d not found", id))}
+			default:
+				results[i] = &dataloader.Result[*T]{Data: found[id]}
+			}
+		}
+		return results
+	}
+}
@@ -0,0 +1,272 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+)
+
+type userIDKey struct{}
+
+// WithUserID records the authenticated caller, deletes are stamped with it
+func WithUserID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+func userID(ctx context.Context) int {
+	id, _ := ctx.Value(userIDKey{}).(int)
+	return id
+}
+
+func inputFields(required []string, fields graphql.InputObjectConfigFieldMap) graphql.InputObjectConfigFieldMap {
+	for _, name := range required {
+		fields[name].Type = graphql.NewNonNull(fields[name].Type)
+	}
+	return fields
+}
+
+// mutations mirror the REST create, update and delete operations
+func mutations(svc services.Services, validate *validator.Validate, employeeType, categoryType, productType, orderType *graphql.Object) graphql.Fields {
+	employeeInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "EmployeeInput",
+		Fields: inputFields([]string{"last_name", "first_name"}, graphql.InputObjectConfigFieldMap{
+			"last_name":         {Type: graphql.String},
+			"first_name":        {Type: graphql.String},
+			"title":             {Type: graphql.String},
+			"title_of_courtesy": {Type: graphql.String},
+			"birth_date":        {Type: graphql.DateTime},
+			"hire_date":         {Type: graphql.DateTime},
+			"address":           {Type: graphql.String},
+			"city":              {Type: graphql.String},
+			"region":            {Type: graphql.String},
+			"postal_code":       {Type: graphql.String},
+			"country":           {Type: graphql.String},
+			"home_phone":        {Type: graphql.String},
+			"extension":         {Type: graphql.String},
+			"notes":             {Type: graphql.String},
+			"reports_to":        {Type: graphql.Int},
+			"photo_path":        {Type: graphql.String},
+		}),
+	})
+	categoryInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CategoryInput",
+		Fields: inputFields([]string{"category_name"}, graphql.InputObjectConfigFieldMap{
+			"category_name": {Type: graphql.String},
+			"description":   {Type: graphql.String},
+		}),
+	})
+	productInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductInput",
+		Fields: inputFields([]string{"product_name"}, graphql.InputObjectConfigFieldMap{
+			"product_name":      {Type: graphql.String},
+			"supplier_id":       {Type: graphql.Int},
+			"category_id":       {Type: graphql.Int},
+			"quantity_per_unit": {Type: graphql.String},
+			"unit_price":        {Type: graphql.Float},
+			"units_in_stock":    {Type: graphql.Int},
+			"units_on_order":    {Type: graphql.Int},
+			"reorder_level":     {Type: graphql.Int},
+			"discontinued":      {Type: graphql.Boolean},
+		}),
+	})
+	orderDetailInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "OrderDetailInput",
+		Fields: inputFields([]string{"product_id"}, graphql.InputObjectConfigFieldMap{
+			"product_id": {Type: graphql.Int},
+			"unit_price": {Type: graphql.Float},
+			"quantity":   {Type: graphql.Int},
+			"discount":   {Type: graphql.Float},
+		}),
+	})
+	orderInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "OrderInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"customer_id":      {Type: graphql.String},
+			"employee_id":      {Type: graphql.Int},
+			"order_date":       {Type: graphql.DateTime},
+			"required_date":    {Type: graphql.DateTime},
+			"shipped_date":     {Type: graphql.DateTime},
+			"ship_via":         {Type: graphql.Int},
+			"freight":          {Type: graphql.Float},
+			"ship_name":        {Type: graphql.String},
+			"ship_address":     {Type: graphql.String},
+			"ship_city":        {Type: graphql.String},
+			"ship_region":      {Type: graphql.String},
+			"ship_postal_code": {Type: graphql.String},
+			"ship_country":     {Type: graphql.String},
+			"order_details":    {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderDetailInput)))},
+		},
+	})
+
+	idArg := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+	input := func(t *graphql.InputObject) *graphql.ArgumentConfig {
+		return &graphql.ArgumentConfig{Type: graphql.NewNonNull(t)}
+	}
+
+	return graphql.Fields{
+		"createEmployee": &graphql.Field{
+			Type: employeeType,
+			Args: graphql.FieldConfigArgument{"input": input(employeeInput)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				emp, err := decodeInput[models.Employee](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				return svc.Employees.Create(p.Context, emp)
+			},
+		},
+		"updateEmployee": &graphql.Field{
+			Type: employeeType,
+			Args: graphql.FieldConfigArgument{"id": idArg, "input": input(employeeInput)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id := p.Args["id"].(int)
+				emp, err := decodeInput[models.Employee](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				emp.EmployeeID = id
+				var updated models.Employee
+				err = svc.Employees.Locked(p.Context, id, func(s services.EmployeeService) error {
+					_, err := s.GetByID(p.Context, id)
+					if err != nil {
+						return err
+					}
+					updated, err = s.Update(p.Context, emp)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+				return updated, nil
+			},
+		},
+		"deleteEmployee": &graphql.Field{
+			Type: graphql.Boolean,
+			Args: graphql.FieldConfigArgument{"id": idArg},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return true, svc.Employees.Delete(p.Context, p.Args["id"].(int), userID(p.Context))
+			},
+		},
+
+		"createCategory": &graphql.Field{
+			Type: categoryType,
+			Args: graphql.FieldConfigArgument{"input": input(categoryInput)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				cat, err := decodeInput[models.Category](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				return svc.Categories.Create(p.Context, cat)
+			},
+		},
+		"updateCategory": &graphql.Field{
+			Type: categoryType,
+			Args: graphql.FieldConfigArgument{"id": idArg, "input": input(categoryInput)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id := p.Args["id"].(int)
+				cat, err := decodeInput[models.Category](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				cat.CategoryID = id
+				var updated models.Category
+				err = svc.Categories.Locked(p.Context, id, func(s services.CategoryService) error {
+					_, err := s.GetByID(p.Context, id)
+					if err != nil {
+						return err
+					}
+					updated, err = s.Update(p.Context, cat)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+				return updated, nil
+			},
+		},
+		"deleteCategory": &graphql.Field{
+			Type: graphql.Boolean,
+			Args: graphql.FieldConfigArgument{"id": idArg},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return true, svc.Categories.Delete(p.Context, p.Args["id"].(int), userID(p.Context))
+			},
+		},
+
+		"createProduct": &graphql.Field{
+			Type: productType,
+			Args: graphql.FieldConfigArgument{"input": input(productInput)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				prod, err := decodeInput[models.Product](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				return svc.Products.Create(p.Context, prod)
+			},
+		},
+		"updateProduct": &graphql.Field{
+			Type: productType,
+			Args: graphql.FieldConfigArgument{"id": idArg, "input": input(productInput)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id := p.Args["id"].(int)
+				prod, err := decodeInput[models.Product](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				prod.ProductID = id
+				var updated *models.Product
+				err = svc.Products.Locked(p.Context, id, func(s services.ProductService) error {
+					_, err := s.GetByID(p.Context, id)
+					if err != nil {
+						return err
+					}
+					updated, err = s.Update(p.Context, prod)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+				return updated, nil
+			},
+		},
+		"deleteProduct": &graphql.Field{
+			Type: graphql.Boolean,
+			Args: graphql.FieldConfigArgument{"id": idArg},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return true, svc.Products.Delete(p.Context, p.Args["id"].(int), userID(p.Context))
+			},
+		},
+
+		"createOrder": &graphql.Field{
+			Type: orderType,
+			Args: graphql.FieldConfigArgument{"input": input(orderInputType)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				in, err := decodeInput[models.OrderInput](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				return svc.Orders.Create(p.Context, &in.Order, in.OrderDetails)
+			},
+		},
+		"updateOrder": &graphql.Field{
+			Type: orderType,
+			Args: graphql.FieldConfigArgument{"id": idArg, "input": input(orderInputType)},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				in, err := decodeInput[models.OrderInput](validate, p.Args["input"])
+				if err != nil {
+					return nil, err
+				}
+				return svc.Orders.Update(p.Context, p.Args["id"].(int), &in.Order, in.OrderDetails)
+			},
+		},
+		"deleteOrder": &graphql.Field{
+			Type: graphql.Boolean,
+			Args: graphql.FieldConfigArgument{"id": idArg},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return true, svc.Orders.Delete(p.Context, p.Args["id"].(int), userID(p.Context))
+			},
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Operation returns the type (query, mutation or subscription) of the operation a
+// request runs: the one called name, or the only one when name is empty. ok is false
+// when the document does not parse or names no such operation, graphql.Do reports that.
+func Operation(query, name string) (string, bool) {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return "", false
+	}
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, isOp := def.(*ast.OperationDefinition)
+		if !isOp {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return "", false
+			}
+			found = op
+		} else if op.Name != nil && op.Name.Value == name {
+			found = op
+		}
+	}
+	if found == nil {
+		return "", false
+	}
+	return found.Operation, true
+}
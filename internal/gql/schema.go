@@ -0,0 +1,298 @@
+package gql
+
+import (
+	"encoding/json"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+)
+
+// NewSchema builds the schema. Field names follow the JSON names of the REST API so
+// the default resolver can read them straight off the models.
+func NewSchema(svc services.Services) (graphql.Schema, error) {
+	validate := validator.New()
+
+	var employeeType *graphql.Object
+	employeeType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Employee",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := graphql.Fields{
+				"employee_id": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"last_name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"first_name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"birth_date":  &graphql.Field{Type: graphql.DateTime},
+				"hire_date":   &graphql.Field{Type: graphql.DateTime},
+				"reports_to":  &graphql.Field{Type: graphql.Int},
+				"manager": &graphql.Field{
+					Type: employeeType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						emp := employeeFrom(p.Source)
+						if emp == nil || emp.ReportsTo == nil {
+							return nil, nil
+						}
+						thunk := loadersFrom(p.Context).Employee.Load(p.Context, *emp.ReportsTo)
+						return func() (interface{}, error) { return thunk() }, nil
+					},
+				},
+			}
+			for _, name := range []string{"title", "title_of_courtesy", "address", "city", "region", "postal_code",
+				"country", "home_phone", "extension", "notes", "photo_path"} {
+				fields[name] = &graphql.Field{Type: graphql.String}
+			}
+			return fields
+		}),
+	})
+
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"category_id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"category_name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"product_id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"product_name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"supplier_id":       &graphql.Field{Type: graphql.Int},
+			"category_id":       &graphql.Field{Type: graphql.Int},
+			"quantity_per_unit": &graphql.Field{Type: graphql.String},
+			"unit_price":        &graphql.Field{Type: graphql.Float},
+			"units_in_stock":    &graphql.Field{Type: graphql.Int},
+			"units_on_order":    &graphql.Field{Type: graphql.Int},
+			"reorder_level":     &graphql.Field{Type: graphql.Int},
+			"discontinued":      &graphql.Field{Type: graphql.Boolean},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					prod := productFrom(p.Source)
+					if prod == nil || prod.CategoryID == nil {
+						return nil, nil
+					}
+					thunk := loadersFrom(p.Context).Category.Load(p.Context, *prod.CategoryID)
+					return func() (interface{}, error) { return thunk() }, nil
+				},
+			},
+		},
+	})
+
+	orderDetailType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderDetail",
+		Fields: graphql.Fields{
+			"order_id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"product_id": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"unit_price": &graphql.Field{Type: graphql.Float},
+			"quantity":   &graphql.Field{Type: graphql.Int},
+			"discount":   &graphql.Field{Type: graphql.Float},
+			"product": &graphql.Field{
+				Type: productType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					detail, ok := p.Source.(models.OrderDetail)
+					if !ok {
+						return nil, nil
+					}
+					thunk := loadersFrom(p.Context).Product.Load(p.Context, detail.ProductID)
+					return func() (interface{}, error) { return thunk() }, nil
+				},
+			},
+		},
+	})
+
+	orderFields := graphql.Fields{
+		"order_id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"customer_id":      &graphql.Field{Type: graphql.String},
+		"employee_id":      &graphql.Field{Type: graphql.Int},
+		"order_date":       &graphql.Field{Type: graphql.DateTime},
+		"required_date":    &graphql.Field{Type: graphql.DateTime},
+		"shipped_date":     &graphql.Field{Type: graphql.DateTime},
+		"ship_via":         &graphql.Field{Type: graphql.Int},
+		"freight":          &graphql.Field{Type: graphql.Float},
+		"ship_name":        &graphql.Field{Type: graphql.String},
+		"ship_address":     &graphql.Field{Type: graphql.String},
+		"ship_city":        &graphql.Field{Type: graphql.String},
+		"ship_region":      &graphql.Field{Type: graphql.String},
+		"ship_postal_code": &graphql.Field{Type: graphql.String},
+		"ship_country":     &graphql.Field{Type: graphql.String},
+		"employee": &graphql.Field{
+			Type: employeeType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				order := orderFrom(p.Source)
+				if order == nil || order.EmployeeID == nil {
+					return nil, nil
+				}
+				thunk := loadersFrom(p.Context).Employee.Load(p.Context, *order.EmployeeID)
+				return func() (interface{}, error) { return thunk() }, nil
+			},
+		},
+		"lines": &graphql.Field{
+			Type: graphql.NewList(orderDetailType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				order := orderFrom(p.Source)
+				if order == nil {
+					return nil, nil
+				}
+				thunk := loadersFrom(p.Context).OrderLines.Load(p.Context, order.OrderID)
+				return func() (interface{}, error) { return thunk() }, nil
+			},
+		},
+	}
+	orderType := graphql.NewObject(graphql.ObjectConfig{Name: "Order", Fields: orderFields})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"page":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 10},
+	}
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"employees": &graphql.Field{
+				Type: graphql.NewList(employeeType),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					page, limit, err := pagination(p)
+					if err != nil {
+						return nil, err
+					}
+					rows, _, err := svc.Employees.GetAllPaginated(p.Context, page, limit)
+					return rows, err
+				},
+			},
+			"employee": &graphql.Field{
+				Type: employeeType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return svc.Employees.GetByID(p.Context, p.Args["id"].(int))
+				},
+			},
+			"categories": &graphql.Field{
+				Type: graphql.NewList(categoryType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return svc.Categories.GetAll(p.Context)
+				},
+			},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return svc.Categories.GetByID(p.Context, p.Args["id"].(int))
+				},
+			},
+			"products": &graphql.Field{
+				Type: graphql.NewList(productType),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					page, limit, err := pagination(p)
+					if err != nil {
+						return nil, err
+					}
+					rows, _, err := svc.Products.GetAllPaginated(p.Context, page, limit)
+					return rows, err
+				},
+			},
+			"product": &graphql.Field{
+				Type: productType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return svc.Products.GetByID(p.Context, p.Args["id"].(int))
+				},
+			},
+			"orders": &graphql.Field{
+				Type: graphql.NewList(orderType),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					page, limit, err := pagination(p)
+					if err != nil {
+						return nil, err
+					}
+					rows, _, err := svc.Orders.GetAll(p.Context, page, limit)
+					return rows, err
+				},
+			},
+			"order": &graphql.Field{
+				Type: orderType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					order, _, err := svc.Orders.GetByID(p.Context, p.Args["id"].(int), nil, nil)
+					return order, err
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Mutation",
+		Fields: mutations(svc, validate, employeeType, categoryType, productType, orderType),
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func pagination(p graphql.ResolveParams) (int, int, error) {
+	page, _ := p.Args["page"].(int)
+	limit, _ := p.Args["limit"].(int)
+	if page <= 0 || limit <= 0 {
+		return 0, 0, services.Invalid(services.CodeInvalidParameter, "page and limit must be positive numbers")
+	}
+	return page, limit, nil
+}
+
+// decodeInput maps a GraphQL input object onto a model through its JSON tags and
+// validates it with the same rules as the REST handlers
+func decodeInput[T any](validate *validator.Validate, input interface{}) (*T, error) {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var out T
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, services.Invalid(services.CodeInvalidBody, "Invalid input").Wrap(err)
+	}
+	if err := validate.Struct(out); err != nil {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			details := utils.FormatValidationErrors(errs)
+			return nil, services.Invalid(services.CodeValidationFailed, "validation failed: "+details[0].Message, details...)
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func employeeFrom(src interface{}) *models.Employee {
+	switch v := src.(type) {
+	case models.Employee:
+		return &v
+	case *models.Employee:
+		return v
+	}
+	return nil
+}
+
+func productFrom(src interface{}) *models.Product {
+	switch v := src.(type) {
+	case models.Product:
+		return &v
+	case *models.Product:
+		return v
+	}
+	return nil
+}
+
+func orderFrom(src interface{}) *models.Order {
+	switch v := src.(type) {
+	case models.Order:
+		return &v
+	case *models.Order:
+		return v
+	}
+	return nil
+}
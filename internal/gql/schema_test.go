@@ -0,0 +1,67 @@
+package gql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubOrders struct {
+	services.OrderService
+	detailCalls int
+}
+
+func (s *stubOrders) GetAll(_ context.Context, page, limit int, columns ...string) ([]models.Order, int64, error) {
+	return []models.Order{{OrderID: 1}, {OrderID: 2}, {OrderID: 3}}, 3, nil
+}
+
+func (s *stubOrders) GetDetailsByOrderIDs(_ context.Context, ids []int) ([]models.OrderDetail, error) {
+	s.detailCalls++
+	var out []models.OrderDetail
+	for _, id := range ids {
+		out = append(out, models.OrderDetail{OrderID: id, ProductID: 10 + id})
+	}
+	return out, nil
+}
+
+type stubProducts struct {
+	services.ProductService
+	calls int
+}
+
+func (s *stubProducts) GetByIDs(_ context.Context, ids []int) ([]models.Product, error) {
+	s.calls++
+	var out []models.Product
+	for _, id := range ids {
+		out = append(out, models.Product{ProductID: id, ProductName: "p"})
+	}
+	return out, nil
+}
+
+func TestOrderLines_AreBatched(t *testing.T) {
+	orders := &stubOrders{}
+	products := &stubProducts{}
+	svc := services.Services{Orders: orders, Products: products}
+
+	schema, err := NewSchema(svc)
+	assert.NoError(t, err)
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ orders { order_id lines { product_id product { product_name } } } }`,
+		Context:       WithLoaders(context.Background(), NewLoaders(svc)),
+	})
+
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 1, orders.detailCalls)
+	assert.Equal(t, 1, products.calls)
+
+	data := result.Data.(map[string]interface{})["orders"].([]interface{})
+	assert.Len(t, data, 3)
+	line := data[2].(map[string]interface{})["lines"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 13, line["product_id"])
+}
@@ -0,0 +1,64 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RevocationCheck reports whether a token was blacklisted by /api/logout
+type RevocationCheck func(ctx context.Context, token string) (bool, error)
+
+type claimsKey struct{}
+
+// Claims returns the JWT claims of the authenticated caller
+func Claims(ctx context.Context) jwt.MapClaims {
+	claims, _ := ctx.Value(claimsKey{}).(jwt.MapClaims)
+	return claims
+}
+
+// userID reads the user_id claim of the caller, 0 if absent
+func userID(ctx context.Context) int {
+	id, _ := Claims(ctx)["user_id"].(float64)
+	return int(id)
+}
+
+// authInterceptor applies the same checks as middlewares.ProtectedWithRedis
+func authInterceptor(jwtSecret string, revoked RevocationCheck) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		header := ""
+		if values := md.Get("authorization"); len(values) > 0 {
+			header = values[0]
+		}
+		if header == "" || !strings.HasPrefix(header, "Bearer ") {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid token")
+		}
+
+		tokenStr := strings.TrimPrefix(header, "Bearer ")
+		blacklisted, err := revoked(ctx, tokenStr)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "token check failed")
+		}
+		if blacklisted {
+			return nil, status.Error(codes.Unauthenticated, "token has been revoked")
+		}
+
+		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(jwtSecret), nil
+		})
+		if err != nil || !token.Valid {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return handler(context.WithValue(ctx, claimsKey{}, token.Claims.(jwt.MapClaims)), req)
+	}
+}
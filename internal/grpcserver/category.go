@@ -0,0 +1,88 @@
+package grpcserver
+
+import (
+	"context"
+
+	northwindv1 "github.com/azizmasr1001/go-api-northwind/gen/northwind/v1"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type categoryServer struct {
+	northwindv1.UnimplementedCategoryServiceServer
+	service  services.CategoryService
+	validate *validator.Validate
+}
+
+func (s *categoryServer) ListCategories(ctx context.Context, req *northwindv1.ListCategoriesRequest) (*northwindv1.ListCategoriesResponse, error) {
+	categories, err := s.service.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &northwindv1.ListCategoriesResponse{}
+	for i := range categories {
+		resp.Categories = append(resp.Categories, toCategory(&categories[i]))
+	}
+	return resp, nil
+}
+
+func (s *categoryServer) GetCategory(ctx context.Context, req *northwindv1.GetCategoryRequest) (*northwindv1.Category, error) {
+	cat, err := s.service.GetByID(ctx, int(req.GetCategoryId()))
+	if err != nil {
+		return nil, err
+	}
+	return toCategory(cat), nil
+}
+
+func (s *categoryServer) CreateCategory(ctx context.Context, req *northwindv1.CreateCategoryRequest) (*northwindv1.Category, error) {
+	if req.GetCategory() == nil {
+		return nil, missing("category")
+	}
+	cat := fromCategory(req.GetCategory())
+	cat.CategoryID = 0
+	if err := s.validate.Struct(cat); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.Create(ctx, cat)
+	if err != nil {
+		return nil, err
+	}
+	return toCategory(&created), nil
+}
+
+func (s *categoryServer) UpdateCategory(ctx context.Context, req *northwindv1.UpdateCategoryRequest) (*northwindv1.Category, error) {
+	if req.GetCategory() == nil {
+		return nil, missing("category")
+	}
+	id := int(req.GetCategoryId())
+	cat := fromCategory(req.GetCategory())
+	cat.CategoryID = id
+	if err := s.validate.Struct(cat); err != nil {
+		return nil, err
+	}
+
+	var updated models.Category
+	err := s.service.Locked(ctx, id, func(svc services.CategoryService) error {
+		_, err := svc.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		updated, err = svc.Update(ctx, cat)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toCategory(&updated), nil
+}
+
+func (s *categoryServer) DeleteCategory(ctx context.Context, req *northwindv1.DeleteCategoryRequest) (*emptypb.Empty, error) {
+	if err := s.service.Delete(ctx, int(req.GetCategoryId()), userID(ctx)); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
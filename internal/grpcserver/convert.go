@@ -0,0 +1,188 @@
+package grpcserver
+
+import (
+	"time"
+
+	northwindv1 "github.com/azizmasr1001/go-api-northwind/gen/northwind/v1"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func toInt32(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	n := int32(*v)
+	return &n
+}
+
+func fromInt32(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
+
+func toEmployee(e *models.Employee) *northwindv1.Employee {
+	return &northwindv1.Employee{
+		EmployeeId:      int32(e.EmployeeID),
+		LastName:        e.LastName,
+		FirstName:       e.FirstName,
+		Title:           e.Title,
+		TitleOfCourtesy: e.TitleOfCourtesy,
+		BirthDate:       toTimestamp(e.BirthDate),
+		HireDate:        toTimestamp(e.HireDate),
+		Address:         e.Address,
+		City:            e.City,
+		Region:          e.Region,
+		PostalCode:      e.PostalCode,
+		Country:         e.Country,
+		HomePhone:       e.HomePhone,
+		Extension:       e.Extension,
+		Notes:           e.Notes,
+		ReportsTo:       toInt32(e.ReportsTo),
+		PhotoPath:       e.PhotoPath,
+	}
+}
+
+func fromEmployee(e *northwindv1.Employee) *models.Employee {
+	return &models.Employee{
+		EmployeeID:      int(e.GetEmployeeId()),
+		LastName:        e.GetLastName(),
+		FirstName:       e.GetFirstName(),
+		Title:           e.GetTitle(),
+		TitleOfCourtesy: e.GetTitleOfCourtesy(),
+		BirthDate:       fromTimestamp(e.GetBirthDate()),
+		HireDate:        fromTimestamp(e.GetHireDate()),
+		Address:         e.GetAddress(),
+		City:            e.GetCity(),
+		Region:          e.GetRegion(),
+		PostalCode:      e.GetPostalCode(),
+		Country:         e.GetCountry(),
+		HomePhone:       e.GetHomePhone(),
+		Extension:       e.GetExtension(),
+		Notes:           e.GetNotes(),
+		ReportsTo:       fromInt32(e.ReportsTo),
+		PhotoPath:       e.GetPhotoPath(),
+	}
+}
+
+func toCategory(c *models.Category) *northwindv1.Category {
+	return &northwindv1.Category{
+		CategoryId:   int32(c.CategoryID),
+		CategoryName: c.CategoryName,
+		Description:  c.Description,
+	}
+}
+
+func fromCategory(c *northwindv1.Category) *models.Category {
+	return &models.Category{
+		CategoryID:   int(c.GetCategoryId()),
+		CategoryName: c.GetCategoryName(),
+		Description:  c.GetDescription(),
+	}
+}
+
+func toProduct(p *models.Product) *northwindv1.Product {
+	return &northwindv1.Product{
+		ProductId:       int32(p.ProductID),
+		ProductName:     p.ProductName,
+		SupplierId:      toInt32(p.SupplierID),
+		CategoryId:      toInt32(p.CategoryID),
+		QuantityPerUnit: p.QuantityPerUnit,
+		UnitPrice:       p.UnitPrice,
+		UnitsInStock:    int32(p.UnitsInStock),
+		UnitsOnOrder:    int32(p.UnitsOnOrder),
+		ReorderLevel:    int32(p.ReorderLevel),
+		Discontinued:    p.Discontinued,
+	}
+}
+
+func fromProduct(p *northwindv1.Product) *models.Product {
+	return &models.Product{
+		ProductID:       int(p.GetProductId()),
+		ProductName:     p.GetProductName(),
+		SupplierID:      fromInt32(p.SupplierId),
+		CategoryID:      fromInt32(p.CategoryId),
+		QuantityPerUnit: p.GetQuantityPerUnit(),
+		UnitPrice:       p.GetUnitPrice(),
+		UnitsInStock:    int(p.GetUnitsInStock()),
+		UnitsOnOrder:    int(p.GetUnitsOnOrder()),
+		ReorderLevel:    int(p.GetReorderLevel()),
+		Discontinued:    p.GetDiscontinued(),
+	}
+}
+
+func toOrder(o *models.Order, details []models.OrderDetail) *northwindv1.Order {
+	out := &northwindv1.Order{
+		OrderId:        int32(o.OrderID),
+		CustomerId:     o.CustomerID,
+		EmployeeId:     toInt32(o.EmployeeID),
+		OrderDate:      toTimestamp(o.OrderDate),
+		RequiredDate:   toTimestamp(o.RequiredDate),
+		ShippedDate:    toTimestamp(o.ShippedDate),
+		ShipVia:        toInt32(o.ShipVia),
+		Freight:        o.Freight,
+		ShipName:       o.ShipName,
+		ShipAddress:    o.ShipAddress,
+		ShipCity:       o.ShipCity,
+		ShipRegion:     o.ShipRegion,
+		ShipPostalCode: o.ShipPostalCode,
+		ShipCountry:    o.ShipCountry,
+	}
+	for _, d := range details {
+		out.OrderDetails = append(out.OrderDetails, &northwindv1.OrderDetail{
+			OrderId:   int32(d.OrderID),
+			ProductId: int32(d.ProductID),
+			UnitPrice: d.UnitPrice,
+			Quantity:  int32(d.Quantity),
+			Discount:  d.Discount,
+		})
+	}
+	return out
+}
+
+func fromOrder(o *northwindv1.Order) *models.OrderInput {
+	in := &models.OrderInput{Order: models.Order{
+		OrderID:        int(o.GetOrderId()),
+		CustomerID:     o.CustomerId,
+		EmployeeID:     fromInt32(o.EmployeeId),
+		OrderDate:      fromTimestamp(o.GetOrderDate()),
+		RequiredDate:   fromTimestamp(o.GetRequiredDate()),
+		ShippedDate:    fromTimestamp(o.GetShippedDate()),
+		ShipVia:        fromInt32(o.ShipVia),
+		Freight:        o.GetFreight(),
+		ShipName:       o.GetShipName(),
+		ShipAddress:    o.GetShipAddress(),
+		ShipCity:       o.GetShipCity(),
+		ShipRegion:     o.GetShipRegion(),
+		ShipPostalCode: o.GetShipPostalCode(),
+		ShipCountry:    o.GetShipCountry(),
+	}}
+	for _, d := range o.GetOrderDetails() {
+		in.OrderDetails = append(in.OrderDetails, models.OrderDetail{
+			OrderID:   int(d.GetOrderId()),
+			ProductID: int(d.GetProductId()),
+			UnitPrice: d.GetUnitPrice(),
+			Quantity:  int(d.GetQuantity()),
+			Discount:  d.GetDiscount(),
+		})
+	}
+	return in
+}
@@ -0,0 +1,89 @@
+package grpcserver
+
+import (
+	"context"
+
+	northwindv1 "github.com/azizmasr1001/go-api-northwind/gen/northwind/v1"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type employeeServer struct {
+	northwindv1.UnimplementedEmployeeServiceServer
+	service  services.EmployeeService
+	validate *validator.Validate
+}
+
+func (s *employeeServer) ListEmployees(ctx context.Context, req *northwindv1.ListEmployeesRequest) (*northwindv1.ListEmployeesResponse, error) {
+	page, limit := pagination(req.GetPage(), req.GetLimit())
+	employees, total, err := s.service.GetAllPaginated(ctx, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &northwindv1.ListEmployeesResponse{Total: total}
+	for i := range employees {
+		resp.Employees = append(resp.Employees, toEmployee(&employees[i]))
+	}
+	return resp, nil
+}
+
+func (s *employeeServer) GetEmployee(ctx context.Context, req *northwindv1.GetEmployeeRequest) (*northwindv1.Employee, error) {
+	emp, err := s.service.GetByID(ctx, int(req.GetEmployeeId()))
+	if err != nil {
+		return nil, err
+	}
+	return toEmployee(emp), nil
+}
+
+func (s *employeeServer) CreateEmployee(ctx context.Context, req *northwindv1.CreateEmployeeRequest) (*northwindv1.Employee, error) {
+	if req.GetEmployee() == nil {
+		return nil, missing("employee")
+	}
+	emp := fromEmployee(req.GetEmployee())
+	emp.EmployeeID = 0
+	if err := s.validate.Struct(emp); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.Create(ctx, emp)
+	if err != nil {
+		return nil, err
+	}
+	return toEmployee(&created), nil
+}
+
+func (s *employeeServer) UpdateEmployee(ctx context.Context, req *northwindv1.UpdateEmployeeRequest) (*northwindv1.Employee, error) {
+	if req.GetEmployee() == nil {
+		return nil, missing("employee")
+	}
+	id := int(req.GetEmployeeId())
+	emp := fromEmployee(req.GetEmployee())
+	emp.EmployeeID = id
+	if err := s.validate.Struct(emp); err != nil {
+		return nil, err
+	}
+
+	var updated models.Employee
+	err := s.service.Locked(ctx, id, func(svc services.EmployeeService) error {
+		_, err := svc.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		updated, err = svc.Update(ctx, emp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toEmployee(&updated), nil
+}
+
+func (s *employeeServer) DeleteEmployee(ctx context.Context, req *northwindv1.DeleteEmployeeRequest) (*emptypb.Empty, error) {
+	if err := s.service.Delete(ctx, int(req.GetEmployeeId()), userID(ctx)); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// timeoutInterceptor gives each call a deadline of timeout, a sooner one set by the
+// client still wins. A timeout of 0 disables it.
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// errorInterceptor turns the errors returned by handlers into gRPC statuses
+func errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, toStatus(ctx, info.FullMethod, err)
+	}
+	return resp, nil
+}
+
+var kindCode = map[services.Kind]codes.Code{
+	services.KindInvalid:       codes.InvalidArgument,
+	services.KindUnauthorized:  codes.Unauthenticated,
+	services.KindForbidden:     codes.PermissionDenied,
+	services.KindNotFound:      codes.NotFound,
+	services.KindConflict:      codes.AlreadyExists,
+	services.KindPrecondition:  codes.FailedPrecondition,
+	services.KindTooLarge:      codes.ResourceExhausted,
+	services.KindUnsupported:   codes.InvalidArgument,
+	services.KindUnprocessable: codes.FailedPrecondition,
+}
+
+func toStatus(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	if svcErr := services.AsError(err); svcErr != nil {
+		code, ok := kindCode[svcErr.Kind]
+		if !ok {
+			code = codes.Internal
+		}
+		return status.Error(code, svcErr.Message)
+	}
+
+	var verrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		st := status.New(codes.InvalidArgument, "validation failed")
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(verrs))
+		for _, e := range verrs {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: e.Field(), Description: e.Error()})
+		}
+		if detailed, derr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); derr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	// drivers do not always wrap ctx.Err(), a failure past the deadline is a timeout
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded):
+		slog.WarnContext(ctx, "gRPC call timed out", "method", method, "error", err)
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled), errors.Is(ctx.Err(), context.Canceled):
+		slog.InfoContext(ctx, "gRPC call cancelled by client", "method", method)
+		return status.Error(codes.Canceled, "cancelled by client")
+	}
+
+	slog.ErrorContext(ctx, "Unhandled gRPC error", "method", method, "error", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func missing(field string) error {
+	return status.Errorf(codes.InvalidArgument, "%s is required", field)
+}
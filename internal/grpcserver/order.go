@@ -0,0 +1,93 @@
+package grpcserver
+
+import (
+	"context"
+
+	northwindv1 "github.com/azizmasr1001/go-api-northwind/gen/northwind/v1"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type orderServer struct {
+	northwindv1.UnimplementedOrderServiceServer
+	service  services.OrderService
+	validate *validator.Validate
+}
+
+func (s *orderServer) ListOrders(ctx context.Context, req *northwindv1.ListOrdersRequest) (*northwindv1.ListOrdersResponse, error) {
+	page, limit := pagination(req.GetPage(), req.GetLimit())
+	orders, total, err := s.service.GetAll(ctx, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &northwindv1.ListOrdersResponse{Total: total}
+	for i := range orders {
+		resp.Orders = append(resp.Orders, toOrder(&orders[i], nil))
+	}
+	return resp, nil
+}
+
+func (s *orderServer) GetOrder(ctx context.Context, req *northwindv1.GetOrderRequest) (*northwindv1.Order, error) {
+	order, details, err := s.service.GetByID(ctx, int(req.GetOrderId()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return toOrder(order, details), nil
+}
+
+func (s *orderServer) CreateOrder(ctx context.Context, req *northwindv1.CreateOrderRequest) (*northwindv1.Order, error) {
+	if req.GetOrder() == nil {
+		return nil, missing("order")
+	}
+	in := fromOrder(req.GetOrder())
+	in.OrderID = 0
+	if err := s.validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	order, err := s.service.Create(ctx, &in.Order, in.OrderDetails)
+	if err != nil {
+		return nil, err
+	}
+	return toOrder(order, withOrderID(in.OrderDetails, order.OrderID)), nil
+}
+
+func (s *orderServer) UpdateOrder(ctx context.Context, req *northwindv1.UpdateOrderRequest) (*northwindv1.Order, error) {
+	if req.GetOrder() == nil {
+		return nil, missing("order")
+	}
+	id := int(req.GetOrderId())
+	if _, _, err := s.service.GetByID(ctx, id, nil, nil); err != nil {
+		return nil, err
+	}
+
+	in := fromOrder(req.GetOrder())
+	in.OrderID = 0
+	if err := s.validate.Struct(in); err != nil {
+		return nil, err
+	}
+
+	order, err := s.service.Update(ctx, id, &in.Order, in.OrderDetails)
+	if err != nil {
+		return nil, err
+	}
+	return toOrder(order, withOrderID(in.OrderDetails, id)), nil
+}
+
+func (s *orderServer) DeleteOrder(ctx context.Context, req *northwindv1.DeleteOrderRequest) (*emptypb.Empty, error) {
+	if err := s.service.Delete(ctx, int(req.GetOrderId()), userID(ctx)); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// withOrderID stamps the parent id on details the repository wrote by value
+func withOrderID(details []models.OrderDetail, id int) []models.OrderDetail {
+	for i := range details {
+		details[i].OrderID = id
+	}
+	return details
+}
@@ -0,0 +1,98 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	northwindv1 "github.com/azizmasr1001/go-api-northwind/gen/northwind/v1"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type productServer struct {
+	northwindv1.UnimplementedProductServiceServer
+	service  services.ProductService
+	validate *validator.Validate
+}
+
+func (s *productServer) ListProducts(ctx context.Context, req *northwindv1.ListProductsRequest) (*northwindv1.ListProductsResponse, error) {
+	page, limit := pagination(req.GetPage(), req.GetLimit())
+
+	var products []models.Product
+	var total int64
+	var err error
+	if name := strings.TrimSpace(req.GetName()); name != "" {
+		products, total, err = s.service.SearchByName(ctx, name, page, limit)
+	} else {
+		products, total, err = s.service.GetAllPaginated(ctx, page, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &northwindv1.ListProductsResponse{Total: total}
+	for i := range products {
+		resp.Products = append(resp.Products, toProduct(&products[i]))
+	}
+	return resp, nil
+}
+
+func (s *productServer) GetProduct(ctx context.Context, req *northwindv1.GetProductRequest) (*northwindv1.Product, error) {
+	prod, err := s.service.GetByID(ctx, int(req.GetProductId()))
+	if err != nil {
+		return nil, err
+	}
+	return toProduct(prod), nil
+}
+
+func (s *productServer) CreateProduct(ctx context.Context, req *northwindv1.CreateProductRequest) (*northwindv1.Product, error) {
+	if req.GetProduct() == nil {
+		return nil, missing("product")
+	}
+	prod := fromProduct(req.GetProduct())
+	prod.ProductID = 0
+	if err := s.validate.Struct(prod); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.Create(ctx, prod)
+	if err != nil {
+		return nil, err
+	}
+	return toProduct(created), nil
+}
+
+func (s *productServer) UpdateProduct(ctx context.Context, req *northwindv1.UpdateProductRequest) (*northwindv1.Product, error) {
+	if req.GetProduct() == nil {
+		return nil, missing("product")
+	}
+	id := int(req.GetProductId())
+	prod := fromProduct(req.GetProduct())
+	prod.ProductID = id
+	if err := s.validate.Struct(prod); err != nil {
+		return nil, err
+	}
+
+	var updated *models.Product
+	err := s.service.Locked(ctx, id, func(svc services.ProductService) error {
+		_, err := svc.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		updated, err = svc.Update(ctx, prod)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProduct(updated), nil
+}
+
+func (s *productServer) DeleteProduct(ctx context.Context, req *northwindv1.DeleteProductRequest) (*emptypb.Empty, error) {
+	if err := s.service.Delete(ctx, int(req.GetProductId()), userID(ctx)); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,45 @@
+// Package grpcserver serves the employee, category, product and order
+// services over gRPC, next to the Fiber REST API.
+package grpcserver
+
+import (
+	"time"
+
+	northwindv1 "github.com/azizmasr1001/go-api-northwind/gen/northwind/v1"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer registers every northwind.v1 service plus server reflection.
+// Unary calls must carry a valid "authorization: Bearer <jwt>" metadata entry and
+// get a deadline of timeout.
+func NewServer(jwtSecret string, timeout time.Duration, revoked RevocationCheck, svc services.Services) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		// errorInterceptor sees the deadline to tell a timeout from a driver error
+		timeoutInterceptor(timeout),
+		errorInterceptor,
+		authInterceptor(jwtSecret, revoked),
+	))
+
+	validate := validator.New()
+	northwindv1.RegisterEmployeeServiceServer(srv, &employeeServer{service: svc.Employees, validate: validate})
+	northwindv1.RegisterCategoryServiceServer(srv, &categoryServer{service: svc.Categories, validate: validate})
+	northwindv1.RegisterProductServiceServer(srv, &productServer{service: svc.Products, validate: validate})
+	northwindv1.RegisterOrderServiceServer(srv, &orderServer{service: svc.Orders, validate: validate})
+	reflection.Register(srv)
+
+	return srv
+}
+
+// pagination applies the REST defaults (page 1, limit 10)
+func pagination(page, limit int32) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	return int(page), int(limit)
+}
@@ -0,0 +1,102 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	northwindv1 "github.com/azizmasr1001/go-api-northwind/gen/northwind/v1"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+const (
+	testSecret  = "test-secret"
+	testTimeout = 200 * time.Millisecond
+)
+
+type stubCategories struct {
+	services.CategoryService
+}
+
+func (s *stubCategories) GetByID(ctx context.Context, id int, columns ...string) (*models.Category, error) {
+	if id == 3 {
+		// a slow query, cancelled by the server's deadline with a driver error of its own
+		<-ctx.Done()
+		return nil, errors.New("mssql: did not get cancellation confirmation from the server")
+	}
+	if id != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Category{CategoryID: 1, CategoryName: "Beverages"}, nil
+}
+
+func dial(t *testing.T, revoked bool) northwindv1.CategoryServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := NewServer(testSecret, testTimeout, func(ctx context.Context, token string) (bool, error) {
+		return revoked, nil
+	}, services.Services{Categories: &stubCategories{}})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return northwindv1.NewCategoryServiceClient(conn)
+}
+
+func withToken(t *testing.T) context.Context {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestGetCategory_RequiresToken(t *testing.T) {
+	client := dial(t, false)
+
+	_, err := client.GetCategory(context.Background(), &northwindv1.GetCategoryRequest{CategoryId: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetCategory_RevokedToken(t *testing.T) {
+	client := dial(t, true)
+
+	_, err := client.GetCategory(withToken(t), &northwindv1.GetCategoryRequest{CategoryId: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetCategory_MapsErrors(t *testing.T) {
+	client := dial(t, false)
+
+	cat, err := client.GetCategory(withToken(t), &northwindv1.GetCategoryRequest{CategoryId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Beverages", cat.GetCategoryName())
+
+	_, err = client.GetCategory(withToken(t), &northwindv1.GetCategoryRequest{CategoryId: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateCategory(withToken(t), &northwindv1.CreateCategoryRequest{Category: &northwindv1.Category{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetCategory_ServerDeadline(t *testing.T) {
+	client := dial(t, false)
+
+	_, err := client.GetCategory(withToken(t), &northwindv1.GetCategoryRequest{CategoryId: 3})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, "deadline exceeded", status.Convert(err).Message(), "the driver message stays in the logs")
+}
@@ -0,0 +1,71 @@
+// Package health pings the dependencies the API cannot serve without
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusDown = "down"
+)
+
+// Check pings one dependency
+type Check struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+// Result is the outcome of one check. Error says only whether the ping timed out,
+// the cause may hold connection details and is kept for the server log.
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+	Cause     error   `json:"-"`
+}
+
+// Report is ok only when every check is
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Run pings every dependency at once, a ping still going after timeout counts as down
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) Report {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Ping(ctx)
+			res := Result{Status: StatusOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				res.Status = StatusDown
+				res.Error = "unavailable"
+				if errors.Is(err, context.DeadlineExceeded) {
+					res.Error = "timeout"
+				}
+				res.Cause = err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = res
+			if err != nil {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Gorm sends GORM's logs to the default slog logger. Every statement is logged at
+// debug, statements slower than SlowThreshold at warn and failed ones at error.
+// A query run with db.WithContext(ctx) is tagged with the request ID of ctx.
+type Gorm struct {
+	SlowThreshold time.Duration
+	silent        bool
+}
+
+// NewGorm logs statements slower than slow as warnings
+func NewGorm(slow time.Duration) Gorm {
+	return Gorm{SlowThreshold: slow}
+}
+
+// LogMode only honours Silent, the slog level decides the rest
+func (g Gorm) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	g.silent = level == gormlogger.Silent
+	return g
+}
+
+func (g Gorm) Info(ctx context.Context, msg string, data ...interface{}) {
+	g.log(ctx, slog.LevelInfo, msg, data)
+}
+
+func (g Gorm) Warn(ctx context.Context, msg string, data ...interface{}) {
+	g.log(ctx, slog.LevelWarn, msg, data)
+}
+
+func (g Gorm) Error(ctx context.Context, msg string, data ...interface{}) {
+	g.log(ctx, slog.LevelError, msg, data)
+}
+
+func (g Gorm) log(ctx context.Context, level slog.Level, msg string, data []interface{}) {
+	if !g.silent {
+		slog.Log(ctx, level, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (g Gorm) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	level := slog.LevelDebug
+	msg := "sql"
+	var constraint *repositories.ConstraintError
+	switch {
+	case err != nil && errors.As(err, &constraint):
+		// a broken constraint is the client's doing and is answered with a 409 or 422
+		level, msg = slog.LevelWarn, "sql constraint violated"
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, msg = slog.LevelError, "sql failed"
+	case g.SlowThreshold > 0 && elapsed > g.SlowThreshold:
+		level, msg = slog.LevelWarn, "sql slow"
+	}
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", milliseconds(elapsed)),
+	}
+	if level > slog.LevelDebug && err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func useLogger(t *testing.T, level string) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, err := New(&buf, level)
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = New(&bytes.Buffer{}, "loud")
+	assert.ErrorContains(t, err, `invalid LOG_LEVEL "loud"`)
+}
+
+func TestGorm_Trace(t *testing.T) {
+	buf := useLogger(t, "debug")
+	ctx := WithRequestID(context.Background(), "req-1")
+	g := NewGorm(100 * time.Millisecond)
+	query := func() (string, int64) { return `SELECT * FROM "Orders"`, 3 }
+
+	g.Trace(ctx, time.Now(), query, nil)
+	g.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+	g.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	g.Trace(ctx, time.Now(), query, &repositories.ConstraintError{Violation: repositories.ViolationUnique, Err: errors.New("dup")})
+	g.Trace(context.Background(), time.Now(), query, errors.New("connection reset"))
+
+	got := entries(t, buf)
+	require.Len(t, got, 5)
+	want := []struct{ level, msg string }{
+		{"DEBUG", "sql"},
+		{"WARN", "sql slow"},
+		{"DEBUG", "sql"},
+		{"WARN", "sql constraint violated"},
+		{"ERROR", "sql failed"},
+	}
+	for i, w := range want {
+		assert.Equal(t, w.level, got[i]["level"], i)
+		assert.Equal(t, w.msg, got[i]["msg"], i)
+		assert.Equal(t, `SELECT * FROM "Orders"`, got[i]["sql"], i)
+	}
+	assert.Equal(t, "req-1", got[0]["request_id"])
+	assert.NotContains(t, got[4], "request_id")
+	assert.Equal(t, "connection reset", got[4]["error"])
+}
+
+func TestGorm_LevelAndSilent(t *testing.T) {
+	buf := useLogger(t, "info")
+	called := false
+	query := func() (string, int64) { called = true; return "SELECT 1", 1 }
+
+	NewGorm(time.Second).Trace(context.Background(), time.Now(), query, nil)
+	assert.False(t, called, "the SQL is not even built when debug is off")
+
+	silent := NewGorm(time.Second).LogMode(gormlogger.Silent)
+	silent.Trace(context.Background(), time.Now(), query, errors.New("boom"))
+	silent.Error(context.Background(), "boom %d", 1)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,126 @@
+// Package metrics holds the Prometheus collectors of the API. The collectors are
+// shared; each app gets a registry of its own with them plus its DB pool stats.
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "northwind"
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route template and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "GORM statement latency by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation", "table"})
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "GORM statements that failed, record not found excluded.",
+	}, []string{"operation", "table"})
+
+	RedisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_command_duration_seconds",
+		Help:    "Redis command latency by command, pipelines count as one.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command"})
+	RedisErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_command_errors_total",
+		Help: "Redis commands that failed, cache misses excluded.",
+	}, []string{"command"})
+
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by result, success or failure.",
+	}, []string{"result"})
+	OrdersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders committed.",
+	})
+	OTPsSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otps_sent_total",
+		Help:      "Password reset OTPs issued.",
+	})
+	TokensRevoked = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_revoked_total",
+		Help:      "Access tokens blacklisted by a logout.",
+	})
+)
+
+const (
+	LoginSucceeded = "success"
+	LoginFailed    = "failure"
+)
+
+// shared are the collectors every registry gets
+var shared = []prometheus.Collector{
+	HTTPRequests, HTTPDuration,
+	DBQueryDuration, DBQueryErrors,
+	RedisDuration, RedisErrors,
+	Logins, OrdersCreated, OTPsSent, TokensRevoked,
+}
+
+// NewRegistry returns a registry with the shared collectors, the Go runtime and
+// process collectors and the connection pool stats of db
+func NewRegistry(db *sql.DB) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(shared...)
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, namespace),
+	)
+	return reg
+}
+
+// Handler serves reg in the Prometheus text format. With a user set it asks for
+// HTTP basic auth with user and password.
+func Handler(reg *prometheus.Registry, user, password string) http.Handler {
+	handler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	if user == "" {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(u), []byte(user)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Events counts committed domain events. It is an event publisher, so inside a
+// batch transaction it only sees what was committed.
+type Events struct{}
+
+func (Events) Publish(_ context.Context, event models.Event) error {
+	if event.Type == models.EventOrderCreated {
+		OrdersCreated.Inc()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const usage = "usage: migrate [up | down [steps] | status]"
+
+// Run is the migrate subcommand: up (the default) applies every pending migration,
+// down reverts the last steps (1 unless given), status lists what is applied.
+func Run(m *Migrator, args []string, out io.Writer) error {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "up":
+		ran, err := m.Up()
+		for _, mig := range ran {
+			fmt.Fprintf(out, "applied  %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(ran) == 0 {
+			fmt.Fprintln(out, "database is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("steps must be a positive number, got %q", args[0])
+			}
+			steps = n
+		}
+		reverted, err := m.Down(steps)
+		for _, mig := range reverted {
+			fmt.Fprintf(out, "reverted %04d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d_%-32s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown migrate command %q, %s", cmd, usage)
+}
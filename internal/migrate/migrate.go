@@ -0,0 +1,266 @@
+// Package migrate applies the versioned schema migrations embedded in the binary.
+// Every dialect has its own directory of NNNN_name.up.sql / NNNN_name.down.sql pairs
+// and applied versions are recorded in the schema_migrations table.
+package migrate
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+var (
+	ErrPending = errors.New("database has pending migrations, run `migrate up`")
+	ErrUnknown = errors.New("database has migrations this binary does not know, it is older than the schema")
+)
+
+// Migration is one version, Up and Down are the statements in the order they run
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// applied is a row of schema_migrations
+type applied struct {
+	Version   int       `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;size:255;not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+func (applied) TableName() string { return "schema_migrations" }
+
+// Status is a migration and when it was applied, AppliedAt is nil while it is pending
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New loads the migrations for the dialect of db
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(migrationsFS, path.Join("migrations", db.Dialector.Name()), db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+var reFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in dir, each version needs both its up and down file
+func Load(fsys fs.FS, dir, dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %q: %w", dialect, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		m := reFile.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("unexpected file %s in %s", entry.Name(), dir)
+		}
+		version, _ := strconv.Atoi(m[1])
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, mig.Name, m[2])
+		}
+
+		raw, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if m[3] == "up" {
+			mig.Up = split(dialect, string(raw))
+		} else {
+			mig.Down = split(dialect, string(raw))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == nil || mig.Down == nil {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+var (
+	reGo        = regexp.MustCompile(`(?im)^\s*GO\s*$`)
+	reSemicolon = regexp.MustCompile(`;\s*(\n|$)`)
+)
+
+// split cuts a file into statements: SQL Server batches end with a GO line,
+// the other dialects end statements with a semicolon at the end of a line.
+// Chunks holding nothing but comments are dropped, so a file may be empty.
+func split(dialect, sql string) []string {
+	var chunks []string
+	if dialect == "sqlserver" {
+		chunks = reGo.Split(sql, -1)
+	} else {
+		chunks = reSemicolon.Split(sql, -1)
+	}
+
+	statements := []string{}
+	for _, chunk := range chunks {
+		chunk = strings.TrimSpace(chunk)
+		if chunk != "" && !onlyComments(chunk) {
+			statements = append(statements, chunk)
+		}
+	}
+	return statements
+}
+
+func onlyComments(chunk string) bool {
+	for _, line := range strings.Split(chunk, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Migrator) Migrations() []Migration { return m.migrations }
+
+// Status lists every migration with the time it was applied
+func (m *Migrator) Status() ([]Status, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		statuses[i] = Status{Migration: mig}
+		if row, ok := done[mig.Version]; ok {
+			at := row.AppliedAt
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Check fails with ErrPending or ErrUnknown unless the database is at the latest version
+func (m *Migrator) Check() error {
+	done, err := m.applied()
+	if err != nil {
+		return err
+	}
+	known := map[int]bool{}
+	var pending []string
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if _, ok := done[mig.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", mig.Version, mig.Name))
+		}
+	}
+	for version, row := range done {
+		if !known[version] {
+			return fmt.Errorf("%w: %04d_%s", ErrUnknown, version, row.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrPending, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up applies every pending migration in version order and returns the ones it ran.
+// Each one runs in a transaction, except on MySQL where DDL commits on its own.
+func (m *Migrator) Up() ([]Migration, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	var ran []Migration
+	for _, mig := range m.migrations {
+		if _, ok := done[mig.Version]; ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := exec(tx, mig.Up); err != nil {
+				return err
+			}
+			return tx.Create(&applied{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		ran = append(ran, mig)
+	}
+	return ran, nil
+}
+
+// Down reverts the last steps applied migrations, newest first
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	var reverted []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := done[mig.Version]; !ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := exec(tx, mig.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&applied{}, mig.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("reverting %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		reverted = append(reverted, mig)
+	}
+	return reverted, nil
+}
+
+func exec(tx *gorm.DB, statements []string) error {
+	for _, stmt := range statements {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applied creates schema_migrations on first use and reads it by version
+func (m *Migrator) applied() (map[int]applied, error) {
+	if err := m.db.AutoMigrate(&applied{}); err != nil {
+		return nil, err
+	}
+	var rows []applied
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int]applied, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
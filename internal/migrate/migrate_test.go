@@ -0,0 +1,79 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestSplit(t *testing.T) {
+	sqlserver := "-- header\nIF 1 = 1\nBEGIN\nSELECT 1;\nSELECT 2;\nEND\ngo\n\nSELECT 3\nGO\n"
+	assert.Equal(t, []string{"-- header\nIF 1 = 1\nBEGIN\nSELECT 1;\nSELECT 2;\nEND", "SELECT 3"}, split("sqlserver", sqlserver))
+
+	assert.Equal(t, []string{"SELECT 'a;b'", "SELECT 2"}, split("sqlite", "SELECT 'a;b';\n\nSELECT 2;\n"))
+	assert.Empty(t, split("postgres", "-- nothing to do\n"))
+}
+
+// Every driver has to ship the same versions, or the schemas drift apart
+func TestLoad_SameVersionsForEveryDriver(t *testing.T) {
+	var want []string
+	for _, driver := range []string{"sqlserver", "postgres", "mysql", "sqlite"} {
+		migrations, err := Load(migrationsFS, "migrations/"+driver, driver)
+		require.NoError(t, err, driver)
+
+		var got []string
+		for _, mig := range migrations {
+			assert.NotEmpty(t, mig.Up, "%s %04d_%s", driver, mig.Version, mig.Name)
+			got = append(got, mig.Name)
+		}
+		if want == nil {
+			want = got
+		}
+		assert.Equal(t, want, got, driver)
+	}
+}
+
+func TestMigrator_SQLite(t *testing.T) {
+	db, err := database.Open(database.DriverSQLite, "file::memory:")
+	require.NoError(t, err)
+	db.Logger = logger.Discard
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // every connection to :memory: is a database of its own
+	m, err := New(db)
+	require.NoError(t, err)
+
+	assert.True(t, errors.Is(m.Check(), ErrPending))
+
+	var out bytes.Buffer
+	require.NoError(t, Run(m, nil, &out))
+	assert.Contains(t, out.String(), "applied  0001_northwind")
+	require.NoError(t, m.Check())
+	for _, table := range []string{"Order Details", "Users", "password_resets", "webhook_deliveries"} {
+		assert.True(t, db.Migrator().HasTable(table), table)
+	}
+	assert.True(t, db.Migrator().HasColumn("Orders", "DeletedAt"))
+
+	out.Reset()
+	require.NoError(t, Run(m, []string{"down", "2"}, &out))
+	assert.Equal(t, "reverted 0005_webhooks\nreverted 0004_soft_delete\n", out.String())
+	assert.False(t, db.Migrator().HasColumn("Orders", "DeletedAt"))
+	assert.True(t, errors.Is(m.Check(), ErrPending))
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[2].AppliedAt)
+	assert.Nil(t, statuses[3].AppliedAt)
+
+	_, err = m.Up()
+	require.NoError(t, err)
+	require.NoError(t, m.Check())
+
+	m.migrations = m.migrations[:len(m.migrations)-1]
+	assert.True(t, errors.Is(m.Check(), ErrUnknown))
+}
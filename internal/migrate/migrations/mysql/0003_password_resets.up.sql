@@ -0,0 +1,12 @@
+CREATE TABLE "password_resets" (
+    "id"         INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    "email"      VARCHAR(100) NOT NULL,
+    "token"      VARCHAR(255) NOT NULL,
+    "expires_at" DATETIME NOT NULL,
+    "used"       BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_at" DATETIME NULL
+);
+
+CREATE INDEX "idx_password_resets_email" ON "password_resets" ("email");
+
+CREATE UNIQUE INDEX "idx_password_resets_token" ON "password_resets" ("token");
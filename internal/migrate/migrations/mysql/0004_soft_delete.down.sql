@@ -0,0 +1,15 @@
+DROP INDEX "IX_Categories_DeletedAt" ON "Categories";
+
+ALTER TABLE "Categories" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
+
+DROP INDEX "IX_Employees_DeletedAt" ON "Employees";
+
+ALTER TABLE "Employees" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
+
+DROP INDEX "IX_Products_DeletedAt" ON "Products";
+
+ALTER TABLE "Products" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
+
+DROP INDEX "IX_Orders_DeletedAt" ON "Orders";
+
+ALTER TABLE "Orders" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
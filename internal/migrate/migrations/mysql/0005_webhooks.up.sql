@@ -0,0 +1,32 @@
+-- Events is a JSON array such as ["order.created"], Status is pending, delivered or dead
+CREATE TABLE "webhook_subscriptions" (
+    "ID"        INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    "UserID"    INT NOT NULL,
+    "URL"       VARCHAR(2048) NOT NULL,
+    "Events"    VARCHAR(1000) NOT NULL,
+    "Secret"    VARCHAR(100) NOT NULL,
+    "Active"    BOOLEAN NOT NULL DEFAULT TRUE,
+    "CreatedAt" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "FK_WebhookSubscriptions_Users" FOREIGN KEY ("UserID") REFERENCES "Users" ("UserID")
+);
+
+CREATE TABLE "webhook_deliveries" (
+    "ID"             INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    "SubscriptionID" INT NOT NULL,
+    "EventID"        VARCHAR(64) NOT NULL,
+    "EventType"      VARCHAR(100) NOT NULL,
+    "Payload"        TEXT NOT NULL,
+    "Status"         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    "Attempts"       INT NOT NULL DEFAULT 0,
+    "NextAttemptAt"  DATETIME NOT NULL,
+    "LockedUntil"    DATETIME NULL,
+    "ResponseStatus" INT NULL,
+    "LastError"      VARCHAR(1000) NULL,
+    "CreatedAt"      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "DeliveredAt"    DATETIME NULL,
+    CONSTRAINT "FK_WebhookDeliveries_Subscriptions" FOREIGN KEY ("SubscriptionID") REFERENCES "webhook_subscriptions" ("ID") ON DELETE CASCADE
+);
+
+CREATE INDEX "IX_WebhookDeliveries_Due" ON "webhook_deliveries" ("Status", "NextAttemptAt");
+
+CREATE INDEX "IX_WebhookDeliveries_Subscription" ON "webhook_deliveries" ("SubscriptionID", "CreatedAt");
@@ -0,0 +1,15 @@
+DROP INDEX "IX_Categories_DeletedAt";
+
+ALTER TABLE "Categories" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
+
+DROP INDEX "IX_Employees_DeletedAt";
+
+ALTER TABLE "Employees" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
+
+DROP INDEX "IX_Products_DeletedAt";
+
+ALTER TABLE "Products" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
+
+DROP INDEX "IX_Orders_DeletedAt";
+
+ALTER TABLE "Orders" DROP COLUMN "DeletedAt", DROP COLUMN "DeletedBy";
@@ -0,0 +1,32 @@
+-- Events is a JSON array such as ["order.created"], Status is pending, delivered or dead
+CREATE TABLE "webhook_subscriptions" (
+    "ID"        INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "UserID"    INTEGER NOT NULL,
+    "URL"       VARCHAR(2048) NOT NULL,
+    "Events"    VARCHAR(1000) NOT NULL,
+    "Secret"    VARCHAR(100) NOT NULL,
+    "Active"    BOOLEAN NOT NULL DEFAULT TRUE,
+    "CreatedAt" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "FK_WebhookSubscriptions_Users" FOREIGN KEY ("UserID") REFERENCES "Users" ("UserID")
+);
+
+CREATE TABLE "webhook_deliveries" (
+    "ID"             INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "SubscriptionID" INTEGER NOT NULL,
+    "EventID"        VARCHAR(64) NOT NULL,
+    "EventType"      VARCHAR(100) NOT NULL,
+    "Payload"        TEXT NOT NULL,
+    "Status"         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    "Attempts"       INTEGER NOT NULL DEFAULT 0,
+    "NextAttemptAt"  TIMESTAMP NOT NULL,
+    "LockedUntil"    TIMESTAMP NULL,
+    "ResponseStatus" INTEGER NULL,
+    "LastError"      VARCHAR(1000) NULL,
+    "CreatedAt"      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "DeliveredAt"    TIMESTAMP NULL,
+    CONSTRAINT "FK_WebhookDeliveries_Subscriptions" FOREIGN KEY ("SubscriptionID") REFERENCES "webhook_subscriptions" ("ID") ON DELETE CASCADE
+);
+
+CREATE INDEX "IX_WebhookDeliveries_Due" ON "webhook_deliveries" ("Status", "NextAttemptAt");
+
+CREATE INDEX "IX_WebhookDeliveries_Subscription" ON "webhook_deliveries" ("SubscriptionID", "CreatedAt");
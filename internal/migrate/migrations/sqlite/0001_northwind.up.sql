@@ -0,0 +1,110 @@
+-- The Northwind tables the API reads. Databases that already hold Northwind keep
+-- their tables, only missing ones are created.
+
+CREATE TABLE IF NOT EXISTS "Categories" (
+    "CategoryID"   INTEGER PRIMARY KEY AUTOINCREMENT,
+    "CategoryName" VARCHAR(15) NOT NULL,
+    "Description"  TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "Suppliers" (
+    "SupplierID"   INTEGER PRIMARY KEY AUTOINCREMENT,
+    "CompanyName"  VARCHAR(40) NOT NULL,
+    "ContactName"  VARCHAR(30) NULL,
+    "ContactTitle" VARCHAR(30) NULL,
+    "Address"      VARCHAR(60) NULL,
+    "City"         VARCHAR(15) NULL,
+    "Region"       VARCHAR(15) NULL,
+    "PostalCode"   VARCHAR(10) NULL,
+    "Country"      VARCHAR(15) NULL,
+    "Phone"        VARCHAR(24) NULL,
+    "Fax"          VARCHAR(24) NULL,
+    "HomePage"     TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "Shippers" (
+    "ShipperID"   INTEGER PRIMARY KEY AUTOINCREMENT,
+    "CompanyName" VARCHAR(40) NOT NULL,
+    "Phone"       VARCHAR(24) NULL
+);
+
+CREATE TABLE IF NOT EXISTS "Customers" (
+    "CustomerID"   CHAR(5) NOT NULL PRIMARY KEY,
+    "CompanyName"  VARCHAR(40) NOT NULL,
+    "ContactName"  VARCHAR(30) NULL,
+    "ContactTitle" VARCHAR(30) NULL,
+    "Address"      VARCHAR(60) NULL,
+    "City"         VARCHAR(15) NULL,
+    "Region"       VARCHAR(15) NULL,
+    "PostalCode"   VARCHAR(10) NULL,
+    "Country"      VARCHAR(15) NULL,
+    "Phone"        VARCHAR(24) NULL,
+    "Fax"          VARCHAR(24) NULL
+);
+
+CREATE TABLE IF NOT EXISTS "Employees" (
+    "EmployeeID"      INTEGER PRIMARY KEY AUTOINCREMENT,
+    "LastName"        VARCHAR(20) NOT NULL,
+    "FirstName"       VARCHAR(10) NOT NULL,
+    "Title"           VARCHAR(30) NULL,
+    "TitleOfCourtesy" VARCHAR(25) NULL,
+    "BirthDate"       DATETIME NULL,
+    "HireDate"        DATETIME NULL,
+    "Address"         VARCHAR(60) NULL,
+    "City"            VARCHAR(15) NULL,
+    "Region"          VARCHAR(15) NULL,
+    "PostalCode"      VARCHAR(10) NULL,
+    "Country"         VARCHAR(15) NULL,
+    "HomePhone"       VARCHAR(24) NULL,
+    "Extension"       VARCHAR(4) NULL,
+    "Notes"           TEXT NULL,
+    "ReportsTo"       INTEGER NULL,
+    "PhotoPath"       VARCHAR(255) NULL,
+    CONSTRAINT "FK_Employees_Employees" FOREIGN KEY ("ReportsTo") REFERENCES "Employees" ("EmployeeID")
+);
+
+CREATE TABLE IF NOT EXISTS "Products" (
+    "ProductID"       INTEGER PRIMARY KEY AUTOINCREMENT,
+    "ProductName"     VARCHAR(40) NOT NULL,
+    "SupplierID"      INTEGER NULL,
+    "CategoryID"      INTEGER NULL,
+    "QuantityPerUnit" VARCHAR(20) NULL,
+    "UnitPrice"       NUMERIC NULL DEFAULT 0,
+    "UnitsInStock"    SMALLINT NULL DEFAULT 0,
+    "UnitsOnOrder"    SMALLINT NULL DEFAULT 0,
+    "ReorderLevel"    SMALLINT NULL DEFAULT 0,
+    "Discontinued"    BOOLEAN NOT NULL DEFAULT 0,
+    CONSTRAINT "FK_Products_Suppliers" FOREIGN KEY ("SupplierID") REFERENCES "Suppliers" ("SupplierID"),
+    CONSTRAINT "FK_Products_Categories" FOREIGN KEY ("CategoryID") REFERENCES "Categories" ("CategoryID")
+);
+
+CREATE TABLE IF NOT EXISTS "Orders" (
+    "OrderID"        INTEGER PRIMARY KEY AUTOINCREMENT,
+    "CustomerID"     CHAR(5) NULL,
+    "EmployeeID"     INTEGER NULL,
+    "OrderDate"      DATETIME NULL,
+    "RequiredDate"   DATETIME NULL,
+    "ShippedDate"    DATETIME NULL,
+    "ShipVia"        INTEGER NULL,
+    "Freight"        NUMERIC NULL DEFAULT 0,
+    "ShipName"       VARCHAR(40) NULL,
+    "ShipAddress"    VARCHAR(60) NULL,
+    "ShipCity"       VARCHAR(15) NULL,
+    "ShipRegion"     VARCHAR(15) NULL,
+    "ShipPostalCode" VARCHAR(10) NULL,
+    "ShipCountry"    VARCHAR(15) NULL,
+    CONSTRAINT "FK_Orders_Customers" FOREIGN KEY ("CustomerID") REFERENCES "Customers" ("CustomerID"),
+    CONSTRAINT "FK_Orders_Employees" FOREIGN KEY ("EmployeeID") REFERENCES "Employees" ("EmployeeID"),
+    CONSTRAINT "FK_Orders_Shippers" FOREIGN KEY ("ShipVia") REFERENCES "Shippers" ("ShipperID")
+);
+
+CREATE TABLE IF NOT EXISTS "Order Details" (
+    "OrderID"   INTEGER NOT NULL,
+    "ProductID" INTEGER NOT NULL,
+    "UnitPrice" NUMERIC NOT NULL DEFAULT 0,
+    "Quantity"  SMALLINT NOT NULL DEFAULT 1,
+    "Discount"  REAL NOT NULL DEFAULT 0,
+    CONSTRAINT "PK_Order_Details" PRIMARY KEY ("OrderID", "ProductID"),
+    CONSTRAINT "FK_Order_Details_Orders" FOREIGN KEY ("OrderID") REFERENCES "Orders" ("OrderID"),
+    CONSTRAINT "FK_Order_Details_Products" FOREIGN KEY ("ProductID") REFERENCES "Products" ("ProductID")
+);
@@ -0,0 +1,17 @@
+-- Role is admin or user, only admins may purge the trash
+CREATE TABLE "Users" (
+    "UserID"       INTEGER PRIMARY KEY AUTOINCREMENT,
+    "Username"     VARCHAR(50) NOT NULL,
+    "Email"        VARCHAR(100) NOT NULL,
+    "PasswordHash" VARCHAR(255) NOT NULL,
+    "Role"         VARCHAR(50) NULL,
+    "IsActive"     BOOLEAN NOT NULL DEFAULT 1,
+    "EmployeeID"   INTEGER NULL,
+    "CreatedAt"    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "UpdatedAt"    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "FK_Users_Employees" FOREIGN KEY ("EmployeeID") REFERENCES "Employees" ("EmployeeID") ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX "IX_Users_Username" ON "Users" ("Username");
+
+CREATE UNIQUE INDEX "IX_Users_Email" ON "Users" ("Email");
@@ -0,0 +1,24 @@
+-- Soft delete: Delete only stamps these columns, purge removes the row for good
+ALTER TABLE "Categories" ADD COLUMN "DeletedAt" DATETIME NULL;
+
+ALTER TABLE "Categories" ADD COLUMN "DeletedBy" INTEGER NULL;
+
+CREATE INDEX "IX_Categories_DeletedAt" ON "Categories" ("DeletedAt");
+
+ALTER TABLE "Employees" ADD COLUMN "DeletedAt" DATETIME NULL;
+
+ALTER TABLE "Employees" ADD COLUMN "DeletedBy" INTEGER NULL;
+
+CREATE INDEX "IX_Employees_DeletedAt" ON "Employees" ("DeletedAt");
+
+ALTER TABLE "Products" ADD COLUMN "DeletedAt" DATETIME NULL;
+
+ALTER TABLE "Products" ADD COLUMN "DeletedBy" INTEGER NULL;
+
+CREATE INDEX "IX_Products_DeletedAt" ON "Products" ("DeletedAt");
+
+ALTER TABLE "Orders" ADD COLUMN "DeletedAt" DATETIME NULL;
+
+ALTER TABLE "Orders" ADD COLUMN "DeletedBy" INTEGER NULL;
+
+CREATE INDEX "IX_Orders_DeletedAt" ON "Orders" ("DeletedAt");
@@ -0,0 +1 @@
+-- The Northwind tables may predate the migrations, they are left in place.
@@ -0,0 +1,126 @@
+-- The Northwind tables the API reads. Databases that already hold Northwind keep
+-- their tables, only missing ones are created.
+
+IF OBJECT_ID(N'dbo.[Categories]', N'U') IS NULL
+CREATE TABLE "Categories" (
+    "CategoryID"   INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "CategoryName" NVARCHAR(15) NOT NULL,
+    "Description"  NVARCHAR(MAX) NULL
+)
+GO
+
+IF OBJECT_ID(N'dbo.[Suppliers]', N'U') IS NULL
+CREATE TABLE "Suppliers" (
+    "SupplierID"   INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "CompanyName"  NVARCHAR(40) NOT NULL,
+    "ContactName"  NVARCHAR(30) NULL,
+    "ContactTitle" NVARCHAR(30) NULL,
+    "Address"      NVARCHAR(60) NULL,
+    "City"         NVARCHAR(15) NULL,
+    "Region"       NVARCHAR(15) NULL,
+    "PostalCode"   NVARCHAR(10) NULL,
+    "Country"      NVARCHAR(15) NULL,
+    "Phone"        NVARCHAR(24) NULL,
+    "Fax"          NVARCHAR(24) NULL,
+    "HomePage"     NVARCHAR(MAX) NULL
+)
+GO
+
+IF OBJECT_ID(N'dbo.[Shippers]', N'U') IS NULL
+CREATE TABLE "Shippers" (
+    "ShipperID"   INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "CompanyName" NVARCHAR(40) NOT NULL,
+    "Phone"       NVARCHAR(24) NULL
+)
+GO
+
+IF OBJECT_ID(N'dbo.[Customers]', N'U') IS NULL
+CREATE TABLE "Customers" (
+    "CustomerID"   NCHAR(5) NOT NULL PRIMARY KEY,
+    "CompanyName"  NVARCHAR(40) NOT NULL,
+    "ContactName"  NVARCHAR(30) NULL,
+    "ContactTitle" NVARCHAR(30) NULL,
+    "Address"      NVARCHAR(60) NULL,
+    "City"         NVARCHAR(15) NULL,
+    "Region"       NVARCHAR(15) NULL,
+    "PostalCode"   NVARCHAR(10) NULL,
+    "Country"      NVARCHAR(15) NULL,
+    "Phone"        NVARCHAR(24) NULL,
+    "Fax"          NVARCHAR(24) NULL
+)
+GO
+
+IF OBJECT_ID(N'dbo.[Employees]', N'U') IS NULL
+CREATE TABLE "Employees" (
+    "EmployeeID"      INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "LastName"        NVARCHAR(20) NOT NULL,
+    "FirstName"       NVARCHAR(10) NOT NULL,
+    "Title"           NVARCHAR(30) NULL,
+    "TitleOfCourtesy" NVARCHAR(25) NULL,
+    "BirthDate"       DATETIME NULL,
+    "HireDate"        DATETIME NULL,
+    "Address"         NVARCHAR(60) NULL,
+    "City"            NVARCHAR(15) NULL,
+    "Region"          NVARCHAR(15) NULL,
+    "PostalCode"      NVARCHAR(10) NULL,
+    "Country"         NVARCHAR(15) NULL,
+    "HomePhone"       NVARCHAR(24) NULL,
+    "Extension"       NVARCHAR(4) NULL,
+    "Notes"           NVARCHAR(MAX) NULL,
+    "ReportsTo"       INT NULL,
+    "PhotoPath"       NVARCHAR(255) NULL,
+    CONSTRAINT "FK_Employees_Employees" FOREIGN KEY ("ReportsTo") REFERENCES "Employees" ("EmployeeID")
+)
+GO
+
+IF OBJECT_ID(N'dbo.[Products]', N'U') IS NULL
+CREATE TABLE "Products" (
+    "ProductID"       INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "ProductName"     NVARCHAR(40) NOT NULL,
+    "SupplierID"      INT NULL,
+    "CategoryID"      INT NULL,
+    "QuantityPerUnit" NVARCHAR(20) NULL,
+    "UnitPrice"       MONEY NULL DEFAULT 0,
+    "UnitsInStock"    SMALLINT NULL DEFAULT 0,
+    "UnitsOnOrder"    SMALLINT NULL DEFAULT 0,
+    "ReorderLevel"    SMALLINT NULL DEFAULT 0,
+    "Discontinued"    BIT NOT NULL DEFAULT 0,
+    CONSTRAINT "FK_Products_Suppliers" FOREIGN KEY ("SupplierID") REFERENCES "Suppliers" ("SupplierID"),
+    CONSTRAINT "FK_Products_Categories" FOREIGN KEY ("CategoryID") REFERENCES "Categories" ("CategoryID")
+)
+GO
+
+IF OBJECT_ID(N'dbo.[Orders]', N'U') IS NULL
+CREATE TABLE "Orders" (
+    "OrderID"        INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "CustomerID"     NCHAR(5) NULL,
+    "EmployeeID"     INT NULL,
+    "OrderDate"      DATETIME NULL,
+    "RequiredDate"   DATETIME NULL,
+    "ShippedDate"    DATETIME NULL,
+    "ShipVia"        INT NULL,
+    "Freight"        MONEY NULL DEFAULT 0,
+    "ShipName"       NVARCHAR(40) NULL,
+    "ShipAddress"    NVARCHAR(60) NULL,
+    "ShipCity"       NVARCHAR(15) NULL,
+    "ShipRegion"     NVARCHAR(15) NULL,
+    "ShipPostalCode" NVARCHAR(10) NULL,
+    "ShipCountry"    NVARCHAR(15) NULL,
+    CONSTRAINT "FK_Orders_Customers" FOREIGN KEY ("CustomerID") REFERENCES "Customers" ("CustomerID"),
+    CONSTRAINT "FK_Orders_Employees" FOREIGN KEY ("EmployeeID") REFERENCES "Employees" ("EmployeeID"),
+    CONSTRAINT "FK_Orders_Shippers" FOREIGN KEY ("ShipVia") REFERENCES "Shippers" ("ShipperID")
+)
+GO
+
+IF OBJECT_ID(N'dbo.[Order Details]', N'U') IS NULL
+CREATE TABLE "Order Details" (
+    "OrderID"   INT NOT NULL,
+    "ProductID" INT NOT NULL,
+    "UnitPrice" MONEY NOT NULL DEFAULT 0,
+    "Quantity"  SMALLINT NOT NULL DEFAULT 1,
+    "Discount"  REAL NOT NULL DEFAULT 0,
+    CONSTRAINT "PK_Order_Details" PRIMARY KEY ("OrderID", "ProductID"),
+    CONSTRAINT "FK_Order_Details_Orders" FOREIGN KEY ("OrderID") REFERENCES "Orders" ("OrderID"),
+    CONSTRAINT "FK_Order_Details_Products" FOREIGN KEY ("ProductID") REFERENCES "Products" ("ProductID")
+)
+GO
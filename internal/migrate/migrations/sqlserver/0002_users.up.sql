@@ -0,0 +1,19 @@
+-- Role is admin or user, only admins may purge the trash
+IF OBJECT_ID(N'dbo.[Users]', N'U') IS NULL
+BEGIN
+CREATE TABLE "Users" (
+    "UserID"       INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "Username"     NVARCHAR(50) NOT NULL,
+    "Email"        NVARCHAR(100) NOT NULL,
+    "PasswordHash" NVARCHAR(255) NOT NULL,
+    "Role"         NVARCHAR(50) NULL,
+    "IsActive"     BIT NOT NULL DEFAULT 1,
+    "EmployeeID"   INT NULL,
+    "CreatedAt"    DATETIME NOT NULL DEFAULT GETDATE(),
+    "UpdatedAt"    DATETIME NOT NULL DEFAULT GETDATE(),
+    CONSTRAINT "FK_Users_Employees" FOREIGN KEY ("EmployeeID") REFERENCES "Employees" ("EmployeeID") ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX "IX_Users_Username" ON "Users" ("Username");
+CREATE UNIQUE INDEX "IX_Users_Email" ON "Users" ("Email");
+END
+GO
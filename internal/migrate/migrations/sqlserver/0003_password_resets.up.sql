@@ -0,0 +1,14 @@
+IF OBJECT_ID(N'dbo.[password_resets]', N'U') IS NULL
+BEGIN
+CREATE TABLE "password_resets" (
+    "id"         INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "email"      NVARCHAR(100) NOT NULL,
+    "token"      NVARCHAR(255) NOT NULL,
+    "expires_at" DATETIME NOT NULL,
+    "used"       BIT NOT NULL DEFAULT 0,
+    "created_at" DATETIME NULL
+);
+CREATE INDEX "idx_password_resets_email" ON "password_resets" ("email");
+CREATE UNIQUE INDEX "idx_password_resets_token" ON "password_resets" ("token");
+END
+GO
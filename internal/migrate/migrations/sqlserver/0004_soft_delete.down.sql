@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS "IX_Categories_DeletedAt" ON "Categories"
+GO
+
+ALTER TABLE "Categories" DROP COLUMN IF EXISTS "DeletedAt", "DeletedBy"
+GO
+
+DROP INDEX IF EXISTS "IX_Employees_DeletedAt" ON "Employees"
+GO
+
+ALTER TABLE "Employees" DROP COLUMN IF EXISTS "DeletedAt", "DeletedBy"
+GO
+
+DROP INDEX IF EXISTS "IX_Products_DeletedAt" ON "Products"
+GO
+
+ALTER TABLE "Products" DROP COLUMN IF EXISTS "DeletedAt", "DeletedBy"
+GO
+
+DROP INDEX IF EXISTS "IX_Orders_DeletedAt" ON "Orders"
+GO
+
+ALTER TABLE "Orders" DROP COLUMN IF EXISTS "DeletedAt", "DeletedBy"
+GO
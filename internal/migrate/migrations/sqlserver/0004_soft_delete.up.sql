@@ -0,0 +1,40 @@
+-- Soft delete: Delete only stamps these columns, purge removes the row for good
+IF COL_LENGTH(N'dbo.Categories', N'DeletedAt') IS NULL
+BEGIN
+ALTER TABLE "Categories" ADD "DeletedAt" DATETIME NULL, "DeletedBy" INT NULL;
+END
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Categories_DeletedAt')
+CREATE INDEX "IX_Categories_DeletedAt" ON "Categories" ("DeletedAt")
+GO
+
+IF COL_LENGTH(N'dbo.Employees', N'DeletedAt') IS NULL
+BEGIN
+ALTER TABLE "Employees" ADD "DeletedAt" DATETIME NULL, "DeletedBy" INT NULL;
+END
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Employees_DeletedAt')
+CREATE INDEX "IX_Employees_DeletedAt" ON "Employees" ("DeletedAt")
+GO
+
+IF COL_LENGTH(N'dbo.Products', N'DeletedAt') IS NULL
+BEGIN
+ALTER TABLE "Products" ADD "DeletedAt" DATETIME NULL, "DeletedBy" INT NULL;
+END
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Products_DeletedAt')
+CREATE INDEX "IX_Products_DeletedAt" ON "Products" ("DeletedAt")
+GO
+
+IF COL_LENGTH(N'dbo.Orders', N'DeletedAt') IS NULL
+BEGIN
+ALTER TABLE "Orders" ADD "DeletedAt" DATETIME NULL, "DeletedBy" INT NULL;
+END
+GO
+
+IF NOT EXISTS (SELECT 1 FROM sys.indexes WHERE name = N'IX_Orders_DeletedAt')
+CREATE INDEX "IX_Orders_DeletedAt" ON "Orders" ("DeletedAt")
+GO
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "webhook_deliveries"
+GO
+
+DROP TABLE IF EXISTS "webhook_subscriptions"
+GO
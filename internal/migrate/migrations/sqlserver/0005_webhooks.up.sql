@@ -0,0 +1,36 @@
+-- Events is a JSON array such as ["order.created"], Status is pending, delivered or dead
+IF OBJECT_ID(N'dbo.[webhook_subscriptions]', N'U') IS NULL
+CREATE TABLE "webhook_subscriptions" (
+    "ID"        INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "UserID"    INT NOT NULL,
+    "URL"       NVARCHAR(2048) NOT NULL,
+    "Events"    NVARCHAR(1000) NOT NULL,
+    "Secret"    NVARCHAR(100) NOT NULL,
+    "Active"    BIT NOT NULL DEFAULT 1,
+    "CreatedAt" DATETIME NOT NULL DEFAULT GETDATE(),
+    CONSTRAINT "FK_WebhookSubscriptions_Users" FOREIGN KEY ("UserID") REFERENCES "Users" ("UserID")
+)
+GO
+
+IF OBJECT_ID(N'dbo.[webhook_deliveries]', N'U') IS NULL
+BEGIN
+CREATE TABLE "webhook_deliveries" (
+    "ID"             INT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    "SubscriptionID" INT NOT NULL,
+    "EventID"        NVARCHAR(64) NOT NULL,
+    "EventType"      NVARCHAR(100) NOT NULL,
+    "Payload"        NVARCHAR(MAX) NOT NULL,
+    "Status"         NVARCHAR(20) NOT NULL DEFAULT 'pending',
+    "Attempts"       INT NOT NULL DEFAULT 0,
+    "NextAttemptAt"  DATETIME NOT NULL,
+    "LockedUntil"    DATETIME NULL,
+    "ResponseStatus" INT NULL,
+    "LastError"      NVARCHAR(1000) NULL,
+    "CreatedAt"      DATETIME NOT NULL DEFAULT GETDATE(),
+    "DeliveredAt"    DATETIME NULL,
+    CONSTRAINT "FK_WebhookDeliveries_Subscriptions" FOREIGN KEY ("SubscriptionID") REFERENCES "webhook_subscriptions" ("ID") ON DELETE CASCADE
+);
+CREATE INDEX "IX_WebhookDeliveries_Due" ON "webhook_deliveries" ("Status", "NextAttemptAt");
+CREATE INDEX "IX_WebhookDeliveries_Subscription" ON "webhook_deliveries" ("SubscriptionID", "CreatedAt");
+END
+GO
@@ -0,0 +1,97 @@
+// Package netguard keeps requests to user supplied URLs, such as webhook targets, out
+// of the server's own network: loopback, private, link-local and other addresses that
+// are not reachable on the public internet are refused.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrNotPublic is returned for a target outside the public internet
+var ErrNotPublic = errors.New("address is not public")
+
+// reserved are ranges Go's netip helpers do not flag but that never lead to the
+// public internet
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // this network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved
+}
+
+// Public reports whether addr is a globally routable unicast address
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL rejects an http(s) URL whose host is, or resolves to, an address that
+// is not public
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !Public(addr) {
+			return fmt.Errorf("%s: %w", host, ErrNotPublic)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !Public(addr) {
+			return fmt.Errorf("%s resolves to %s: %w", host, addr, ErrNotPublic)
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control function refusing to connect to an address that
+// is not public. It runs after DNS resolution, so a host that resolves to a private
+// address only at delivery time, or a redirect to one, is caught as well.
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !Public(addrPort.Addr()) {
+		return fmt.Errorf("dial %s: %w", address, ErrNotPublic)
+	}
+	return nil
+}
+
+// Transport is http.DefaultTransport dialing through Control. It ignores the proxy
+// settings, a proxy would make the connection on the guard's behalf.
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: Control}
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,59 @@
+package netguard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublic(t *testing.T) {
+	cases := map[string]bool{
+		"93.184.215.14":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+	}
+	for addr, public := range cases {
+		assert.Equal(t, public, Public(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, CheckURL(ctx, "https://93.184.215.14/hooks"))
+	for _, target := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://127.0.0.1:8080/",
+		"http://[::1]/",
+		"http://localhost:3000/",
+	} {
+		assert.ErrorIs(t, CheckURL(ctx, target), ErrNotPublic, target)
+	}
+	assert.Error(t, CheckURL(ctx, "ftp://93.184.215.14/"))
+}
+
+func TestTransport_RefusesPrivateTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the guarded client must not reach a loopback server")
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: Transport()}
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotPublic)
+}
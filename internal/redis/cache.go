@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GetJSON loads a cached value into dest, reporting false on a cache miss
+func GetJSON(ctx context.Context, rdb *redis.Client, key string, dest interface{}) (bool, error) {
+	raw, err := rdb.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(raw, dest)
+}
+
+func SetJSON(ctx context.Context, rdb *redis.Client, key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, key, raw, ttl).Err()
+}
+
+// DeleteByPattern removes every key matching pattern using SCAN, so it never blocks Redis
+func DeleteByPattern(ctx context.Context, rdb *redis.Client, pattern string) error {
+	iter := rdb.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return rdb.Del(ctx, keys...).Err()
+}
@@ -3,16 +3,22 @@ package redis
 import (
 	"context"
 	"fmt"
+	"github.com/azizmasr1001/go-api-northwind/internal/metrics"
+	"github.com/azizmasr1001/go-api-northwind/internal/tracing"
 	"github.com/redis/go-redis/v9"
 	"time"
 )
 
+// NewRedisClient connects to host:port, times every command for /metrics and traces it
 func NewRedisClient(host, port, password string) *redis.Client {
-	return redis.NewClient(&redis.Options{
+	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", host, port),
 		Password: password,
 		DB:       0,
 	})
+	rdb.AddHook(tracing.Redis{})
+	rdb.AddHook(metrics.Redis{})
+	return rdb
 }
 
 func SetBlacklistToken(ctx context.Context, rdb *redis.Client, token string, duration time.Duration) error {
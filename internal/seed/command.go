@@ -0,0 +1,40 @@
+package seed
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Run is the seed subcommand:
+//
+//	seed [-only categories,products] [-orders 1000000]
+func Run(db *gorm.DB, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() {
+		fmt.Fprintln(out, "Usage: seed [flags]\n\nLoads a sample of the Northwind dataset: every category, supplier, shipper,\ncustomer, employee and product, and 15 of the 830 orders. Use -orders for volume.\n\nFlags:")
+		flags.PrintDefaults()
+	}
+	only := flags.String("only", "", "comma separated datasets to load, with the ones they reference: "+strings.Join(Datasets(), ", "))
+	orders := flags.Int("orders", 0, "synthetic orders to generate on top of the sample")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *orders < 0 {
+		return fmt.Errorf("-orders must not be negative, got %d", *orders)
+	}
+
+	opts := Options{SyntheticOrders: *orders}
+	if *only != "" {
+		opts.Tables = strings.Split(*only, ",")
+	}
+	results, err := Load(db, opts)
+	for _, r := range results {
+		fmt.Fprintf(out, "%-20s inserted %d, skipped %d\n", r.Table, r.Inserted, r.Skipped)
+	}
+	return err
+}
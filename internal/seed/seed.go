@@ -0,0 +1,263 @@
+// Package seed loads the embedded sample of the Northwind dataset, the reference
+// tables in full and 15 of the orders, into an empty or partly filled database.
+// Rows whose key already exists are skipped, so seeding twice is harmless.
+package seed
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:embed data
+var dataFS embed.FS
+
+// maxParams keeps every INSERT under the 2100 parameter limit of SQL Server
+const maxParams = 2000
+
+// Result is what seeding did to one table
+type Result struct {
+	Table    string
+	Inserted int
+	Skipped  int
+}
+
+type Options struct {
+	// Tables limits the load to these datasets and the ones they reference, all when empty
+	Tables []string
+	// SyntheticOrders generates this many extra orders with details for load testing
+	SyntheticOrders int
+}
+
+// dataset is one embedded file and the table it fills
+type dataset struct {
+	name string
+	deps []string
+	load func(db *gorm.DB) (Result, error)
+}
+
+// datasets are in dependency order, a dataset only references the ones before it
+var datasets = []dataset{
+	fixture("categories", true, func(r *models.Category) []interface{} { return []interface{}{r.CategoryID} }),
+	fixture("suppliers", true, func(r *models.Supplier) []interface{} { return []interface{}{r.SupplierID} }),
+	fixture("shippers", true, func(r *models.Shipper) []interface{} { return []interface{}{r.ShipperID} }),
+	fixture("customers", false, func(r *models.Customer) []interface{} { return []interface{}{r.CustomerID} }),
+	fixture("employees", true, func(r *models.Employee) []interface{} { return []interface{}{r.EmployeeID} }),
+	fixture("products", true, func(r *models.Product) []interface{} { return []interface{}{r.ProductID} },
+		"categories", "suppliers"),
+	fixture("orders", true, func(r *models.Order) []interface{} { return []interface{}{r.OrderID} },
+		"customers", "employees", "shippers"),
+	fixture("order_details", false, func(r *models.OrderDetail) []interface{} { return []interface{}{r.OrderID, r.ProductID} },
+		"orders", "products"),
+}
+
+// Datasets lists the names accepted by Options.Tables
+func Datasets() []string {
+	names := make([]string, len(datasets))
+	for i, d := range datasets {
+		names[i] = d.name
+	}
+	return names
+}
+
+// Load seeds the selected datasets in dependency order, then the synthetic orders
+func Load(db *gorm.DB, opts Options) ([]Result, error) {
+	selected, err := resolve(opts.Tables, opts.SyntheticOrders > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, d := range datasets {
+		if !selected[d.name] {
+			continue
+		}
+		res, err := d.load(db)
+		if err != nil {
+			return results, fmt.Errorf("seeding %s: %w", d.name, err)
+		}
+		results = append(results, res)
+	}
+
+	if opts.SyntheticOrders > 0 {
+		res, err := loadSynthetic(db, opts.SyntheticOrders)
+		if err != nil {
+			return results, fmt.Errorf("seeding synthetic orders: %w", err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// resolve adds the datasets each requested one references, synthetic orders need
+// everything an order and its details point at
+func resolve(tables []string, synthetic bool) (map[string]bool, error) {
+	byName := map[string]dataset{}
+	for _, d := range datasets {
+		byName[d.name] = d
+	}
+	if len(tables) == 0 {
+		tables = Datasets()
+	}
+	if synthetic {
+		tables = append(tables, "orders", "products")
+	}
+
+	selected := map[string]bool{}
+	var add func(name string) error
+	add = func(name string) error {
+		d, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown dataset %q, use %s", name, strings.Join(Datasets(), ", "))
+		}
+		selected[name] = true
+		for _, dep := range d.deps {
+			if err := add(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, name := range tables {
+		if err := add(strings.TrimSpace(name)); err != nil {
+			return nil, err
+		}
+	}
+	return selected, nil
+}
+
+// fixture builds the dataset for data/<name>.json. key returns the primary key
+// values of a row; identity tables get their explicit IDs inserted as they are.
+func fixture[T any](name string, identity bool, key func(*T) []interface{}, deps ...string) dataset {
+	return dataset{name: name, deps: deps, load: func(db *gorm.DB) (Result, error) {
+		raw, err := dataFS.ReadFile("data/" + name + ".json")
+		if err != nil {
+			return Result{}, err
+		}
+		var rows []T
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return Result{}, fmt.Errorf("data/%s.json: %w", name, err)
+		}
+		return insertMissing(db, rows, identity, key)
+	}}
+}
+
+// insertMissing writes the rows whose primary key is not in the table yet
+func insertMissing[T any](db *gorm.DB, rows []T, identity bool, key func(*T) []interface{}) (Result, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return Result{}, err
+	}
+	table := stmt.Schema.Table
+	res := Result{Table: table}
+
+	firsts := make([]interface{}, len(rows))
+	for i := range rows {
+		firsts[i] = key(&rows[i])[0]
+	}
+	existing, err := existingKeys(db.Model(new(T)).Unscoped(), stmt.Schema.PrimaryFieldDBNames, firsts)
+	if err != nil {
+		return res, err
+	}
+	var missing []T
+	for i := range rows {
+		if existing[keyString(key(&rows[i]))] {
+			res.Skipped++
+			continue
+		}
+		missing = append(missing, rows[i])
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	identityColumn := ""
+	if identity {
+		identityColumn = stmt.Schema.PrioritizedPrimaryField.DBName
+	}
+	batch := maxParams / len(stmt.Schema.DBNames)
+	err = insert(db, table, identityColumn, func(tx *gorm.DB) error {
+		return tx.CreateInBatches(missing, batch).Error
+	})
+	if err != nil {
+		return res, err
+	}
+	res.Inserted = len(missing)
+	return res, nil
+}
+
+// existingKeys reads the primary keys already in the table of query whose first column
+// is one of firsts
+func existingKeys(query *gorm.DB, columns []string, firsts []interface{}) (map[string]bool, error) {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = query.Statement.Quote(c)
+	}
+	rows, err := query.Select(strings.Join(quoted, ", ")).
+		Where(clause.IN{Column: columns[0], Values: firsts}).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := map[string]bool{}
+	values := make([]interface{}, len(columns))
+	for rows.Next() {
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		keys[keyString(values)] = true
+	}
+	return keys, rows.Err()
+}
+
+// keyString makes a map key that is the same whatever type the driver scanned into
+func keyString(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		parts[i] = strings.TrimSpace(fmt.Sprint(v))
+	}
+	return strings.Join(parts, "|")
+}
+
+// insert runs fn in a transaction that may write explicit values into identity,
+// the identity column of table, then moves the identity past the highest value written.
+// An empty identity means the table has none.
+func insert(db *gorm.DB, table, identity string, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		sqlServer := identity != "" && tx.Dialector.Name() == "sqlserver"
+		if sqlServer {
+			if err := tx.Exec("SET IDENTITY_INSERT " + tx.Statement.Quote(table) + " ON").Error; err != nil {
+				return err
+			}
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if sqlServer {
+			return tx.Exec("SET IDENTITY_INSERT " + tx.Statement.Quote(table) + " OFF").Error
+		}
+		if identity != "" && tx.Dialector.Name() == "postgres" {
+			// MySQL and SQLite move past explicit IDs on their own
+			return tx.Exec(
+				fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX(%s), 1)) FROM %s",
+					tx.Statement.Quote(identity), tx.Statement.Quote(table)),
+				tx.Statement.Quote(table), identity,
+			).Error
+		}
+		return nil
+	})
+}
@@ -0,0 +1,107 @@
+package seed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/database"
+	"github.com/azizmasr1001/go-api-northwind/internal/migrate"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func migratedDB(t *testing.T) *gorm.DB {
+	db, err := database.Open(database.DriverSQLite, "file::memory:")
+	require.NoError(t, err)
+	db.Logger = logger.Discard
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // every connection to :memory: is a database of its own
+
+	m, err := migrate.New(db)
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+	return db
+}
+
+func count(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	var n int64
+	require.NoError(t, db.Model(model).Count(&n).Error)
+	return n
+}
+
+func TestLoad_IsIdempotent(t *testing.T) {
+	db := migratedDB(t)
+
+	results, err := Load(db, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, len(datasets))
+	for _, r := range results {
+		assert.NotZero(t, r.Inserted, r.Table)
+		assert.Zero(t, r.Skipped, r.Table)
+	}
+	assert.EqualValues(t, 77, count(t, db, &models.Product{}))
+	assert.EqualValues(t, 91, count(t, db, &models.Customer{}))
+
+	var chai models.Product
+	require.NoError(t, db.First(&chai, 1).Error)
+	assert.Equal(t, "Chai", chai.ProductName)
+	assert.Equal(t, 18.0, chai.UnitPrice)
+
+	results, err = Load(db, Options{})
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.Zero(t, r.Inserted, r.Table)
+	}
+	assert.EqualValues(t, 77, count(t, db, &models.Product{}))
+
+	// the identity keeps counting after the seeded IDs
+	category := models.Category{CategoryName: "Snacks"}
+	require.NoError(t, db.Create(&category).Error)
+	assert.Equal(t, 9, category.CategoryID)
+}
+
+func TestLoad_SubsetBringsItsReferences(t *testing.T) {
+	db := migratedDB(t)
+
+	var out bytes.Buffer
+	require.NoError(t, Run(db, []string{"-only", "products"}, &out))
+	assert.Contains(t, out.String(), "Products             inserted 77, skipped 0")
+	assert.EqualValues(t, 8, count(t, db, &models.Category{}))
+	assert.EqualValues(t, 29, count(t, db, &models.Supplier{}))
+	assert.Zero(t, count(t, db, &models.Customer{}))
+	assert.Zero(t, count(t, db, &models.Order{}))
+
+	assert.ErrorContains(t, Run(db, []string{"-only", "regions"}, &out), `unknown dataset "regions"`)
+}
+
+func TestLoad_SyntheticOrdersResume(t *testing.T) {
+	db := migratedDB(t)
+
+	results, err := Load(db, Options{Tables: []string{"orders"}, SyntheticOrders: 1500})
+	require.NoError(t, err)
+	assert.Equal(t, Result{Table: "Orders (synthetic)", Inserted: 1500}, results[len(results)-1])
+	seeded := count(t, db, &models.OrderDetail{})
+
+	results, err = Load(db, Options{Tables: []string{"orders"}, SyntheticOrders: 2000})
+	require.NoError(t, err)
+	assert.Equal(t, Result{Table: "Orders (synthetic)", Inserted: 500, Skipped: 1500}, results[len(results)-1])
+	assert.EqualValues(t, 15+2000, count(t, db, &models.Order{}))
+
+	// the same ID always yields the same order
+	var refs references
+	refs, err = loadReferences(db)
+	require.NoError(t, err)
+	first, lines := refs.order(syntheticBase + 1)
+	var stored models.Order
+	require.NoError(t, db.First(&stored, syntheticBase+1).Error)
+	assert.Equal(t, *first.CustomerID, *stored.CustomerID)
+	var storedLines int64
+	require.NoError(t, db.Model(&models.OrderDetail{}).Where(`"OrderID" = ?`, syntheticBase+1).Count(&storedLines).Error)
+	assert.EqualValues(t, len(lines), storedLines)
+	assert.Greater(t, seeded, int64(1500))
+}
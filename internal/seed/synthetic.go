@@ -0,0 +1,150 @@
+package seed
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// syntheticBase is where generated order IDs start, well above the Northwind ones
+	syntheticBase = 1_000_000
+	// ordersPerChunk orders and their details are committed together
+	ordersPerChunk = 1000
+)
+
+var (
+	syntheticFrom = time.Date(1996, 7, 4, 0, 0, 0, 0, time.UTC)
+	discounts     = []float64{0, 0, 0, 0, 0.05, 0.1, 0.15, 0.2, 0.25}
+)
+
+// references are the rows a synthetic order can point at
+type references struct {
+	customers []models.Customer
+	employees []int
+	shippers  []int
+	products  []models.Product
+}
+
+// loadSynthetic writes orders syntheticBase+1 to syntheticBase+n with one to five
+// lines each. Every order is derived from its ID alone, so a rerun picks up after
+// the last order written and produces the same data.
+func loadSynthetic(db *gorm.DB, n int) (Result, error) {
+	res := Result{Table: "Orders (synthetic)"}
+
+	var last int
+	err := db.Model(&models.Order{}).Unscoped().
+		Select(`COALESCE(MAX("OrderID"), ?)`, syntheticBase).
+		Where(`"OrderID" > ?`, syntheticBase).
+		Scan(&last).Error
+	if err != nil {
+		return res, err
+	}
+	res.Skipped = min(last-syntheticBase, n)
+
+	refs, err := loadReferences(db)
+	if err != nil {
+		return res, err
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&models.Order{}); err != nil {
+		return res, err
+	}
+	orderBatch := maxParams / len(stmt.Schema.DBNames)
+	if err := stmt.Parse(&models.OrderDetail{}); err != nil {
+		return res, err
+	}
+	detailBatch := maxParams / len(stmt.Schema.DBNames)
+
+	for start := last + 1; start <= syntheticBase+n; start += ordersPerChunk {
+		end := min(start+ordersPerChunk-1, syntheticBase+n)
+		var orders []models.Order
+		var details []models.OrderDetail
+		for id := start; id <= end; id++ {
+			order, lines := refs.order(id)
+			orders = append(orders, order)
+			details = append(details, lines...)
+		}
+
+		err := insert(db, models.Order{}.TableName(), "OrderID", func(tx *gorm.DB) error {
+			if err := tx.CreateInBatches(orders, orderBatch).Error; err != nil {
+				return err
+			}
+			return tx.CreateInBatches(details, detailBatch).Error
+		})
+		if err != nil {
+			return res, err
+		}
+		res.Inserted += len(orders)
+	}
+	return res, nil
+}
+
+func loadReferences(db *gorm.DB) (references, error) {
+	var refs references
+	err := errors.Join(
+		db.Find(&refs.customers).Error,
+		db.Model(&models.Employee{}).Pluck("EmployeeID", &refs.employees).Error,
+		db.Model(&models.Shipper{}).Pluck("ShipperID", &refs.shippers).Error,
+		db.Select("ProductID", "UnitPrice").Find(&refs.products).Error,
+	)
+	if err != nil {
+		return refs, err
+	}
+	if len(refs.customers) == 0 || len(refs.employees) == 0 || len(refs.shippers) == 0 || len(refs.products) == 0 {
+		return refs, errors.New("synthetic orders need customers, employees, shippers and products")
+	}
+	return refs, nil
+}
+
+// order generates order id and its lines from a random source seeded with id
+func (refs references) order(id int) (models.Order, []models.OrderDetail) {
+	rnd := rand.New(rand.NewSource(int64(id)))
+
+	customer := refs.customers[rnd.Intn(len(refs.customers))]
+	employee := refs.employees[rnd.Intn(len(refs.employees))]
+	shipVia := refs.shippers[rnd.Intn(len(refs.shippers))]
+	orderDate := syntheticFrom.AddDate(0, 0, rnd.Intn(10*365))
+	required := orderDate.AddDate(0, 0, 28)
+	var shipped *time.Time
+	if rnd.Intn(10) > 0 {
+		at := orderDate.AddDate(0, 0, 1+rnd.Intn(30))
+		shipped = &at
+	}
+
+	order := models.Order{
+		OrderID:        id,
+		CustomerID:     &customer.CustomerID,
+		EmployeeID:     &employee,
+		OrderDate:      &orderDate,
+		RequiredDate:   &required,
+		ShippedDate:    shipped,
+		ShipVia:        &shipVia,
+		Freight:        math.Round(rnd.Float64()*20000) / 100,
+		ShipName:       customer.CompanyName,
+		ShipAddress:    customer.Address,
+		ShipCity:       customer.City,
+		ShipRegion:     customer.Region,
+		ShipPostalCode: customer.PostalCode,
+		ShipCountry:    customer.Country,
+	}
+
+	lines := min(1+rnd.Intn(5), len(refs.products))
+	details := make([]models.OrderDetail, lines)
+	for i, p := range rnd.Perm(len(refs.products))[:lines] {
+		product := refs.products[p]
+		details[i] = models.OrderDetail{
+			OrderID:   id,
+			ProductID: product.ProductID,
+			UnitPrice: product.UnitPrice,
+			Quantity:  1 + rnd.Intn(50),
+			Discount:  discounts[rnd.Intn(len(discounts))],
+		}
+	}
+	return order, details
+}
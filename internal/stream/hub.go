@@ -0,0 +1,170 @@
+// Package stream fans domain events out to connected /api/stream clients.
+// Events go through one Redis pub/sub channel so every instance sees every write.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	Channel          = "northwind:events"
+	subscriberBuffer = 64
+)
+
+// families are the event types streamed to clients: any logged-in user may listen,
+// so user.* and its account data stay out
+var families = []string{"order.", "product."}
+
+// Streamed reports whether events of eventType reach stream clients
+func Streamed(eventType string) bool {
+	for _, family := range families {
+		if strings.HasPrefix(eventType, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hub publishes events to Redis and relays what it receives to local subscribers
+type Hub struct {
+	rdb     *redis.Client
+	channel string
+
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+func NewHub(rdb *redis.Client, channel string) *Hub {
+	return &Hub{rdb: rdb, channel: channel, subs: map[*Subscription]struct{}{}}
+}
+
+// Publish implements services.EventPublisher, events that are not Streamed are dropped
+func (h *Hub) Publish(ctx context.Context, event models.Event) error {
+	if !Streamed(event.Type) {
+		return nil
+	}
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return h.rdb.Publish(ctx, h.channel, raw).Err()
+}
+
+// Run listens on the Redis channel until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) error {
+	pubsub := h.rdb.Subscribe(ctx, h.channel)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			h.closeAll()
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			var event Message
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				slog.Warn("Dropping malformed stream event", "error", err)
+				continue
+			}
+			h.broadcast(event)
+		}
+	}
+}
+
+// Message is an event as read back from Redis, Data stays raw JSON
+type Message struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt string          `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Subscription receives the events matching its topics on C.
+// C is closed when the subscriber is too slow or the hub stops.
+type Subscription struct {
+	C      chan Message
+	topics []string
+	once   sync.Once
+}
+
+// Subscribe registers a listener. topics are event types ("order.created") or
+// prefixes ending in ".*" ("order.*"); no topics means everything.
+func (h *Hub) Subscribe(topics []string) *Subscription {
+	sub := &Subscription{C: make(chan Message, subscriberBuffer), topics: topics}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+	sub.close()
+}
+
+func (h *Hub) broadcast(event Message) {
+	h.mu.RLock()
+	var slow []*Subscription
+	for sub := range h.subs {
+		// an instance running an older build may still publish other events
+		if !Streamed(event.Type) || !sub.wants(event.Type) {
+			continue
+		}
+		select {
+		case sub.C <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	// a client that cannot keep up is dropped, it reconnects and refetches
+	for _, sub := range slow {
+		h.Unsubscribe(sub)
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	subs := h.subs
+	h.subs = map[*Subscription]struct{}{}
+	h.mu.Unlock()
+	for sub := range subs {
+		sub.close()
+	}
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.C) })
+}
+
+func (s *Subscription) wants(eventType string) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+	for _, topic := range s.topics {
+		if topic == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(topic, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runningHub(t *testing.T) *Hub {
+	mr := miniredis.RunT(t)
+	hub := NewHub(redis.NewClient(&redis.Options{Addr: mr.Addr()}), Channel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go hub.Run(ctx)
+	require.Eventually(t, func() bool { return mr.PubSubNumSub(Channel)[Channel] == 1 }, time.Second, 10*time.Millisecond)
+	return hub
+}
+
+func receive(t *testing.T, sub *Subscription) Message {
+	select {
+	case msg := <-sub.C:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Message{}
+	}
+}
+
+func TestHub_FiltersByTopic(t *testing.T) {
+	hub := runningHub(t)
+	all := hub.Subscribe(nil)
+	orders := hub.Subscribe([]string{"order.*"})
+	stock := hub.Subscribe([]string{models.EventProductStockChanged})
+	defer hub.Unsubscribe(all)
+	defer hub.Unsubscribe(orders)
+	defer hub.Unsubscribe(stock)
+
+	require.NoError(t, hub.Publish(t.Context(), models.Event{ID: "evt_0", Type: models.EventUserRegistered, Data: map[string]string{"username": "newcomer"}}))
+	require.NoError(t, hub.Publish(t.Context(), models.Event{ID: "evt_1", Type: models.EventProductStockChanged, Data: map[string]int{"product_id": 1}}))
+	require.NoError(t, hub.Publish(t.Context(), models.Event{ID: "evt_2", Type: models.EventOrderCreated, Data: map[string]int{"order_id": 7}}))
+
+	assert.Equal(t, "evt_1", receive(t, all).ID, "user events are never streamed")
+	assert.Equal(t, "evt_2", receive(t, all).ID)
+
+	msg := receive(t, orders)
+	assert.Equal(t, models.EventOrderCreated, msg.Type)
+	assert.JSONEq(t, `{"order_id":7}`, string(msg.Data))
+
+	assert.Equal(t, "evt_1", receive(t, stock).ID)
+	select {
+	case msg := <-stock.C:
+		t.Fatalf("unexpected event %s", msg.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHub_UnsubscribeClosesChannel(t *testing.T) {
+	hub := NewHub(nil, Channel)
+	sub := hub.Subscribe(nil)
+	hub.Unsubscribe(sub)
+	hub.Unsubscribe(sub)
+
+	_, open := <-sub.C
+	assert.False(t, open)
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordSpans swaps the global provider for one keeping every ended span
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestSetup_Exporters(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	for _, exporter := range []string{"", ExporterNone, ExporterStdout} {
+		shutdown, err := Setup(context.Background(), exporter, "", 1)
+		require.NoError(t, err, exporter)
+		assert.NoError(t, shutdown(context.Background()), exporter)
+	}
+
+	_, err := Setup(context.Background(), "jaeger", "", 1)
+	assert.ErrorContains(t, err, `invalid TRACING_EXPORTER "jaeger"`)
+}
+
+func TestGorm_SpansNestUnderTheRequest(t *testing.T) {
+	recorder := recordSpans(t)
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE "Shippers" ("ShipperID" INTEGER PRIMARY KEY, "CompanyName" TEXT)`).Error)
+	require.NoError(t, db.Use(Gorm{}))
+	type Shipper struct {
+		ShipperID   int `gorm:"column:ShipperID;primaryKey"`
+		CompanyName string
+	}
+
+	ctx, parent := Tracer().Start(context.Background(), "GET /shippers")
+	var total int64
+	var shippers []Shipper
+	query := db.WithContext(ctx).Model(&Shipper{}).Where(`"CompanyName" LIKE ?`, "%Express%")
+	require.NoError(t, query.Count(&total).Error)
+	require.NoError(t, query.Find(&shippers).Error)
+	require.Error(t, db.WithContext(ctx).Table("Missing").Find(&shippers).Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	for _, span := range spans[:3] {
+		assert.Equal(t, "gorm.query", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), "a chained query must not nest in the previous one")
+		assert.Equal(t, "sqlite", attr(span, "db.system").AsString())
+	}
+	assert.Equal(t, "shippers", attr(spans[0], "db.collection.name").AsString())
+	assert.Contains(t, attr(spans[0], "db.query.text").AsString(), "LIKE ?", "bound values stay out of the span")
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
+
+func TestRedis_Spans(t *testing.T) {
+	recorder := recordSpans(t)
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	rdb.AddHook(Redis{})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	ctx, parent := Tracer().Start(context.Background(), "POST /auth/logout")
+	require.NoError(t, rdb.Set(ctx, "token", "blacklisted", 0).Err())
+	assert.ErrorIs(t, rdb.Get(ctx, "missing").Err(), redis.Nil)
+	_, err := rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, "a")
+		p.Incr(ctx, "b")
+		return nil
+	})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	assert.Equal(t, []string{"redis.set", "redis.get", "redis.pipeline"}, []string{spans[0].Name(), spans[1].Name(), spans[2].Name()})
+	for _, span := range spans[:3] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, "redis", attr(span, "db.system").AsString())
+	}
+	assert.Equal(t, codes.Unset, spans[1].Status().Code, "a cache miss is not an error")
+	assert.Equal(t, int64(2), attr(spans[2], "db.redis.pipeline_length").AsInt64())
+}
@@ -2,57 +2,101 @@ package main
 
 import (
 	"context"
-	"github.com/aronipurwanto/go-api-northwind/config"
-	"github.com/aronipurwanto/go-api-northwind/controllers"
-	"github.com/aronipurwanto/go-api-northwind/repositories"
-	"github.com/aronipurwanto/go-api-northwind/routes"
-	"github.com/aronipurwanto/go-api-northwind/services"
-	"github.com/gofiber/fiber/v2"
-	"gorm.io/driver/sqlserver"
-	"gorm.io/gorm"
+	"errors"
+	"github.com/azizmasr1001/go-api-northwind/config"
+	"github.com/azizmasr1001/go-api-northwind/internal/app"
+	"github.com/azizmasr1001/go-api-northwind/internal/database"
+	"github.com/azizmasr1001/go-api-northwind/internal/logging"
+	"github.com/azizmasr1001/go-api-northwind/internal/migrate"
+	"github.com/azizmasr1001/go-api-northwind/internal/seed"
+	"github.com/azizmasr1001/go-api-northwind/internal/tracing"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	redisClient "github.com/aronipurwanto/go-api-northwind/internal/redis"
-	"github.com/gofiber/swagger"
+	redisClient "github.com/azizmasr1001/go-api-northwind/internal/redis"
 )
 
 func main() {
 	cfg := config.LoadConfig()
-
-	db, err := gorm.Open(sqlserver.Open(cfg.DBSource), &gorm.Config{})
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
 	if err != nil {
-		log.Fatal("Failed to connect to DB: ", err)
+		log.Fatal(err)
 	}
-	log.Println("Connected to DB")
+	// the standard log package goes through the same handler from here on
+	slog.SetDefault(logger)
 
-	redis := redisClient.NewRedisClient(cfg.RedisHost, cfg.RedisPort, cfg.RedisPass)
-	pong, err := redis.Ping(context.Background()).Result()
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter, cfg.TracingEndpoint, cfg.TracingSampleRatio)
 	if err != nil {
-		log.Fatal("Failed to connect to Redis:", err)
+		fatal("Failed to set up tracing", err)
 	}
-	log.Println("Connected to Redis:", pong)
 
-	empRepo := repositories.NewEmployeeRepository(db)
-	empService := services.NewEmployeeService(empRepo)
-	empController := controllers.NewEmployeeController(empService)
+	db, err := database.Open(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		fatal("Failed to connect to DB", err)
+	}
+	slog.Info("Connected to DB", "driver", db.Dialector.Name())
 
-	authRepo := repositories.NewAuthRepository(db)
-	authService := services.NewAuthService(authRepo, cfg.JWTSecret, redis)
-	authController := controllers.NewAuthController(authService)
+	migrator, err := migrate.New(db)
+	if err != nil {
+		fatal("Failed to load migrations", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate.Run(migrator, os.Args[2:], os.Stdout); err != nil {
+			fatal("Migration failed", err)
+		}
+		return
+	}
+	if err := migrator.Check(); err != nil {
+		fatal("Schema is not up to date", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := seed.Run(db, os.Args[2:], os.Stdout); err != nil {
+			fatal("Seeding failed", err)
+		}
+		return
+	}
 
-	categoryRepo := repositories.NewCategoryRepository(db)
-	categoryService := services.NewCategoryService(categoryRepo)
-	categoryController := controllers.NewCategoryController(categoryService)
+	redis := redisClient.NewRedisClient(cfg.RedisHost, cfg.RedisPort, cfg.RedisPass)
+	pong, err := redis.Ping(context.Background()).Result()
+	if err != nil {
+		fatal("Failed to connect to Redis", err)
+	}
+	slog.Info("Connected to Redis", "reply", pong)
 
-	productRepo := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepo)
-	productController := controllers.NewProductController(productService)
+	server, err := app.New(cfg, db, redis)
+	if err != nil {
+		fatal("Failed to build the app", err)
+	}
+	// SIGTERM stops the workers and the live event streams, then the servers drain
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server.Run(ctx)
 
-	orderRepo := repositories.NewOrderRepository(db)
-	orderService := services.NewOrderService(orderRepo)
-	orderController := controllers.NewOrderController(orderService)
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		fatal("Failed to listen for gRPC", err)
+	}
+	go func() {
+		slog.Info("gRPC listening", "addr", grpcListener.Addr().String())
+		if err := server.GRPC.Serve(grpcListener); err != nil {
+			fatal("gRPC server stopped", err)
+		}
+	}()
 
-	app := fiber.New()
+	if server.Metrics != nil {
+		go func() {
+			slog.Info("Metrics listening", "addr", server.Metrics.Addr)
+			if err := server.Metrics.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fatal("Metrics server stopped", err)
+			}
+		}()
+	}
 
 	// Swagger info
 	// @title Northwind API
@@ -62,9 +106,39 @@ func main() {
 	// @in header
 	// @name Authorization
 
-	app.Get("/swagger/*", swagger.HandlerDefault)
+	go func() {
+		slog.Info("HTTP listening", "port", cfg.Port)
+		if err := server.Fiber.Listen(":" + cfg.Port); err != nil {
+			fatal("HTTP server stopped", err)
+		}
+	}()
 
-	routes.SetupRoutes(app, cfg, redis, authController, empController, categoryController, productController, orderController)
+	<-ctx.Done()
+	stop() // a second signal kills the process right away
+	slog.Info("Shutting down, draining in-flight requests", "timeout", cfg.ShutdownTimeout.String())
+	if err := server.Shutdown(cfg.ShutdownTimeout); err != nil {
+		slog.Error("Shutdown", "error", err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Failed to close the DB pool", "error", err)
+		}
+	}
+	if err := redis.Close(); err != nil {
+		slog.Error("Failed to close the Redis pool", "error", err)
+	}
+	// the spans of the drained requests are still in the batch
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	slog.Info("Stopped")
+}
 
-	log.Fatal(app.Listen(":" + cfg.Port))
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
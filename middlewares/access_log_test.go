@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the default logger to a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "debug")
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func accessLogApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RequestID(), AccessLog())
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		c.Locals("user_id", float64(7))
+		slog.InfoContext(c.UserContext(), "handling")
+		return c.SendString("ok")
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fiber.ErrNotFound
+	})
+	return app
+}
+
+func TestAccessLog_CarriesRequestID(t *testing.T) {
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest("GET", "/orders/10248", nil)
+	req.Header.Set(HeaderRequestID, "abc-123")
+	resp, err := accessLogApp().Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "abc-123", resp.Header.Get(HeaderRequestID))
+
+	entries := logEntries(t, logs)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "handling", entries[0]["msg"])
+	assert.Equal(t, "abc-123", entries[0]["request_id"])
+
+	access := entries[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "abc-123", access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/orders/:id", access["route"])
+	assert.EqualValues(t, 200, access["status"])
+	assert.EqualValues(t, 7, access["user_id"])
+	assert.Contains(t, access, "latency_ms")
+}
+
+func TestAccessLog_LogsRenderedErrorStatus(t *testing.T) {
+	logs := captureLogs(t)
+
+	resp, err := accessLogApp().Test(httptest.NewRequest("GET", "/missing", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	entries := logEntries(t, logs)
+	require.Len(t, entries, 1)
+	assert.EqualValues(t, 404, entries[0]["status"])
+	assert.Nil(t, entries[0]["user_id"])
+}
+
+func TestRequestID_GeneratedWhenMissingOrInvalid(t *testing.T) {
+	captureLogs(t)
+	app := accessLogApp()
+
+	for _, sent := range []string{"", "has space", strings.Repeat("x", 200)} {
+		req := httptest.NewRequest("GET", "/orders/1", nil)
+		if sent != "" {
+			req.Header.Set(HeaderRequestID, sent)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		got := resp.Header.Get(HeaderRequestID)
+		assert.Len(t, got, 36, "sent %q", sent)
+	}
+}
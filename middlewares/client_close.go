@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// clientClosePollInterval is how often a running request checks its connection
+var clientClosePollInterval = 200 * time.Millisecond
+
+// errClientClosed is the cause of a request context cancelled by CancelOnClientClose.
+// The context is also cancelled when the request ends, the cause tells the two apart.
+var errClientClosed = errors.New("client closed the connection")
+
+// CancelOnClientClose cancels c.UserContext() once the client closes its connection,
+// so the queries of a request nobody waits for stop and ErrorHandler logs it as
+// cancelled. fasthttp does not report a closed connection, so the middleware peeks
+// at it while the handler runs, without reading what the client sent next.
+// Connections it cannot peek at (TLS, tests over app.Test) are never cancelled.
+//
+// Like Timeout it must run after Tracing so the context keeps the request's span.
+func CancelOnClientClose() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		closed := connClosedFunc(c.Context().Conn())
+		if closed == nil {
+			return c.Next()
+		}
+		ctx, cancel := context.WithCancelCause(c.UserContext())
+		defer cancel(nil)
+		c.SetUserContext(ctx)
+
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(clientClosePollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if closed() {
+						cancel(errClientClosed)
+						return
+					}
+				}
+			}
+		}()
+		// the connection goes back to fasthttp once the handler returns
+		defer wg.Wait()
+		defer close(done)
+		return c.Next()
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package middlewares
+
+import "net"
+
+// connClosedFunc cannot peek at a socket here, requests are only cancelled by their deadline
+func connClosedFunc(net.Conn) func() bool { return nil }
@@ -0,0 +1,107 @@
+//go:build unix
+
+package middlewares
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clientCloseApp serves handler at /wait over a real listener and reports why the
+// request's context ended once the request is rendered
+func clientCloseApp(t *testing.T, handler fiber.Handler) (string, chan error) {
+	previous := clientClosePollInterval
+	clientClosePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { clientClosePollInterval = previous })
+
+	finished := make(chan error, 2)
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler, DisableStartupMessage: true})
+	app.Get("/wait", func(c *fiber.Ctx) error {
+		// rendered here so the log entry is written before the test reads it
+		if err := c.Next(); err != nil {
+			require.NoError(t, ErrorHandler(c, err))
+		}
+		finished <- context.Cause(c.UserContext())
+		return nil
+	}, CancelOnClientClose(), handler)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+	return ln.Addr().String(), finished
+}
+
+// waitFor waits up to wait for the request's context
+func waitFor(wait time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+			return fmt.Errorf("list products: %w", c.UserContext().Err())
+		case <-time.After(wait):
+			return c.SendStatus(http.StatusNoContent)
+		}
+	}
+}
+
+func TestCancelOnClientClose_ClosedConnection(t *testing.T) {
+	logs := captureLogs(t)
+	addr, finished := clientCloseApp(t, waitFor(5*time.Second))
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	fmt.Fprint(conn, "GET /wait HTTP/1.1\r\nHost: test\r\n\r\n")
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, conn.Close())
+
+	select {
+	case err := <-finished:
+		assert.ErrorIs(t, err, errClientClosed)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the request was not cancelled")
+	}
+	entries := logEntries(t, logs)
+	require.NotEmpty(t, entries)
+	assert.Equal(t, "Request cancelled by client", entries[len(entries)-1]["msg"])
+}
+
+func TestCancelOnClientClose_OpenConnection(t *testing.T) {
+	addr, finished := clientCloseApp(t, waitFor(100*time.Millisecond))
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	// a keep-alive client that already sent its next request is still there
+	fmt.Fprint(conn, "GET /wait HTTP/1.1\r\nHost: test\r\n\r\nGET /wait HTTP/1.1\r\nHost: test\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	for range 2 {
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.NotErrorIs(t, <-finished, errClientClosed)
+	}
+}
+
+func TestCancelOnClientClose_ErrorsKeepTheirStatus(t *testing.T) {
+	addr, finished := clientCloseApp(t, func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	resp, err := http.Get("http://" + addr + "/wait")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.NotErrorIs(t, <-finished, errClientClosed)
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package middlewares
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// connClosedFunc returns a check that peeks one byte without consuming it: a read
+// of 0 bytes or a reset means the peer closed the connection. It is nil when conn
+// is not a socket.
+func connClosedFunc(conn net.Conn) func() bool {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return nil
+	}
+	return func() bool {
+		closed := false
+		raw.Read(func(fd uintptr) bool {
+			var buf [1]byte
+			n, _, err := syscall.Recvfrom(int(fd), buf[:], syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+			closed = (n == 0 && err == nil) || errors.Is(err, syscall.ECONNRESET)
+			return true
+		})
+		return closed
+	}
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+var kindStatus = map[services.Kind]int{
+	services.KindInvalid:       fiber.StatusBadRequest,
+	services.KindUnauthorized:  fiber.StatusUnauthorized,
+	services.KindForbidden:     fiber.StatusForbidden,
+	services.KindNotFound:      fiber.StatusNotFound,
+	services.KindConflict:      fiber.StatusConflict,
+	services.KindPrecondition:  fiber.StatusPreconditionFailed,
+	services.KindTooLarge:      fiber.StatusRequestEntityTooLarge,
+	services.KindUnsupported:   fiber.StatusUnsupportedMediaType,
+	services.KindUnprocessable: fiber.StatusUnprocessableEntity,
+}
+
+// StatusClientClosedRequest is nginx's status for a client that went away before the
+// response, it only ever reaches the access log
+const StatusClientClosedRequest = 499
+
+// ErrorHandler is the fiber.Config ErrorHandler, it renders every error returned by a
+// handler as application/problem+json. A services.Error, or a repository error that
+// services.AsError translates, keeps its code and message; a fiber.Error (unknown
+// route, body too large, ...) keeps its status. A request that failed after its
+// deadline passed is a 504 and one whose client went away (see CancelOnClientClose) is
+// only logged, with a 499 for the access log. Anything else is logged and answered
+// with a bare 500 so database and driver messages never reach the client.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	if svcErr := services.AsError(err); svcErr != nil {
+		status, ok := kindStatus[svcErr.Kind]
+		if !ok {
+			status = fiber.StatusInternalServerError
+		}
+		return utils.WriteProblem(c, utils.NewProblem(c, status, svcErr.Code, svcErr.Message, svcErr.Details))
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code < fiber.StatusInternalServerError {
+		return utils.WriteProblem(c, utils.NewProblem(c, fiberErr.Code, statusCode(fiberErr.Code), fiberErr.Message, nil))
+	}
+
+	// drivers do not always wrap ctx.Err(), a failure past the deadline is a timeout
+	ctx := c.UserContext()
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		slog.WarnContext(ctx, "Request timed out", "method", c.Method(), "url", c.OriginalURL(), "error", err)
+		return utils.WriteProblem(c, utils.NewProblem(c, fiber.StatusGatewayTimeout, services.CodeTimeout, "Request timed out", nil))
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(context.Cause(ctx), errClientClosed) {
+		slog.InfoContext(ctx, "Request cancelled by client", "method", c.Method(), "url", c.OriginalURL())
+		return c.SendStatus(StatusClientClosedRequest)
+	}
+
+	slog.ErrorContext(ctx, "Unhandled error", "method", c.Method(), "url", c.OriginalURL(), "error", err)
+	return utils.WriteProblem(c, utils.NewProblem(c, fiber.StatusInternalServerError, services.CodeInternal, "Internal server error", nil))
+}
+
+// statusCode derives an error code from the status text, e.g. 405 method_not_allowed
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/taken", func(c *fiber.Ctx) error {
+		return services.Conflict(services.CodeUsernameTaken, "Username already taken",
+			utils.ErrorDetail{Field: "username", Message: "already taken"})
+	})
+	app.Get("/sql", func(c *fiber.Ctx) error {
+		return errors.New("mssql: Invalid column name 'Secret'")
+	})
+	app.Get("/slow", Timeout(20*time.Millisecond), func(c *fiber.Ctx) error {
+		// drivers may report a cancelled query with an error of their own
+		<-c.UserContext().Done()
+		return errors.New("mssql: did not get cancellation confirmation from the server")
+	})
+
+	cases := []struct {
+		path, code string
+		status     int
+	}{
+		{"/taken", services.CodeUsernameTaken, 409},
+		{"/sql", services.CodeInternal, 500},
+		{"/slow", services.CodeTimeout, 504},
+		{"/missing", "not_found", 404},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", tc.path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.path)
+		assert.Equal(t, utils.MIMEProblemJSON, resp.Header.Get("Content-Type"), tc.path)
+
+		var problem utils.Problem
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+		assert.Equal(t, tc.code, problem.Code, tc.path)
+		assert.Equal(t, tc.status, problem.Status, tc.path)
+		assert.Equal(t, tc.path, problem.Instance)
+		assert.NotContains(t, problem.Title, "mssql")
+	}
+}
+
+func TestErrorHandler_ClientCancelled(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/", func(c *fiber.Ctx) error {
+		return fmt.Errorf("count products: %w", context.Canceled)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, StatusClientClosedRequest, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body, "nobody is left to read a problem")
+}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ValidateFields parses "?fields=" against model (and the optional include models)
+// and stores the result under c.Locals("fields")
+func ValidateFields(model interface{}, includes map[string]interface{}) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		fields, errs := utils.ParseFields(c.Query("fields"), model, includes)
+		if errs != nil {
+			return services.Invalid(services.CodeInvalidParameter, "Invalid fields parameter", errs...)
+		}
+
+		c.Locals("fields", fields)
+
+		return c.Next()
+	}
+}
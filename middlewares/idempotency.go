@@ -0,0 +1,201 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+
+	maxIdempotencyKey = 255
+)
+
+// idempotencyLockTTL bounds how long a crashed instance blocks a key. A running
+// request refreshes its lock every third of it, however long it takes.
+var idempotencyLockTTL = 30 * time.Second
+
+// idempotentResponse is what gets stored in Redis for a finished request
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// releaseLock deletes the lock only if this request still owns it
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// extendLock resets the lock's expiry only if this request still owns it
+var extendLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// Idempotency makes POSTs safe to retry. A request carrying an Idempotency-Key is
+// fingerprinted (method, URL with its query string, body) and its response stored for ttl; a retry with
+// the same key replays that response, a retry with a different payload gets 422 and
+// a duplicate that arrives while the first is still running gets 409.
+// Keys are scoped per user, so it must run after ProtectedWithRedis.
+func Idempotency(rdb *redis.Client, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKey {
+			return services.Invalid(services.CodeInvalidParameter, "Invalid Idempotency-Key",
+				utils.ErrorDetail{Field: HeaderIdempotencyKey, Message: fmt.Sprintf("must be at most %d characters", maxIdempotencyKey)})
+		}
+
+		ctx := c.UserContext()
+		// the lock release and the stored response must outlive a request timeout
+		detached := context.WithoutCancel(ctx)
+		scope := fmt.Sprintf("idempotency:%v:%s", c.Locals("user_id"), key)
+		lockKey := scope + ":lock"
+		fingerprint := requestFingerprint(c.Method(), c.OriginalURL(), c.Body())
+
+		if raw, err := rdb.Get(ctx, scope).Bytes(); err == nil {
+			var stored idempotentResponse
+			if err := json.Unmarshal(raw, &stored); err == nil {
+				return replay(c, stored, fingerprint)
+			}
+		} else if err != redis.Nil {
+			return err
+		}
+
+		token := fingerprint + ":" + randomToken()
+		if err := acquireLock(ctx, rdb, lockKey, token, fingerprint); err != nil {
+			if err == errKeyReused {
+				return keyReused(c)
+			}
+			return err
+		}
+		defer releaseLock.Run(detached, rdb, []string{lockKey}, token)
+		defer holdLock(detached, rdb, lockKey, token)()
+
+		// the first request may have finished between the GET and the lock
+		if raw, err := rdb.Get(ctx, scope).Bytes(); err == nil {
+			var stored idempotentResponse
+			if err := json.Unmarshal(raw, &stored); err == nil {
+				return replay(c, stored, fingerprint)
+			}
+		}
+
+		// errors are rendered here so rejected requests are stored and replayed as well
+		if err := c.Next(); err != nil {
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		// server errors are not stored so the client can retry them
+		status := c.Response().StatusCode()
+		if status >= 500 {
+			return nil
+		}
+		raw, _ := json.Marshal(idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        c.Response().Body(),
+		})
+		if err := rdb.Set(detached, scope, raw, ttl).Err(); err != nil {
+			// the response is already rendered, a retry runs the request again
+			slog.ErrorContext(ctx, "Idempotent response could not be stored", "key", key, "error", err)
+		}
+		return nil
+	}
+}
+
+var errKeyReused = errors.New("idempotency key reused")
+
+// acquireLock takes the lock of a key for token. A lock held for the same fingerprint
+// is a 409, one held for another payload is errKeyReused. A lock that expires between
+// the SETNX and the GET is free again, so it is tried once more rather than reported.
+func acquireLock(ctx context.Context, rdb *redis.Client, lockKey, token, fingerprint string) error {
+	for attempt := 0; ; attempt++ {
+		acquired, err := rdb.SetNX(ctx, lockKey, token, idempotencyLockTTL).Result()
+		if err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		holder, err := rdb.Get(ctx, lockKey).Result()
+		if err == redis.Nil && attempt < 2 {
+			continue
+		}
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil && !strings.HasPrefix(holder, fingerprint) {
+			return errKeyReused
+		}
+		return services.Conflict(services.CodeConflict, "A request with this Idempotency-Key is still in progress")
+	}
+}
+
+// holdLock keeps the lock alive until the returned stop is called, so a duplicate
+// still gets 409 while a request that outlives idempotencyLockTTL is running
+func holdLock(ctx context.Context, rdb *redis.Client, key, token string) (stop func()) {
+	ttl := idempotencyLockTTL
+	ticker := time.NewTicker(ttl / 3)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				extendLock.Run(ctx, rdb, []string{key}, token, ttl.Milliseconds())
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func requestFingerprint(method, url string, body []byte) string {
+	sum := sha256.Sum256([]byte(method + " " + url + "\n" + string(body)))
+	return hex.EncodeToString(sum[:])
+}
+
+func replay(c *fiber.Ctx, stored idempotentResponse, fingerprint string) error {
+	if stored.Fingerprint != fingerprint {
+		return keyReused(c)
+	}
+	c.Set(HeaderReplayed, "true")
+	c.Set(fiber.HeaderContentType, stored.ContentType)
+	return c.Status(stored.Status).Send(stored.Body)
+}
+
+func keyReused(c *fiber.Ctx) error {
+	return services.Unprocessable(services.CodeIdempotencyMismatch, "Idempotency-Key was already used with a different request",
+		utils.ErrorDetail{Field: HeaderIdempotencyKey, Message: "use a new key for a different payload"})
+}
+
+func randomToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
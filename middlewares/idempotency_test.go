@@ -0,0 +1,170 @@
+package middlewares
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func idempotencyApp(t *testing.T) (*fiber.App, *int, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	calls := 0
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/orders", Idempotency(rdb, time.Hour), func(c *fiber.Ctx) error {
+		calls++
+		return c.Status(201).JSON(fiber.Map{"order_id": calls})
+	})
+	return app, &calls, mr
+}
+
+func postOrder(t *testing.T, app *fiber.App, key, body string) (int, string, string) {
+	return post(t, app, "/orders", key, body)
+}
+
+func post(t *testing.T, app *fiber.App, target, key, body string) (int, string, string) {
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	raw, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(raw), resp.Header.Get(HeaderReplayed)
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	app, calls, _ := idempotencyApp(t)
+
+	status, body, replayed := postOrder(t, app, "abc", `{"ship_name":"x"}`)
+	assert.Equal(t, 201, status)
+	assert.Empty(t, replayed)
+
+	status, again, replayed := postOrder(t, app, "abc", `{"ship_name":"x"}`)
+	assert.Equal(t, 201, status)
+	assert.Equal(t, body, again)
+	assert.Equal(t, "true", replayed)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_DifferentPayload(t *testing.T) {
+	app, calls, _ := idempotencyApp(t)
+
+	postOrder(t, app, "abc", `{"ship_name":"x"}`)
+	status, _, _ := postOrder(t, app, "abc", `{"ship_name":"y"}`)
+
+	assert.Equal(t, 422, status)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_QueryStringIsPartOfTheRequest(t *testing.T) {
+	app, calls, _ := idempotencyApp(t)
+
+	status, _, _ := post(t, app, "/orders?dry_run=true", "abc", `{"ship_name":"x"}`)
+	assert.Equal(t, 201, status)
+	status, _, replayed := post(t, app, "/orders", "abc", `{"ship_name":"x"}`)
+	assert.Equal(t, 422, status)
+	assert.Empty(t, replayed)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_StoresResponsesPastTheDeadline(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	calls := 0
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/orders", func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), 20*time.Millisecond)
+		defer cancel()
+		c.SetUserContext(ctx)
+		return c.Next()
+	}, Idempotency(rdb, time.Hour), func(c *fiber.Ctx) error {
+		calls++
+		<-c.UserContext().Done()
+		return c.SendStatus(201)
+	})
+
+	status, _, _ := postOrder(t, app, "late", `{}`)
+	assert.Equal(t, 201, status)
+	assert.False(t, mr.Exists("idempotency:<nil>:late:lock"))
+
+	status, _, replayed := postOrder(t, app, "late", `{}`)
+	assert.Equal(t, 201, status)
+	assert.Equal(t, "true", replayed)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	app, calls, mr := idempotencyApp(t)
+
+	// another request with the same key still holds the lock
+	lock := "idempotency:<nil>:abc:lock"
+	mr.Set(lock, requestFingerprint("POST", "/orders", []byte(`{"ship_name":"x"}`))+":token")
+	status, _, _ := postOrder(t, app, "abc", `{"ship_name":"x"}`)
+	assert.Equal(t, 409, status)
+
+	mr.Set(lock, requestFingerprint("POST", "/orders", []byte(`{"ship_name":"y"}`))+":token")
+	status, _, _ = postOrder(t, app, "abc", `{"ship_name":"x"}`)
+	assert.Equal(t, 422, status)
+	assert.Equal(t, 0, *calls)
+}
+
+func TestIdempotency_WithoutKey(t *testing.T) {
+	app, calls, _ := idempotencyApp(t)
+
+	postOrder(t, app, "", `{}`)
+	postOrder(t, app, "", `{}`)
+
+	assert.Equal(t, 2, *calls)
+}
+
+func TestIdempotency_LongRequestKeepsItsLock(t *testing.T) {
+	previous := idempotencyLockTTL
+	idempotencyLockTTL = 300 * time.Millisecond
+	t.Cleanup(func() { idempotencyLockTTL = previous })
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	release := make(chan struct{})
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/orders", Idempotency(rdb, time.Hour), func(c *fiber.Ctx) error {
+		<-release
+		return c.SendStatus(201)
+	})
+
+	first := make(chan int)
+	go func() {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{}`))
+		req.Header.Set(HeaderIdempotencyKey, "slow")
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		first <- resp.StatusCode
+	}()
+
+	// miniredis only expires keys when told to; without a refresh the lock would be
+	// gone after the second round
+	lock := "idempotency:<nil>:slow:lock"
+	require.Eventually(t, func() bool { return mr.Exists(lock) }, time.Second, 10*time.Millisecond)
+	for range 4 {
+		time.Sleep(150 * time.Millisecond)
+		mr.FastForward(200 * time.Millisecond)
+		require.True(t, mr.Exists(lock))
+	}
+	status, _, _ := postOrder(t, app, "slow", `{}`)
+	assert.Equal(t, 409, status)
+
+	close(release)
+	assert.Equal(t, 201, <-first)
+	assert.False(t, mr.Exists(lock))
+}
@@ -1,8 +1,8 @@
 package middlewares
 
 import (
-	redisclient "github.com/aronipurwanto/go-api-northwind/internal/redis"
-	"github.com/aronipurwanto/go-api-northwind/utils"
+	redisclient "github.com/azizmasr1001/go-api-northwind/internal/redis"
+	"github.com/azizmasr1001/go-api-northwind/services"
 	"github.com/gofiber/fiber/v2"
 	jwtware "github.com/gofiber/jwt/v3"
 	"github.com/golang-jwt/jwt/v5"
@@ -15,17 +15,14 @@ func Protected(jwtSecret string) fiber.Handler {
 		SigningKey: []byte(jwtSecret),
 		ContextKey: "user", // this is required to later retrieve token
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"status":  "error",
-				"code":    401,
-				"message": "Unauthorized",
-			})
+			return services.Unauthorized(services.CodeUnauthorized, "Unauthorized")
 		},
 		SuccessHandler: func(c *fiber.Ctx) error {
 			token := c.Locals("user").(*jwt.Token)
 			claims := token.Claims.(jwt.MapClaims)
 			c.Locals("user_id", claims["user_id"])
 			c.Locals("first_name", claims["firstName"])
+			c.Locals("role", claims["role"])
 			return c.Next()
 		},
 	})
@@ -35,16 +32,16 @@ func ProtectedWithRedis(jwtSecret string, rdb *redis.Client) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		header := c.Get("Authorization")
 		if header == "" || !strings.HasPrefix(header, "Bearer ") {
-			return utils.ErrorResponse(c, 401, "Missing or invalid token", nil)
+			return services.Unauthorized(services.CodeUnauthorized, "Missing or invalid token")
 		}
 
 		tokenStr := strings.TrimPrefix(header, "Bearer ")
-		blacklisted, err := redisclient.IsTokenBlacklisted(c.Context(), rdb, tokenStr)
+		blacklisted, err := redisclient.IsTokenBlacklisted(c.UserContext(), rdb, tokenStr)
 		if err != nil {
-			return utils.ErrorResponse(c, 500, "Redis error", []utils.ErrorDetail{{Message: err.Error()}})
+			return err
 		}
 		if blacklisted {
-			return utils.ErrorResponse(c, 401, "Token has been revoked", nil)
+			return services.Unauthorized(services.CodeTokenRevoked, "Token has been revoked")
 		}
 
 		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
@@ -54,12 +51,26 @@ func ProtectedWithRedis(jwtSecret string, rdb *redis.Client) fiber.Handler {
 			return []byte(jwtSecret), nil
 		})
 		if err != nil || !token.Valid {
-			return utils.ErrorResponse(c, 401, "Invalid token", nil)
+			return services.Unauthorized(services.CodeInvalidToken, "Invalid token")
 		}
 
 		claims := token.Claims.(jwt.MapClaims)
 		c.Locals("user_id", claims["user_id"])
 		c.Locals("first_name", claims["firstName"])
+		c.Locals("role", claims["role"])
+		return c.Next()
+	}
+}
+
+// TokenFromQuery copies ?<param>= into the Authorization header when none is sent,
+// for clients such as browser EventSource that cannot set request headers
+func TokenFromQuery(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			if token := c.Query(param); token != "" {
+				c.Request().Header.Set("Authorization", "Bearer "+token)
+			}
+		}
 		return c.Next()
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 
@@ -27,18 +28,14 @@ func generateToken(userID int, firstName string, expMinutes int) string {
 	return signed
 }
 
-func setupRedisClient() *redis.Client {
-	rdb := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379", // Pastikan Redis aktif saat test
-		DB:   1,                // Gunakan DB test
-	})
-	_ = rdb.FlushDB(context.Background()).Err()
-	return rdb
+func setupRedisClient(t *testing.T) *redis.Client {
+	mr := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: mr.Addr()})
 }
 
 func TestProtectedWithRedis_ValidToken(t *testing.T) {
-	app := fiber.New()
-	rdb := setupRedisClient()
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	rdb := setupRedisClient(t)
 	token := generateToken(1, "TestUser", 15)
 
 	app.Use(ProtectedWithRedis(jwtSecret, rdb))
@@ -55,8 +52,8 @@ func TestProtectedWithRedis_ValidToken(t *testing.T) {
 }
 
 func TestProtectedWithRedis_InvalidToken(t *testing.T) {
-	app := fiber.New()
-	rdb := setupRedisClient()
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	rdb := setupRedisClient(t)
 
 	app.Use(ProtectedWithRedis(jwtSecret, rdb))
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -72,8 +69,8 @@ func TestProtectedWithRedis_InvalidToken(t *testing.T) {
 }
 
 func TestProtectedWithRedis_BlacklistedToken(t *testing.T) {
-	app := fiber.New()
-	rdb := setupRedisClient()
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	rdb := setupRedisClient(t)
 
 	token := generateToken(2, "BlockedUser", 10)
 	_ = rdb.Set(context.Background(), token, "blacklisted", 10*time.Minute).Err()
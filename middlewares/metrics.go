@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute labels requests no route answered, so scanners cannot blow up the label set
+const unmatchedRoute = "unmatched"
+
+// Metrics counts and times requests by route template and status. It must run before
+// AccessLog, which renders errors, so the status is the one the client gets.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		own := c.Route()
+		err := c.Next()
+
+		// a request stopped by group middleware keeps the group prefix as its route,
+		// one still on the route of this middleware matched nothing else
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		if c.Route() == own {
+			route = unmatchedRoute
+		}
+		// fiber's strings point into buffers it reuses, the registry keeps its labels
+		labels := []string{strings.Clone(c.Method()), strings.Clone(route), strconv.Itoa(status)}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -1,8 +1,11 @@
 package middlewares
 
 import (
-	"github.com/gofiber/fiber/v2"
 	"strconv"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
 )
 
 func ValidateQueryPagination(defaultPage, defaultLimit int) fiber.Handler {
@@ -14,15 +17,9 @@ func ValidateQueryPagination(defaultPage, defaultLimit int) fiber.Handler {
 		limit, err2 := strconv.Atoi(limitStr)
 
 		if err1 != nil || err2 != nil || page <= 0 || limit <= 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status":  "error",
-				"code":    400,
-				"message": "Invalid pagination parameters",
-				"errors": []fiber.Map{
-					{"field": "page", "message": "must be a positive number"},
-					{"field": "limit", "message": "must be a positive number"},
-				},
-			})
+			return services.Invalid(services.CodeInvalidParameter, "Invalid pagination parameters",
+				utils.ErrorDetail{Field: "page", Message: "must be a positive number"},
+				utils.ErrorDetail{Field: "limit", Message: "must be a positive number"})
 		}
 
 		// Simpan ke context
@@ -1,8 +1,11 @@
 package middlewares
 
 import (
-	"github.com/gofiber/fiber/v2"
 	"strconv"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"github.com/gofiber/fiber/v2"
 )
 
 func ValidateIDParam(paramName string) fiber.Handler {
@@ -10,14 +13,8 @@ func ValidateIDParam(paramName string) fiber.Handler {
 		idStr := c.Params(paramName)
 		id, err := strconv.Atoi(idStr)
 		if err != nil || id <= 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"status":  "error",
-				"code":    400,
-				"message": "Invalid ID parameter",
-				"errors": []fiber.Map{
-					{"field": paramName, "message": "must be a valid positive number"},
-				},
-			})
+			return services.Invalid(services.CodeInvalidParameter, "Invalid ID parameter",
+				utils.ErrorDetail{Field: paramName, Message: "must be a valid positive number"})
 		}
 
 		// Simpan ID hasil parsing agar bisa dipakai di controller
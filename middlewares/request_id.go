@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"github.com/azizmasr1001/go-api-northwind/internal/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength keeps a client from stuffing the logs through the header
+const maxRequestIDLength = 128
+
+// RequestID keeps the X-Request-ID the client sent, or makes one up, echoes it in the
+// response and puts it in c.Locals("request_id"), c.Context() and c.UserContext()
+// so logs written further down the chain carry it
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(HeaderRequestID, id)
+		c.Locals("request_id", id)
+		c.Context().SetUserValue(logging.RequestIDKey, id)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+// validRequestID accepts printable ASCII without spaces, up to maxRequestIDLength
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/azizmasr1001/go-api-northwind/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole lets the request through only when the role claim set by the JWT
+// middleware is one of roles. It must run after Protected or ProtectedWithRedis.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+		return services.Forbidden(services.CodeForbidden, "Requires role: "+strings.Join(roles, " or "))
+	}
+}
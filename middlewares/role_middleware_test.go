@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Delete("/purge", func(c *fiber.Ctx) error {
+		c.Locals("role", c.Get("X-Role"))
+		return c.Next()
+	}, RequireRole("admin"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	for role, want := range map[string]int{"admin": 204, "user": 403, "": 403} {
+		req := httptest.NewRequest("DELETE", "/purge", nil)
+		req.Header.Set("X-Role", role)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, want, resp.StatusCode, "role %q", role)
+	}
+}
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Timeout gives each request a deadline of timeout in c.UserContext(), the context
+// services and repositories pass to db.WithContext, so a slow query is cancelled on
+// the database instead of running after the client gave up. ErrorHandler answers a
+// request that ran past its deadline with 504. A timeout of 0 disables it.
+//
+// It must run after Tracing so the deadline context keeps the request's span.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if timeout <= 0 {
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
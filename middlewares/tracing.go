@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/tracing"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing opens a server span per request, continuing the trace of an incoming
+// traceparent header, and puts it in c.UserContext() so the service, GORM and Redis
+// spans below become its children. Like Metrics it must run before AccessLog so the
+// span gets the status the client gets.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parent := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		// the exporter sends spans after the request, when fiber has reused its buffers
+		method := strings.Clone(c.Method())
+		ctx, span := tracing.Tracer().Start(parent, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(strings.Clone(c.Path())),
+				semconv.ClientAddress(strings.Clone(c.IP())),
+				semconv.UserAgentOriginal(strings.Clone(c.Get(fiber.HeaderUserAgent))),
+			))
+		defer span.End()
+		if id, ok := c.Locals("request_id").(string); ok {
+			span.SetAttributes(attribute.String("http.request.id", id))
+		}
+
+		own := c.Route()
+		c.SetUserContext(ctx)
+		err := c.Next()
+
+		// unmatched requests keep the method alone as the name, see Metrics
+		if c.Route() != own {
+			route := strings.Clone(c.Route().Path)
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		status := c.Response().StatusCode()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		return err
+	}
+}
+
+// headerCarrier reads the W3C trace context headers of the request
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	// baggage keeps substrings of the header in the context
+	return strings.Clone(h.c.Get(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h.c.GetReqHeaders()))
+	for key := range h.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID = "00f067aa0ba902b7"
+)
+
+func tracingApp(t *testing.T) (*fiber.App, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RequestID(), Tracing(), AccessLog())
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		// the handler sees the server span, so its own spans become children
+		return c.SendString(trace.SpanContextFromContext(c.UserContext()).SpanID().String())
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return errors.New("database is down")
+	})
+	return app, recorder
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing_ContinuesIncomingTrace(t *testing.T) {
+	app, recorder := tracingApp(t)
+
+	req := httptest.NewRequest("GET", "/orders/10248", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	req.Header.Set(HeaderRequestID, "abc-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /orders/:id", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, parentSpanID, span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+	assert.Equal(t, "/orders/:id", spanAttr(span, "http.route").AsString())
+	assert.Equal(t, "/orders/10248", spanAttr(span, "url.path").AsString())
+	assert.Equal(t, int64(200), spanAttr(span, "http.response.status_code").AsInt64())
+	assert.Equal(t, "abc-123", spanAttr(span, "http.request.id").AsString())
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, span.SpanContext().SpanID().String(), string(body))
+}
+
+func TestTracing_ErrorsAndUnmatched(t *testing.T) {
+	app, recorder := tracingApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/boom", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest("GET", "/wp-login.php", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "GET /boom", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, int64(500), spanAttr(spans[0], "http.response.status_code").AsInt64())
+	assert.False(t, spans[0].Parent().IsValid(), "no traceparent starts a new trace")
+
+	assert.Equal(t, "GET", spans[1].Name(), "an unmatched path must not become a span name")
+	assert.Equal(t, attribute.INVALID, spanAttr(spans[1], "http.route").Type())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
@@ -0,0 +1,33 @@
+package models
+
+import "encoding/json"
+
+// BatchOperation is one request replayed by POST /api/batch, e.g.
+// {"method": "PATCH", "path": "/products/7", "body": {"unit_price": 19}}
+type BatchOperation struct {
+	Method string          `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE"`
+	Path   string          `json:"path" validate:"required,startswith=/"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"` // all operations in one transaction, stop at the first failure
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,dive"`
+}
+
+// BatchOperationResult carries the status and the usual response envelope of one operation
+type BatchOperationResult struct {
+	Index  int             `json:"index"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+type BatchResult struct {
+	Atomic    bool                   `json:"atomic"`
+	Committed bool                   `json:"committed"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Results   []BatchOperationResult `json:"results"`
+}
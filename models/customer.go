@@ -0,0 +1,17 @@
+package models
+
+type Customer struct {
+	CustomerID   string `gorm:"column:CustomerID;primaryKey" json:"customer_id"`
+	CompanyName  string `gorm:"column:CompanyName" json:"company_name"`
+	ContactName  string `gorm:"column:ContactName" json:"contact_name"`
+	ContactTitle string `gorm:"column:ContactTitle" json:"contact_title"`
+	Address      string `gorm:"column:Address" json:"address"`
+	City         string `gorm:"column:City" json:"city"`
+	Region       string `gorm:"column:Region" json:"region"`
+	PostalCode   string `gorm:"column:PostalCode" json:"postal_code"`
+	Country      string `gorm:"column:Country" json:"country"`
+	Phone        string `gorm:"column:Phone" json:"phone"`
+	Fax          string `gorm:"column:Fax" json:"fax"`
+}
+
+func (Customer) TableName() string { return "Customers" }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type Dashboard struct {
+	RevenueThisMonth  float64       `json:"revenue_this_month"`
+	RevenueLastMonth  float64       `json:"revenue_last_month"`
+	OrderCount        int           `json:"order_count"`
+	AverageOrderValue float64       `json:"average_order_value"`
+	TopProducts       []TopProduct  `json:"top_products"`
+	TopCustomers      []TopCustomer `json:"top_customers"`
+	LateShipments     int           `json:"late_shipments"`
+	LowStockCount     int           `json:"low_stock_count"`
+	GeneratedAt       time.Time     `json:"generated_at"`
+}
+
+type TopProduct struct {
+	ProductID   int     `gorm:"column:product_id" json:"product_id"`
+	ProductName string  `gorm:"column:product_name" json:"product_name"`
+	Quantity    int     `gorm:"column:quantity" json:"quantity"`
+	Revenue     float64 `gorm:"column:revenue" json:"revenue"`
+}
+
+type TopCustomer struct {
+	CustomerID  string  `gorm:"column:customer_id" json:"customer_id"`
+	CompanyName string  `gorm:"column:company_name" json:"company_name"`
+	OrderCount  int     `gorm:"column:order_count" json:"order_count"`
+	Revenue     float64 `gorm:"column:revenue" json:"revenue"`
+}
@@ -20,4 +20,7 @@ type Employee struct {
 	Notes           string     `gorm:"column:Notes" json:"notes"`
 	ReportsTo       *int       `gorm:"column:ReportsTo" json:"reports_to"`
 	PhotoPath       string     `gorm:"column:PhotoPath" json:"photo_path"`
+	SoftDelete      `csv:"-"`
 }
+
+func (Employee) TableName() string { return "Employees" }
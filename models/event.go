@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Domain event types published by the services
+const (
+	EventOrderCreated        = "order.created"
+	EventOrderUpdated        = "order.updated"
+	EventOrderShipped        = "order.shipped"
+	EventProductStockChanged = "product.stock_changed"
+	EventProductOutOfStock   = "product.out_of_stock"
+	EventUserRegistered      = "user.registered"
+)
+
+// EventTypes lists every event a webhook or stream client can subscribe to
+var EventTypes = []string{
+	EventOrderCreated, EventOrderUpdated, EventOrderShipped,
+	EventProductStockChanged, EventProductOutOfStock, EventUserRegistered,
+}
+
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
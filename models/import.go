@@ -0,0 +1,24 @@
+package models
+
+type ImportOptions struct {
+	DryRun bool   // validate and run the writes, then roll everything back
+	Atomic bool   // all rows in one transaction, or only the valid rows
+	Key    string // "id" or "name", which existing row an input row updates
+}
+
+type ImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportResult struct {
+	Total     int           `json:"total"`
+	Created   int           `json:"created"`
+	Updated   int           `json:"updated"`
+	Failed    int           `json:"failed"`
+	DryRun    bool          `json:"dry_run"`
+	Atomic    bool          `json:"atomic"`
+	Committed bool          `json:"committed"`
+	Errors    []ImportError `json:"errors,omitempty"`
+}
@@ -17,8 +17,11 @@ type Order struct {
 	ShipRegion     string     `gorm:"column:ShipRegion" json:"ship_region"`
 	ShipPostalCode string     `gorm:"column:ShipPostalCode" json:"ship_postal_code"`
 	ShipCountry    string     `gorm:"column:ShipCountry" json:"ship_country"`
+	SoftDelete     `csv:"-"`
 }
 
+func (Order) TableName() string { return "Orders" }
+
 type OrderDetail struct {
 	OrderID   int     `gorm:"column:OrderID;primaryKey" json:"order_id"`
 	ProductID int     `gorm:"column:ProductID;primaryKey" json:"product_id"`
@@ -26,3 +29,12 @@ type OrderDetail struct {
 	Quantity  int     `gorm:"column:Quantity" json:"quantity"`
 	Discount  float64 `gorm:"column:Discount" json:"discount"`
 }
+
+// OrderInput is the body of an order create or update in every API, the order with its lines
+type OrderInput struct {
+	Order
+	OrderDetails []OrderDetail `json:"order_details" validate:"required,min=1,dive"`
+}
+
+// TableName is the Northwind name, space included; GORM quotes it for every dialect
+func (OrderDetail) TableName() string { return "Order Details" }
@@ -11,4 +11,7 @@ type Product struct {
 	UnitsOnOrder    int     `gorm:"column:UnitsOnOrder" json:"units_on_order"`
 	ReorderLevel    int     `gorm:"column:ReorderLevel" json:"reorder_level"`
 	Discontinued    bool    `gorm:"column:Discontinued" json:"discontinued"`
+	SoftDelete      `csv:"-"`
 }
+
+func (Product) TableName() string { return "Products" }
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// DateRange bounds a report; a zero From or To leaves that side open
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+type CategorySales struct {
+	CategoryName string  `gorm:"column:category_name" json:"category_name"`
+	Year         int     `gorm:"column:year" json:"year"`
+	TotalSales   float64 `gorm:"column:total_sales" json:"total_sales"`
+}
+
+type ProductSales struct {
+	ProductID    int     `gorm:"column:product_id" json:"product_id"`
+	ProductName  string  `gorm:"column:product_name" json:"product_name"`
+	CategoryName string  `gorm:"column:category_name" json:"category_name"`
+	TotalSales   float64 `gorm:"column:total_sales" json:"total_sales"`
+}
+
+type EmployeeSales struct {
+	EmployeeID int     `gorm:"column:employee_id" json:"employee_id"`
+	FirstName  string  `gorm:"column:first_name" json:"first_name"`
+	LastName   string  `gorm:"column:last_name" json:"last_name"`
+	Country    string  `gorm:"column:country" json:"country"`
+	OrderCount int     `gorm:"column:order_count" json:"order_count"`
+	TotalSales float64 `gorm:"column:total_sales" json:"total_sales"`
+}
+
+type QuarterlyOrders struct {
+	Year       int     `gorm:"column:year" json:"year"`
+	Quarter    int     `gorm:"column:quarter" json:"quarter"`
+	OrderCount int     `gorm:"column:order_count" json:"order_count"`
+	TotalSales float64 `gorm:"column:total_sales" json:"total_sales"`
+}
+
+type OrderSubtotal struct {
+	OrderID  int     `gorm:"column:order_id" json:"order_id"`
+	Subtotal float64 `gorm:"column:subtotal" json:"subtotal"`
+}
+
+type ExpensiveProduct struct {
+	ProductID   int     `gorm:"column:product_id" json:"product_id"`
+	ProductName string  `gorm:"column:product_name" json:"product_name"`
+	UnitPrice   float64 `gorm:"column:unit_price" json:"unit_price"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type Shipper struct {
+	ShipperID   int    `gorm:"column:ShipperID;primaryKey" json:"shipper_id"`
+	CompanyName string `gorm:"column:CompanyName" json:"company_name"`
+	Phone       string `gorm:"column:Phone" json:"phone"`
+}
+
+func (Shipper) TableName() string { return "Shippers" }
@@ -0,0 +1,10 @@
+package models
+
+import "gorm.io/gorm"
+
+// SoftDelete is embedded by models whose Delete moves rows to the trash.
+// GORM leaves rows with DeletedAt set out of every query unless Unscoped is used.
+type SoftDelete struct {
+	DeletedAt gorm.DeletedAt `gorm:"column:DeletedAt;index" json:"deleted_at" swaggertype:"string" format:"date-time"`
+	DeletedBy *int           `gorm:"column:DeletedBy" json:"deleted_by"`
+}
@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
 type User struct {
 	UserID       int       `gorm:"column:UserID;primaryKey" json:"user_id"`
 	Username     string    `gorm:"column:Username" json:"username" validate:"required"`
@@ -14,6 +19,8 @@ type User struct {
 	UpdatedAt    time.Time `gorm:"column:UpdatedAt" json:"updated_at"`
 }
 
+func (User) TableName() string { return "Users" }
+
 type RegisterRequest struct {
 	Username   string `json:"username" validate:"required"`
 	Email      string `json:"email" validate:"required,email"`
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Delivery states, dead deliveries have used up every retry
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID        int       `gorm:"column:ID;primaryKey" json:"id"`
+	UserID    int       `gorm:"column:UserID" json:"user_id"`
+	URL       string    `gorm:"column:URL" json:"url"`
+	Events    []string  `gorm:"column:Events;serializer:json" json:"events"`
+	Secret    string    `gorm:"column:Secret" json:"-"`
+	Active    bool      `gorm:"column:Active" json:"active"`
+	CreatedAt time.Time `gorm:"column:CreatedAt" json:"created_at"`
+}
+
+// Wants reports whether the subscription listens to eventType
+func (s WebhookSubscription) Wants(eventType string) bool {
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookSubscriptionInput struct {
+	URL    string   `json:"url" validate:"required,url,startswith=http"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=order.created order.updated order.shipped product.stock_changed product.out_of_stock user.registered"`
+}
+
+// WebhookSubscriptionCreated is only returned once, it is the one place the secret is shown
+type WebhookSubscriptionCreated struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+type WebhookDelivery struct {
+	ID             int        `gorm:"column:ID;primaryKey" json:"id"`
+	SubscriptionID int        `gorm:"column:SubscriptionID" json:"subscription_id"`
+	EventID        string     `gorm:"column:EventID" json:"event_id"`
+	EventType      string     `gorm:"column:EventType" json:"event_type"`
+	Payload        string     `gorm:"column:Payload" json:"payload"`
+	Status         string     `gorm:"column:Status" json:"status"`
+	Attempts       int        `gorm:"column:Attempts" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"column:NextAttemptAt" json:"next_attempt_at"`
+	LockedUntil    *time.Time `gorm:"column:LockedUntil" json:"-"`
+	ResponseStatus *int       `gorm:"column:ResponseStatus" json:"response_status"`
+	LastError      string     `gorm:"column:LastError" json:"last_error"`
+	CreatedAt      time.Time  `gorm:"column:CreatedAt" json:"created_at"`
+	DeliveredAt    *time.Time `gorm:"column:DeliveredAt" json:"delivered_at"`
+
+	Subscription *WebhookSubscription `gorm:"foreignKey:SubscriptionID" json:"-"`
+}
@@ -1,17 +1,18 @@
 package repositories
 
 import (
+	"context"
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"gorm.io/gorm"
 )
 
 type AuthRepository interface {
-	FindByUsername(username string) (*models.User, error)
-	FindByEmail(email string) (*models.User, error)
-	CreateUser(user *models.User) error
-	CheckUsernameExists(username string) (bool, error)
-	CheckEmailExists(email string) (bool, error)
-	UpdatePassword(user *models.User) error
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	CheckUsernameExists(ctx context.Context, username string) (bool, error)
+	CheckEmailExists(ctx context.Context, email string) (bool, error)
+	UpdatePassword(ctx context.Context, user *models.User) error
 }
 
 type authRepositoryImpl struct {
@@ -22,36 +23,36 @@ func NewAuthRepository(db *gorm.DB) AuthRepository {
 	return &authRepositoryImpl{db: db}
 }
 
-func (r *authRepositoryImpl) FindByUsername(username string) (*models.User, error) {
+func (r *authRepositoryImpl) FindByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	err := r.db.
-		Where("Username = ? OR Email = ?", username, username).
+	err := r.db.WithContext(ctx).
+		Where(`"Username" = ? OR "Email" = ?`, username, username).
 		First(&user).Error
 	return &user, err
 }
 
-func (r *authRepositoryImpl) FindByEmail(email string) (*models.User, error) {
+func (r *authRepositoryImpl) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("Email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where(`"Email" = ?`, email).First(&user).Error
 	return &user, err
 }
 
-func (r *authRepositoryImpl) CreateUser(user *models.User) error {
-	return r.db.Create(user).Error
+func (r *authRepositoryImpl) CreateUser(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
-func (r *authRepositoryImpl) CheckUsernameExists(username string) (bool, error) {
+func (r *authRepositoryImpl) CheckUsernameExists(ctx context.Context, username string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Where("Username = ?", username).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where(`"Username" = ?`, username).Count(&count).Error
 	return count > 0, err
 }
 
-func (r *authRepositoryImpl) CheckEmailExists(email string) (bool, error) {
+func (r *authRepositoryImpl) CheckEmailExists(ctx context.Context, email string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Where("Email = ?", email).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where(`"Email" = ?`, email).Count(&count).Error
 	return count > 0, err
 }
 
-func (r *authRepositoryImpl) UpdatePassword(user *models.User) error {
-	return r.db.Model(user).Update("PasswordHash", user.PasswordHash).Error
+func (r *authRepositoryImpl) UpdatePassword(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Model(user).Update("PasswordHash", user.PasswordHash).Error
 }
@@ -1,52 +1,90 @@
 package repositories
 
 import (
+	"context"
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"gorm.io/gorm"
 )
 
 type CategoryRepository interface {
-	GetAll() ([]models.Category, error)
-	GetByID(id int) (*models.Category, error)
-	Create(cat *models.Category) (models.Category, error)
-	Update(cat *models.Category) (models.Category, error)
-	Delete(id int) error
+	GetAll(ctx context.Context, columns ...string) ([]models.Category, error)
+	GetByID(ctx context.Context, id int, columns ...string) (*models.Category, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.Category, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Category) error) error
+	Create(ctx context.Context, cat *models.Category) (models.Category, error)
+	Update(ctx context.Context, cat *models.Category) (models.Category, error)
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Category, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []models.Category, opts models.ImportOptions) ([]ImportOutcome, error)
+	// Locked runs fn against a repository bound to one transaction that holds the
+	// write lock of category id
+	Locked(ctx context.Context, id int, fn func(CategoryRepository) error) error
 }
 
 type categoryRepo struct {
 	db *gorm.DB
+	trash[models.Category]
 }
 
 func NewCategoryRepository(db *gorm.DB) CategoryRepository {
-	return &categoryRepo{db}
+	return &categoryRepo{db: db, trash: trash[models.Category]{db: db, key: "CategoryID"}}
 }
 
-func (r *categoryRepo) GetAll() ([]models.Category, error) {
+func (r *categoryRepo) GetAll(ctx context.Context, columns ...string) ([]models.Category, error) {
 	var categories []models.Category
-	err := r.db.Find(&categories).Error
+	err := selectColumns(r.db.WithContext(ctx), columns).Find(&categories).Error
 	return categories, err
 }
 
-func (r *categoryRepo) GetByID(id int) (*models.Category, error) {
+func (r *categoryRepo) StreamAll(ctx context.Context, columns []string, fn func(models.Category) error) error {
+	return streamRows(r.db.WithContext(ctx), columns, fn)
+}
+
+func (r *categoryRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.WithContext(ctx).Find(&categories, ids).Error
+	return categories, err
+}
+
+func (r *categoryRepo) GetByID(ctx context.Context, id int, columns ...string) (*models.Category, error) {
 	var cat models.Category
-	err := r.db.First(&cat, id).Error
+	err := selectColumns(r.db.WithContext(ctx), columns).First(&cat, id).Error
 	return &cat, err
 }
 
-func (r *categoryRepo) Create(cat *models.Category) (models.Category, error) {
-	if err := r.db.Create(cat).Error; err != nil {
+func (r *categoryRepo) Create(ctx context.Context, cat *models.Category) (models.Category, error) {
+	if err := r.db.WithContext(ctx).Create(cat).Error; err != nil {
 		return models.Category{}, err
 	}
 	return *cat, nil
 }
 
-func (r *categoryRepo) Update(cat *models.Category) (models.Category, error) {
-	if err := r.db.Save(cat).Error; err != nil {
+func (r *categoryRepo) Update(ctx context.Context, cat *models.Category) (models.Category, error) {
+	if err := r.db.WithContext(ctx).Save(cat).Error; err != nil {
 		return models.Category{}, err
 	}
 	return *cat, nil
 }
 
-func (r *categoryRepo) Delete(id int) error {
-	return r.db.Delete(&models.Category{}, id).Error
+func (r *categoryRepo) Locked(ctx context.Context, id int, fn func(CategoryRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockRow(tx, models.Category{}.TableName(), "CategoryID", id); err != nil {
+			return err
+		}
+		return fn(NewCategoryRepository(tx))
+	})
+}
+
+// Import upserts rows keyed by CategoryID or by name, see importRows for the transaction modes
+func (r *categoryRepo) Import(ctx context.Context, rows []models.Category, opts models.ImportOptions) ([]ImportOutcome, error) {
+	return importRows(r.db.WithContext(ctx), rows, opts, func(tx *gorm.DB, cat *models.Category) (string, error) {
+		return upsert(tx, cat, &cat.CategoryID, "CategoryID", opts.Key, map[string]interface{}{"CategoryName": cat.CategoryName})
+	})
+}
+
+// Delete moves the row to the trash, see Restore and Purge
+func (r *categoryRepo) Delete(ctx context.Context, id, deletedBy int) error {
+	return r.softDelete(r.db.WithContext(ctx), id, deletedBy)
 }
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"gorm.io/gorm"
+)
+
+type DashboardRepository interface {
+	Revenue(ctx context.Context, r models.DateRange) (float64, int, error)
+	TopProducts(ctx context.Context, r models.DateRange, limit int) ([]models.TopProduct, error)
+	TopCustomers(ctx context.Context, r models.DateRange, limit int) ([]models.TopCustomer, error)
+	LateShipments(ctx context.Context, asOf time.Time) (int, error)
+	LowStockCount(ctx context.Context) (int, error)
+}
+
+type dashboardRepo struct {
+	db *gorm.DB
+}
+
+func NewDashboardRepository(db *gorm.DB) DashboardRepository {
+	return &dashboardRepo{db}
+}
+
+func (r *dashboardRepo) orderLines(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table(`"Orders" o`).
+		Joins(`JOIN "Order Details" od ON od."OrderID" = o."OrderID"`).
+		Where(`o."DeletedAt" IS NULL`)
+}
+
+// Revenue returns the sales total and number of orders placed in the range
+func (r *dashboardRepo) Revenue(ctx context.Context, dr models.DateRange) (float64, int, error) {
+	var row struct {
+		Revenue    float64 `gorm:"column:revenue"`
+		OrderCount int     `gorm:"column:order_count"`
+	}
+	err := inRange(r.orderLines(ctx), `o."OrderDate"`, dr).
+		Select(`COALESCE(SUM(` + lineTotal + `), 0) AS revenue, COUNT(DISTINCT o."OrderID") AS order_count`).
+		Scan(&row).Error
+	return row.Revenue, row.OrderCount, err
+}
+
+func (r *dashboardRepo) TopProducts(ctx context.Context, dr models.DateRange, limit int) ([]models.TopProduct, error) {
+	// not nil, an empty month is [] in the JSON rather than null
+	rows := []models.TopProduct{}
+	err := inRange(r.orderLines(ctx), `o."OrderDate"`, dr).
+		Select(`p."ProductID" AS product_id, p."ProductName" AS product_name, SUM(od."Quantity") AS quantity, SUM(` + lineTotal + `) AS revenue`).
+		Joins(`JOIN "Products" p ON p."ProductID" = od."ProductID"`).
+		Group(`p."ProductID", p."ProductName"`).
+		Order("revenue DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *dashboardRepo) TopCustomers(ctx context.Context, dr models.DateRange, limit int) ([]models.TopCustomer, error) {
+	rows := []models.TopCustomer{}
+	err := inRange(r.orderLines(ctx), `o."OrderDate"`, dr).
+		Select(`c."CustomerID" AS customer_id, c."CompanyName" AS company_name, COUNT(DISTINCT o."OrderID") AS order_count, SUM(` + lineTotal + `) AS revenue`).
+		Joins(`JOIN "Customers" c ON c."CustomerID" = o."CustomerID"`).
+		Group(`c."CustomerID", c."CompanyName"`).
+		Order("revenue DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// LateShipments counts orders shipped after their required date, or still unshipped past it
+func (r *dashboardRepo) LateShipments(ctx context.Context, asOf time.Time) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where(`("ShippedDate" IS NOT NULL AND "ShippedDate" > "RequiredDate") OR ("ShippedDate" IS NULL AND "RequiredDate" < ?)`, asOf).
+		Count(&count).Error
+	return int(count), err
+}
+
+// LowStockCount counts active products at or below their reorder level
+func (r *dashboardRepo) LowStockCount(ctx context.Context) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Product{}).
+		Where(`"Discontinued" = ? AND "UnitsInStock" <= "ReorderLevel"`, false).
+		Count(&count).Error
+	return int(count), err
+}
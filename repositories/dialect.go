@@ -0,0 +1,33 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// Raw SQL in this package quotes identifiers with ANSI double quotes, which SQL Server,
+// PostgreSQL and SQLite accept as is and MySQL once ANSI_QUOTES is on (database.Open
+// sets it). What still differs between them is spelled out here.
+
+// yearOf extracts the year of a date column as an integer
+func yearOf(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "CAST(EXTRACT(YEAR FROM " + column + ") AS INTEGER)"
+	case "sqlite":
+		return "CAST(strftime('%Y', " + column + ") AS INTEGER)"
+	default: // sqlserver, mysql
+		return "YEAR(" + column + ")"
+	}
+}
+
+// quarterOf extracts the quarter (1-4) of a date column
+func quarterOf(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "CAST(EXTRACT(QUARTER FROM " + column + ") AS INTEGER)"
+	case "sqlite":
+		return "((CAST(strftime('%m', " + column + ") AS INTEGER) + 2) / 3)"
+	case "mysql":
+		return "QUARTER(" + column + ")"
+	default:
+		return "DATEPART(quarter, " + column + ")"
+	}
+}
@@ -1,65 +1,117 @@
 package repositories
 
 import (
+	"context"
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"gorm.io/gorm"
 )
 
 type EmployeeRepository interface {
-	GetAll() ([]models.Employee, error)
-	GetPaginated(page int, limit int) ([]models.Employee, int64, error)
-	GetByID(id int) (*models.Employee, error)
-	Create(employee *models.Employee) (models.Employee, error)
-	Update(employee *models.Employee) (models.Employee, error)
-	Delete(id int) error
+	GetAll(ctx context.Context, columns ...string) ([]models.Employee, error)
+	GetPaginated(ctx context.Context, page int, limit int, columns ...string) ([]models.Employee, int64, error)
+	GetByID(ctx context.Context, id int, columns ...string) (*models.Employee, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.Employee, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Employee) error) error
+	Create(ctx context.Context, employee *models.Employee) (models.Employee, error)
+	Update(ctx context.Context, employee *models.Employee) (models.Employee, error)
+	UpdateColumns(ctx context.Context, employee *models.Employee, columns []string) (models.Employee, error)
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Employee, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []models.Employee, opts models.ImportOptions) ([]ImportOutcome, error)
+	// Locked runs fn against a repository bound to one transaction that holds the
+	// write lock of employee id
+	Locked(ctx context.Context, id int, fn func(EmployeeRepository) error) error
 }
 
 type employeeRepo struct {
 	db *gorm.DB
+	trash[models.Employee]
 }
 
 func NewEmployeeRepository(db *gorm.DB) EmployeeRepository {
-	return &employeeRepo{db}
+	return &employeeRepo{db: db, trash: trash[models.Employee]{db: db, key: "EmployeeID"}}
 }
 
-func (r *employeeRepo) GetAll() ([]models.Employee, error) {
+func (r *employeeRepo) GetAll(ctx context.Context, columns ...string) ([]models.Employee, error) {
 	var employees []models.Employee
-	err := r.db.Find(&employees).Error
+	err := selectColumns(r.db.WithContext(ctx), columns).Find(&employees).Error
 	return employees, err
 }
 
-func (r *employeeRepo) GetByID(id int) (*models.Employee, error) {
+func (r *employeeRepo) StreamAll(ctx context.Context, columns []string, fn func(models.Employee) error) error {
+	return streamRows(r.db.WithContext(ctx), columns, fn)
+}
+
+func (r *employeeRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Employee, error) {
+	var employees []models.Employee
+	err := r.db.WithContext(ctx).Find(&employees, ids).Error
+	return employees, err
+}
+
+func (r *employeeRepo) GetByID(ctx context.Context, id int, columns ...string) (*models.Employee, error) {
 	var employee models.Employee
-	err := r.db.First(&employee, id).Error
+	err := selectColumns(r.db.WithContext(ctx), columns).First(&employee, id).Error
 	return &employee, err
 }
 
-func (r *employeeRepo) GetPaginated(page int, limit int) ([]models.Employee, int64, error) {
+func (r *employeeRepo) GetPaginated(ctx context.Context, page int, limit int, columns ...string) ([]models.Employee, int64, error) {
+	db := r.db.WithContext(ctx)
 	var employees []models.Employee
 	var total int64
 
+	if err := db.Model(&models.Employee{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	offset := (page - 1) * limit
-	if err := r.db.Model(&models.Employee{}).Count(&total).Limit(limit).Offset(offset).Find(&employees).Error; err != nil {
+	if err := selectColumns(db, columns).Limit(limit).Offset(offset).Find(&employees).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return employees, total, nil
 }
 
-func (r *employeeRepo) Create(employee *models.Employee) (models.Employee, error) {
-	if err := r.db.Create(employee).Error; err != nil {
+func (r *employeeRepo) Create(ctx context.Context, employee *models.Employee) (models.Employee, error) {
+	if err := r.db.WithContext(ctx).Create(employee).Error; err != nil {
 		return models.Employee{}, err
 	}
 	return *employee, nil
 }
 
-func (r *employeeRepo) Update(employee *models.Employee) (models.Employee, error) {
-	if err := r.db.Save(employee).Error; err != nil {
+func (r *employeeRepo) Update(ctx context.Context, employee *models.Employee) (models.Employee, error) {
+	if err := r.db.WithContext(ctx).Save(employee).Error; err != nil {
 		return models.Employee{}, err
 	}
 	return *employee, nil
 }
 
-func (r *employeeRepo) Delete(id int) error {
-	return r.db.Delete(&models.Employee{}, id).Error
+func (r *employeeRepo) Locked(ctx context.Context, id int, fn func(EmployeeRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockRow(tx, models.Employee{}.TableName(), "EmployeeID", id); err != nil {
+			return err
+		}
+		return fn(NewEmployeeRepository(tx))
+	})
+}
+
+// UpdateColumns writes only the listed columns, used by PATCH
+func (r *employeeRepo) UpdateColumns(ctx context.Context, employee *models.Employee, columns []string) (models.Employee, error) {
+	if err := updateColumns(r.db.WithContext(ctx), employee, columns); err != nil {
+		return models.Employee{}, err
+	}
+	return *employee, nil
+}
+
+// Import upserts rows keyed by EmployeeID or by name, see importRows for the transaction modes
+func (r *employeeRepo) Import(ctx context.Context, rows []models.Employee, opts models.ImportOptions) ([]ImportOutcome, error) {
+	return importRows(r.db.WithContext(ctx), rows, opts, func(tx *gorm.DB, emp *models.Employee) (string, error) {
+		return upsert(tx, emp, &emp.EmployeeID, "EmployeeID", opts.Key, map[string]interface{}{"FirstName": emp.FirstName, "LastName": emp.LastName})
+	})
+}
+
+// Delete moves the row to the trash, see Restore and Purge
+func (r *employeeRepo) Delete(ctx context.Context, id, deletedBy int) error {
+	return r.softDelete(r.db.WithContext(ctx), id, deletedBy)
 }
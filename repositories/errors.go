@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Violation is the kind of constraint a write broke
+type Violation string
+
+const (
+	// ViolationUnique: the value is already used by another row
+	ViolationUnique Violation = "unique"
+	// ViolationForeignKey: the row points at a parent that does not exist
+	ViolationForeignKey Violation = "foreign_key"
+	// ViolationReference: the row is still referenced by rows of another table
+	ViolationReference Violation = "reference"
+	// ViolationCheck: the value is rejected by a CHECK constraint
+	ViolationCheck Violation = "check"
+)
+
+// ConstraintError is a write rejected by the database. Table is the table named in
+// the server message: the table written to for unique violations, the parent for
+// foreign key violations and the referencing table for reference violations.
+type ConstraintError struct {
+	Violation  Violation
+	Constraint string
+	Table      string
+	Column     string
+	Value      string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string { return e.Err.Error() }
+
+func (e *ConstraintError) Unwrap() error { return e.Err }
+
+// translators read the constraint errors of each database, keyed by dialector name.
+// deleting tells a DELETE apart, SQLite does not say which side of a foreign key failed.
+var translators = map[string]func(err error, deleting bool) *ConstraintError{
+	"sqlserver": sqlServerConstraint,
+	"postgres":  postgresConstraint,
+	"mysql":     mysqlConstraint,
+	"sqlite":    sqliteConstraint,
+}
+
+// translateError turns the constraint errors of dialect into a *ConstraintError.
+// gorm.ErrRecordNotFound and any other error are returned as they are.
+func translateError(dialect string, err error, deleting bool) error {
+	translate, ok := translators[dialect]
+	if err == nil || !ok {
+		return err
+	}
+	if cerr := translate(err, deleting); cerr != nil {
+		cerr.Err = err
+		return cerr
+	}
+	return err
+}
+
+// sqlServerError is implemented by the SQL Server driver errors (mssql.Error)
+type sqlServerError interface {
+	SQLErrorNumber() int32
+	SQLErrorMessage() string
+}
+
+var (
+	reConstraint = regexp.MustCompile(`(?:constraint|index) ['"]([^'"]+)['"]`)
+	reTable      = regexp.MustCompile(`(?:object|table) ['"]([^'"]+)['"]`)
+	reColumn     = regexp.MustCompile(`column '([^']+)'`)
+	reValue      = regexp.MustCompile(`duplicate key value is \((.*)\)`)
+)
+
+// sqlServerConstraint reads errors 2627 and 2601 (unique) and 547 (foreign key and check)
+func sqlServerConstraint(err error, _ bool) *ConstraintError {
+	var sqlErr sqlServerError
+	if !errors.As(err, &sqlErr) {
+		return nil
+	}
+
+	msg := sqlErr.SQLErrorMessage()
+	cerr := &ConstraintError{
+		Constraint: submatch(reConstraint, msg),
+		Table:      strings.TrimPrefix(submatch(reTable, msg), "dbo."),
+		Column:     submatch(reColumn, msg),
+		Value:      submatch(reValue, msg),
+	}
+	switch sqlErr.SQLErrorNumber() {
+	case 2627, 2601:
+		cerr.Violation = ViolationUnique
+		if cerr.Column == "" {
+			cerr.Column = keyColumn(cerr.Constraint, cerr.Table)
+		}
+	case 547:
+		switch {
+		case strings.Contains(msg, "REFERENCE constraint"):
+			cerr.Violation = ViolationReference
+		case strings.Contains(msg, "CHECK constraint"):
+			cerr.Violation = ViolationCheck
+		default:
+			cerr.Violation = ViolationForeignKey
+		}
+	default:
+		return nil
+	}
+	return cerr
+}
+
+var (
+	rePgKey      = regexp.MustCompile(`Key \(([^)]*)\)=\((.*)\)`)
+	rePgRefTable = regexp.MustCompile(`table "([^"]+)"`)
+)
+
+// postgresConstraint reads SQLSTATE 23505 (unique), 23503 (foreign key) and 23514 (check)
+func postgresConstraint(err error, _ bool) *ConstraintError {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	cerr := &ConstraintError{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Column: pgErr.ColumnName}
+	if m := rePgKey.FindStringSubmatch(pgErr.Detail); m != nil {
+		cerr.Column = strings.Trim(m[1], `"`)
+		cerr.Value = m[2]
+	}
+	switch pgErr.Code {
+	case "23505":
+		cerr.Violation = ViolationUnique
+	case "23503":
+		// the detail names the other table: the missing parent or the rows still pointing here
+		cerr.Violation = ViolationForeignKey
+		if strings.Contains(pgErr.Detail, "still referenced") {
+			cerr.Violation = ViolationReference
+		}
+		cerr.Table = submatch(rePgRefTable, pgErr.Detail)
+		cerr.Value = ""
+	case "23514":
+		cerr.Violation = ViolationCheck
+	default:
+		return nil
+	}
+	return cerr
+}
+
+var (
+	reMySQLDuplicate = regexp.MustCompile("Duplicate entry '(.*)' for key '([^']+)'")
+	reMySQLForeign   = regexp.MustCompile("[`\"]([^`\"]+)[`\"], CONSTRAINT [`\"]([^`\"]+)[`\"] FOREIGN KEY \\([`\"]([^`\"]+)[`\"]\\) REFERENCES [`\"]([^`\"]+)[`\"]")
+	reMySQLCheck     = regexp.MustCompile("Check constraint '([^']+)'")
+)
+
+// mysqlConstraint reads errors 1062 (unique), 1451 and 1452 (foreign key) and 3819 (check)
+func mysqlConstraint(err error, _ bool) *ConstraintError {
+	var myErr *mysqldriver.MySQLError
+	if !errors.As(err, &myErr) {
+		return nil
+	}
+
+	switch myErr.Number {
+	case 1062:
+		m := reMySQLDuplicate.FindStringSubmatch(myErr.Message)
+		if m == nil {
+			return &ConstraintError{Violation: ViolationUnique}
+		}
+		table, key, _ := strings.Cut(m[2], ".")
+		if key == "" {
+			table, key = "", table
+		}
+		return &ConstraintError{Violation: ViolationUnique, Constraint: key, Table: table, Column: keyColumn(key, table), Value: m[1]}
+	case 1451, 1452:
+		cerr := &ConstraintError{Violation: ViolationForeignKey}
+		m := reMySQLForeign.FindStringSubmatch(myErr.Message)
+		if m != nil {
+			cerr.Constraint, cerr.Column, cerr.Table = m[2], m[3], m[4]
+		}
+		if myErr.Number == 1451 {
+			// a parent row is still referenced, name the child table
+			cerr.Violation = ViolationReference
+			if m != nil {
+				cerr.Table = m[1]
+			}
+		}
+		return cerr
+	case 3819:
+		return &ConstraintError{Violation: ViolationCheck, Constraint: submatch(reMySQLCheck, myErr.Message)}
+	}
+	return nil
+}
+
+// sqliteConstraint reads the constraint messages of SQLite. The driver error type
+// needs cgo, the messages do not.
+func sqliteConstraint(err error, deleting bool) *ConstraintError {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "UNIQUE constraint failed: "), strings.HasPrefix(msg, "PRIMARY KEY constraint failed: "):
+		_, columns, _ := strings.Cut(msg, ": ")
+		table, column, _ := strings.Cut(strings.Split(columns, ", ")[0], ".")
+		return &ConstraintError{Violation: ViolationUnique, Table: table, Column: column}
+	case strings.HasPrefix(msg, "FOREIGN KEY constraint failed"):
+		if deleting {
+			return &ConstraintError{Violation: ViolationReference}
+		}
+		return &ConstraintError{Violation: ViolationForeignKey}
+	case strings.HasPrefix(msg, "CHECK constraint failed: "):
+		_, name, _ := strings.Cut(msg, ": ")
+		return &ConstraintError{Violation: ViolationCheck, Constraint: name}
+	}
+	return nil
+}
+
+func submatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// keyColumn guesses the column from a key name such as IX_Users_Email, the server
+// does not report it for unique violations. Primary keys and
+// generated names such as UQ__Users__A9D1 give "".
+func keyColumn(constraint, table string) string {
+	if strings.HasPrefix(constraint, "PK") || strings.Contains(constraint, "__") {
+		return ""
+	}
+	name := constraint
+	if i := strings.Index(name, "_"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimPrefix(name, table+"_")
+}
+
+// ErrorTranslator is a GORM plugin that runs translateError on the result of every
+// statement, so repositories return *ConstraintError instead of raw driver errors:
+//
+//	db.Use(repositories.ErrorTranslator{})
+type ErrorTranslator struct{}
+
+func (ErrorTranslator) Name() string { return "northwind:error_translator" }
+
+func (t ErrorTranslator) Initialize(db *gorm.DB) error {
+	dialect := db.Dialector.Name()
+	translate := func(deleting bool) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			tx.Error = translateError(dialect, tx.Error, deleting)
+		}
+	}
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().After("*").Register(t.Name(), translate(false)),
+		cb.Query().After("*").Register(t.Name(), translate(false)),
+		cb.Update().After("*").Register(t.Name(), translate(false)),
+		cb.Delete().After("*").Register(t.Name(), translate(true)),
+		cb.Row().After("*").Register(t.Name(), translate(false)),
+		cb.Raw().After("*").Register(t.Name(), translate(false)),
+	)
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeSQLError mimics mssql.Error
+type fakeSQLError struct {
+	number  int32
+	message string
+}
+
+func (e fakeSQLError) Error() string           { return "mssql: " + e.message }
+func (e fakeSQLError) SQLErrorNumber() int32   { return e.number }
+func (e fakeSQLError) SQLErrorMessage() string { return e.message }
+
+func TestTranslateError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  fakeSQLError
+		want ConstraintError
+	}{
+		{
+			name: "unique index",
+			err:  fakeSQLError{2601, "Cannot insert duplicate key row in object 'dbo.Users' with unique index 'IX_Users_Email'. The duplicate key value is (a@b.c)."},
+			want: ConstraintError{Violation: ViolationUnique, Constraint: "IX_Users_Email", Table: "Users", Column: "Email", Value: "a@b.c"},
+		},
+		{
+			name: "primary key",
+			err:  fakeSQLError{2627, "Violation of PRIMARY KEY constraint 'PK_Categories'. Cannot insert duplicate key in object 'dbo.Categories'. The duplicate key value is (1)."},
+			want: ConstraintError{Violation: ViolationUnique, Constraint: "PK_Categories", Table: "Categories", Value: "1"},
+		},
+		{
+			name: "missing parent",
+			err:  fakeSQLError{547, `The INSERT statement conflicted with the FOREIGN KEY constraint "FK_Products_Categories". The conflict occurred in database "Northwind", table "dbo.Categories", column 'CategoryID'.`},
+			want: ConstraintError{Violation: ViolationForeignKey, Constraint: "FK_Products_Categories", Table: "Categories", Column: "CategoryID"},
+		},
+		{
+			name: "still referenced",
+			err:  fakeSQLError{547, `The DELETE statement conflicted with the REFERENCE constraint "FK_Products_Categories". The conflict occurred in database "Northwind", table "dbo.Products", column 'CategoryID'.`},
+			want: ConstraintError{Violation: ViolationReference, Constraint: "FK_Products_Categories", Table: "Products", Column: "CategoryID"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := translateError("sqlserver", fmt.Errorf("wrapped: %w", tc.err), false)
+
+			var cerr *ConstraintError
+			require.True(t, errors.As(err, &cerr), "got %v", err)
+			tc.want.Err = cerr.Err
+			assert.Equal(t, tc.want, *cerr)
+		})
+	}
+
+	other := fakeSQLError{208, "Invalid object name 'dbo.Nope'."}
+	assert.Equal(t, error(other), translateError("sqlserver", other, false))
+	assert.Equal(t, gorm.ErrRecordNotFound, translateError("sqlserver", gorm.ErrRecordNotFound, false))
+	assert.Nil(t, translateError("sqlserver", nil, false))
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"gorm.io/gorm"
+)
+
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+)
+
+// ImportOutcome is what happened to one imported row; Action is empty when the row was not written
+type ImportOutcome struct {
+	Action string
+	Err    error
+}
+
+var errImportRollback = errors.New("import rolled back")
+
+// importRows writes rows inside one transaction. Atomic imports stop and roll back on
+// the first failure; otherwise every row runs in its own savepoint so failures are skipped.
+// Dry runs always roll back at the end.
+func importRows[T any](db *gorm.DB, rows []T, opts models.ImportOptions, write func(tx *gorm.DB, row *T) (string, error)) ([]ImportOutcome, error) {
+	outcomes := make([]ImportOutcome, len(rows))
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i := range rows {
+			if opts.Atomic {
+				action, err := write(tx, &rows[i])
+				outcomes[i] = ImportOutcome{Action: action, Err: err}
+				if err != nil {
+					return errImportRollback
+				}
+				continue
+			}
+
+			var action string
+			err := tx.Transaction(func(rowTx *gorm.DB) error {
+				var err error
+				action, err = write(rowTx, &rows[i])
+				return err
+			})
+			if err != nil {
+				action = ""
+			}
+			outcomes[i] = ImportOutcome{Action: action, Err: err}
+		}
+		if opts.DryRun {
+			return errImportRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errImportRollback) {
+		err = nil
+	}
+	return outcomes, err
+}
+
+// upsert updates the row matched by key ("id" uses *id, "name" uses match) or creates a new one
+func upsert[T any](tx *gorm.DB, row *T, id *int, pkColumn, key string, match map[string]interface{}) (string, error) {
+	var model T
+
+	switch key {
+	case "id":
+		if *id == 0 {
+			return ImportCreated, tx.Create(row).Error
+		}
+		var count int64
+		if err := tx.Model(&model).Where(map[string]interface{}{pkColumn: *id}).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return "", fmt.Errorf("no record with id %d: %w", *id, gorm.ErrRecordNotFound)
+		}
+	case "name":
+		var ids []int
+		if err := tx.Model(&model).Where(match).Limit(1).Pluck(pkColumn, &ids).Error; err != nil {
+			return "", err
+		}
+		if len(ids) == 0 {
+			*id = 0
+			return ImportCreated, tx.Create(row).Error
+		}
+		*id = ids[0]
+	default:
+		return "", fmt.Errorf("unsupported import key %q", key)
+	}
+
+	return ImportUpdated, tx.Save(row).Error
+}
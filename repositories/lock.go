@@ -0,0 +1,19 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// lockRow takes the write lock of the row of table whose key column is id, held until
+// tx ends, so a read, a check and a write in tx cannot interleave with another writer.
+// A missing row is not an error, the read that follows reports it.
+func lockRow(tx *gorm.DB, table, key string, id int) error {
+	table, key = tx.Statement.Quote(table), tx.Statement.Quote(key)
+	switch tx.Dialector.Name() {
+	case "sqlite":
+		// no row locks, a no-op write takes the database write lock instead
+		return tx.Exec(`UPDATE `+table+` SET `+key+` = `+key+` WHERE `+key+` = ?`, id).Error
+	case "sqlserver":
+		return tx.Exec(`SELECT `+key+` FROM `+table+` WITH (UPDLOCK, ROWLOCK) WHERE `+key+` = ?`, id).Error
+	default: // postgres, mysql
+		return tx.Exec(`SELECT `+key+` FROM `+table+` WHERE `+key+` = ? FOR UPDATE`, id).Error
+	}
+}
@@ -1,100 +1,138 @@
 package repositories
 
 import (
+	"context"
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"gorm.io/gorm"
 )
 
 type OrderRepository interface {
-	CreateOrderWithDetails(order *models.Order, details []models.OrderDetail) error
-	GetAll(page, limit int) ([]models.Order, int64, error)
-	GetByID(id int) (*models.Order, []models.OrderDetail, error)
-	Update(id int, order *models.Order, details []models.OrderDetail) error
-	Delete(id int) error
+	CreateOrderWithDetails(ctx context.Context, order *models.Order, details []models.OrderDetail) error
+	GetAll(ctx context.Context, page, limit int, columns ...string) ([]models.Order, int64, error)
+	GetByID(ctx context.Context, id int, orderColumns, detailColumns []string) (*models.Order, []models.OrderDetail, error)
+	GetDetailsByOrderIDs(ctx context.Context, ids []int) ([]models.OrderDetail, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Order) error) error
+	Update(ctx context.Context, id int, order *models.Order, details []models.OrderDetail) error
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Order, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	// Locked runs fn against a repository bound to one transaction that holds the
+	// write lock of order id
+	Locked(ctx context.Context, id int, fn func(OrderRepository) error) error
 }
 
 type orderRepository struct {
 	db *gorm.DB
+	trash[models.Order]
 }
 
 func NewOrderRepository(db *gorm.DB) OrderRepository {
-	return &orderRepository{db: db}
+	return &orderRepository{db: db, trash: trash[models.Order]{db: db, key: "OrderID"}}
 }
 
-func (r *orderRepository) CreateOrderWithDetails(order *models.Order, details []models.OrderDetail) error {
-	tx := r.db.Begin()
-	if err := tx.Create(order).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	for _, detail := range details {
-		detail.OrderID = order.OrderID
-		if err := tx.Create(&detail).Error; err != nil {
-			tx.Rollback()
+// CreateOrderWithDetails writes the order and its details in one transaction,
+// a savepoint when the caller already runs inside one
+func (r *orderRepository) CreateOrderWithDetails(ctx context.Context, order *models.Order, details []models.OrderDetail) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
 			return err
 		}
-	}
-
-	return tx.Commit().Error
+		for _, detail := range details {
+			detail.OrderID = order.OrderID
+			if err := tx.Create(&detail).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (r *orderRepository) GetAll(page, limit int) ([]models.Order, int64, error) {
+func (r *orderRepository) GetAll(ctx context.Context, page, limit int, columns ...string) ([]models.Order, int64, error) {
+	db := r.db.WithContext(ctx)
 	var orders []models.Order
 	var total int64
 
+	if err := db.Model(&models.Order{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	offset := (page - 1) * limit
-	err := r.db.Model(&models.Order{}).Count(&total).
+	err := selectColumns(db, columns).
 		Limit(limit).Offset(offset).
 		Find(&orders).Error
 	return orders, total, err
 }
 
-func (r *orderRepository) GetByID(id int) (*models.Order, []models.OrderDetail, error) {
+func (r *orderRepository) StreamAll(ctx context.Context, columns []string, fn func(models.Order) error) error {
+	return streamRows(r.db.WithContext(ctx), columns, fn)
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id int, orderColumns, detailColumns []string) (*models.Order, []models.OrderDetail, error) {
+	db := r.db.WithContext(ctx)
 	var order models.Order
-	if err := r.db.First(&order, id).Error; err != nil {
+	if err := selectColumns(db, orderColumns).First(&order, id).Error; err != nil {
 		return nil, nil, err
 	}
 	var details []models.OrderDetail
-	if err := r.db.Where("OrderID = ?", id).Find(&details).Error; err != nil {
+	if err := selectColumns(db, detailColumns).Where(`"OrderID" = ?`, id).Find(&details).Error; err != nil {
 		return nil, nil, err
 	}
 	return &order, details, nil
 }
 
-func (r *orderRepository) Delete(id int) error {
-	tx := r.db.Begin()
-	if err := tx.Where("OrderID = ?", id).Delete(&models.OrderDetail{}).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-	if err := tx.Delete(&models.Order{}, id).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-	return tx.Commit().Error
+func (r *orderRepository) GetDetailsByOrderIDs(ctx context.Context, ids []int) ([]models.OrderDetail, error) {
+	var details []models.OrderDetail
+	err := r.db.WithContext(ctx).Where(`"OrderID" IN ?`, ids).Find(&details).Error
+	return details, err
 }
 
-func (r *orderRepository) Update(id int, order *models.Order, details []models.OrderDetail) error {
-	tx := r.db.Begin()
-
-	if err := tx.Model(&models.Order{}).Where("OrderID = ?", id).Updates(order).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+// Delete moves the order to the trash, its details stay so it can be restored intact
+func (r *orderRepository) Delete(ctx context.Context, id, deletedBy int) error {
+	return r.softDelete(r.db.WithContext(ctx), id, deletedBy)
+}
 
-	if err := tx.Where("OrderID = ?", id).Delete(&models.OrderDetail{}).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+// Purge removes a trashed order together with its details
+func (r *orderRepository) Purge(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Unscoped().Where(`"DeletedAt" IS NOT NULL`).First(&order, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(`"OrderID" = ?`, id).Delete(&models.OrderDetail{}).Error; err != nil {
+			return err
+		}
+		return r.purge(tx, id)
+	})
+}
 
-	for _, d := range details {
-		d.OrderID = id
-		if err := tx.Create(&d).Error; err != nil {
-			tx.Rollback()
+func (r *orderRepository) Locked(ctx context.Context, id int, fn func(OrderRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockRow(tx, models.Order{}.TableName(), "OrderID", id); err != nil {
 			return err
 		}
-	}
+		return fn(NewOrderRepository(tx))
+	})
+}
 
-	return tx.Commit().Error
+func (r *orderRepository) Update(ctx context.Context, id int, order *models.Order, details []models.OrderDetail) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// a trashed order is not found, its details must stay untouched
+		if err := tx.Select("OrderID").First(&models.Order{}, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Order{}).Where(`"OrderID" = ?`, id).Updates(order).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(`"OrderID" = ?`, id).Delete(&models.OrderDetail{}).Error; err != nil {
+			return err
+		}
+		for _, d := range details {
+			d.OrderID = id
+			if err := tx.Create(&d).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
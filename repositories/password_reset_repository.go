@@ -1,14 +1,17 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"gorm.io/gorm"
 )
 
 type PasswordResetRepository interface {
-	Save(reset *models.PasswordReset) error
-	FindByToken(token string) (*models.PasswordReset, error)
-	MarkUsed(id int) error
+	Save(ctx context.Context, reset *models.PasswordReset) error
+	FindByToken(ctx context.Context, token string) (*models.PasswordReset, error)
+	MarkUsed(ctx context.Context, id int) error
 }
 
 type passwordResetRepo struct {
@@ -19,16 +22,16 @@ func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
 	return &passwordResetRepo{db}
 }
 
-func (r *passwordResetRepo) Save(reset *models.PasswordReset) error {
-	return r.db.Create(reset).Error
+func (r *passwordResetRepo) Save(ctx context.Context, reset *models.PasswordReset) error {
+	return r.db.WithContext(ctx).Create(reset).Error
 }
 
-func (r *passwordResetRepo) FindByToken(token string) (*models.PasswordReset, error) {
+func (r *passwordResetRepo) FindByToken(ctx context.Context, token string) (*models.PasswordReset, error) {
 	var reset models.PasswordReset
-	err := r.db.Where("token = ? AND used = false AND expires_at > GETDATE()", token).First(&reset).Error
+	err := r.db.WithContext(ctx).Where("token = ? AND used = ? AND expires_at > ?", token, false, time.Now()).First(&reset).Error
 	return &reset, err
 }
 
-func (r *passwordResetRepo) MarkUsed(id int) error {
-	return r.db.Model(&models.PasswordReset{}).Where("id = ?", id).Update("used", true).Error
+func (r *passwordResetRepo) MarkUsed(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Model(&models.PasswordReset{}).Where("id = ?", id).Update("used", true).Error
 }
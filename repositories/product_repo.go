@@ -1,86 +1,147 @@
 package repositories
 
 import (
+	"context"
+	"strings"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"gorm.io/gorm"
 )
 
 type ProductRepository interface {
-	GetAll() ([]models.Product, error)
-	GetPaginated(page, limit int) ([]models.Product, int64, error)
-	SearchByName(name string, page, limit int) ([]models.Product, int64, error)
-	GetByID(id int) (*models.Product, error)
-	Create(prod *models.Product) (*models.Product, error)
-	Update(prod *models.Product) (*models.Product, error)
-	Delete(id int) error
+	GetAll(ctx context.Context, columns ...string) ([]models.Product, error)
+	GetPaginated(ctx context.Context, page, limit int, columns ...string) ([]models.Product, int64, error)
+	SearchByName(ctx context.Context, name string, page, limit int, columns ...string) ([]models.Product, int64, error)
+	GetByID(ctx context.Context, id int, columns ...string) (*models.Product, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.Product, error)
+	GetByNames(ctx context.Context, names []string) ([]models.Product, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Product) error) error
+	Create(ctx context.Context, prod *models.Product) (*models.Product, error)
+	Update(ctx context.Context, prod *models.Product) (*models.Product, error)
+	UpdateColumns(ctx context.Context, prod *models.Product, columns []string) (*models.Product, error)
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Product, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []models.Product, opts models.ImportOptions) ([]ImportOutcome, error)
+	// Locked runs fn against a repository bound to one transaction that holds the
+	// write lock of product id
+	Locked(ctx context.Context, id int, fn func(ProductRepository) error) error
 }
 
 type productRepo struct {
 	db *gorm.DB
+	trash[models.Product]
 }
 
 func NewProductRepository(db *gorm.DB) ProductRepository {
-	return &productRepo{db}
+	return &productRepo{db: db, trash: trash[models.Product]{db: db, key: "ProductID"}}
 }
 
-func (r *productRepo) GetAll() ([]models.Product, error) {
+func (r *productRepo) GetAll(ctx context.Context, columns ...string) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Find(&products).Error
+	err := selectColumns(r.db.WithContext(ctx), columns).Find(&products).Error
 	return products, err
 }
 
-func (r *productRepo) GetPaginated(page, limit int) ([]models.Product, int64, error) {
+func (r *productRepo) GetPaginated(ctx context.Context, page, limit int, columns ...string) ([]models.Product, int64, error) {
+	db := r.db.WithContext(ctx)
 	var products []models.Product
 	var total int64
 
+	if err := db.Model(&models.Product{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	offset := (page - 1) * limit
 
-	err := r.db.Model(&models.Product{}).Count(&total).Limit(limit).Offset(offset).Find(&products).Error
+	err := selectColumns(db, columns).Limit(limit).Offset(offset).Find(&products).Error
 	if err != nil {
 		return nil, 0, err
 	}
 	return products, total, nil
 }
 
-func (r *productRepo) SearchByName(name string, page, limit int) ([]models.Product, int64, error) {
+func (r *productRepo) SearchByName(ctx context.Context, name string, page, limit int, columns ...string) ([]models.Product, int64, error) {
 	var products []models.Product
 	var total int64
 
-	query := r.db.Model(&models.Product{}).Where("ProductName LIKE ?", "%"+name+"%")
+	query := r.db.WithContext(ctx).Model(&models.Product{}).Where(`LOWER("ProductName") LIKE ?`, "%"+strings.ToLower(name)+"%")
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * limit
-	if err := query.Limit(limit).Offset(offset).Find(&products).Error; err != nil {
+	if err := selectColumns(query, columns).Limit(limit).Offset(offset).Find(&products).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return products, total, nil
 }
 
-func (r *productRepo) GetByID(id int) (*models.Product, error) {
+func (r *productRepo) StreamAll(ctx context.Context, columns []string, fn func(models.Product) error) error {
+	return streamRows(r.db.WithContext(ctx), columns, fn)
+}
+
+func (r *productRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Find(&products, ids).Error
+	return products, err
+}
+
+func (r *productRepo) GetByNames(ctx context.Context, names []string) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Where("ProductName IN ?", names).Find(&products).Error
+	return products, err
+}
+
+func (r *productRepo) GetByID(ctx context.Context, id int, columns ...string) (*models.Product, error) {
 	var prod models.Product
-	err := r.db.First(&prod, id).Error
+	err := selectColumns(r.db.WithContext(ctx), columns).First(&prod, id).Error
 	return &prod, err
 }
 
-func (r *productRepo) Create(prod *models.Product) (*models.Product, error) {
+func (r *productRepo) Create(ctx context.Context, prod *models.Product) (*models.Product, error) {
 
-	if err := r.db.Create(prod).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(prod).Error; err != nil {
 		return nil, err
 	}
 	return prod, nil
 }
 
-func (r *productRepo) Update(prod *models.Product) (*models.Product, error) {
-	if err := r.db.Save(prod).Error; err != nil {
+func (r *productRepo) Update(ctx context.Context, prod *models.Product) (*models.Product, error) {
+	if err := r.db.WithContext(ctx).Save(prod).Error; err != nil {
 		return nil, err
 	}
 	return prod, nil
 }
 
-func (r *productRepo) Delete(id int) error {
-	return r.db.Delete(&models.Product{}, id).Error
+func (r *productRepo) Locked(ctx context.Context, id int, fn func(ProductRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockRow(tx, models.Product{}.TableName(), "ProductID", id); err != nil {
+			return err
+		}
+		return fn(NewProductRepository(tx))
+	})
+}
+
+// UpdateColumns writes only the listed columns, used by PATCH
+func (r *productRepo) UpdateColumns(ctx context.Context, prod *models.Product, columns []string) (*models.Product, error) {
+	if err := updateColumns(r.db.WithContext(ctx), prod, columns); err != nil {
+		return nil, err
+	}
+	return prod, nil
+}
+
+// Import upserts rows keyed by ProductID or by name, see importRows for the transaction modes
+func (r *productRepo) Import(ctx context.Context, rows []models.Product, opts models.ImportOptions) ([]ImportOutcome, error) {
+	return importRows(r.db.WithContext(ctx), rows, opts, func(tx *gorm.DB, prod *models.Product) (string, error) {
+		return upsert(tx, prod, &prod.ProductID, "ProductID", opts.Key, map[string]interface{}{"ProductName": prod.ProductName})
+	})
+}
+
+// Delete moves the row to the trash, see Restore and Purge
+func (r *productRepo) Delete(ctx context.Context, id, deletedBy int) error {
+	return r.softDelete(r.db.WithContext(ctx), id, deletedBy)
 }
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"gorm.io/gorm"
+)
+
+// lineTotal is the extended price of one order line, as in the Northwind views
+const lineTotal = `od."UnitPrice" * od."Quantity" * (1 - od."Discount")`
+
+type ReportRepository interface {
+	SalesByCategory(ctx context.Context, r models.DateRange) ([]models.CategorySales, error)
+	ProductSales(ctx context.Context, r models.DateRange) ([]models.ProductSales, error)
+	SalesByEmployee(ctx context.Context, r models.DateRange) ([]models.EmployeeSales, error)
+	QuarterlyOrders(ctx context.Context, r models.DateRange) ([]models.QuarterlyOrders, error)
+	OrderSubtotals(ctx context.Context, r models.DateRange) ([]models.OrderSubtotal, error)
+	MostExpensiveProducts(ctx context.Context, limit int) ([]models.ExpensiveProduct, error)
+}
+
+type reportRepo struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepo{db}
+}
+
+// orderLines joins Orders with their Order Details rows
+func (r *reportRepo) orderLines(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table(`"Orders" o`).
+		Joins(`JOIN "Order Details" od ON od."OrderID" = o."OrderID"`).
+		Where(`o."DeletedAt" IS NULL`)
+}
+
+// inRange limits column to the half-open interval [From, To)
+func inRange(db *gorm.DB, column string, r models.DateRange) *gorm.DB {
+	if !r.From.IsZero() {
+		db = db.Where(column+" >= ?", r.From)
+	}
+	if !r.To.IsZero() {
+		db = db.Where(column+" < ?", r.To)
+	}
+	return db
+}
+
+func (r *reportRepo) SalesByCategory(ctx context.Context, dr models.DateRange) ([]models.CategorySales, error) {
+	var rows []models.CategorySales
+	year := yearOf(r.db, `o."ShippedDate"`)
+	err := inRange(r.orderLines(ctx), `o."ShippedDate"`, dr).
+		Select(`c."CategoryName" AS category_name, ` + year + ` AS year, SUM(` + lineTotal + `) AS total_sales`).
+		Joins(`JOIN "Products" p ON p."ProductID" = od."ProductID"`).
+		Joins(`JOIN "Categories" c ON c."CategoryID" = p."CategoryID"`).
+		Where(`o."ShippedDate" IS NOT NULL`).
+		Group(`c."CategoryName", ` + year).
+		Order("year, category_name").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) ProductSales(ctx context.Context, dr models.DateRange) ([]models.ProductSales, error) {
+	var rows []models.ProductSales
+	err := inRange(r.orderLines(ctx), `o."ShippedDate"`, dr).
+		Select(`p."ProductID" AS product_id, p."ProductName" AS product_name, c."CategoryName" AS category_name, SUM(` + lineTotal + `) AS total_sales`).
+		Joins(`JOIN "Products" p ON p."ProductID" = od."ProductID"`).
+		Joins(`JOIN "Categories" c ON c."CategoryID" = p."CategoryID"`).
+		Where(`o."ShippedDate" IS NOT NULL`).
+		Group(`p."ProductID", p."ProductName", c."CategoryName"`).
+		Order("total_sales DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) SalesByEmployee(ctx context.Context, dr models.DateRange) ([]models.EmployeeSales, error) {
+	var rows []models.EmployeeSales
+	err := inRange(r.orderLines(ctx), `o."ShippedDate"`, dr).
+		Select(`e."EmployeeID" AS employee_id, e."FirstName" AS first_name, e."LastName" AS last_name, e."Country" AS country, ` +
+			`COUNT(DISTINCT o."OrderID") AS order_count, SUM(` + lineTotal + `) AS total_sales`).
+		Joins(`JOIN "Employees" e ON e."EmployeeID" = o."EmployeeID"`).
+		Where(`o."ShippedDate" IS NOT NULL`).
+		Group(`e."EmployeeID", e."FirstName", e."LastName", e."Country"`).
+		Order("total_sales DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) QuarterlyOrders(ctx context.Context, dr models.DateRange) ([]models.QuarterlyOrders, error) {
+	var rows []models.QuarterlyOrders
+	year, quarter := yearOf(r.db, `o."OrderDate"`), quarterOf(r.db, `o."OrderDate"`)
+	err := inRange(r.orderLines(ctx), `o."OrderDate"`, dr).
+		Select(year + ` AS year, ` + quarter + ` AS quarter, ` +
+			`COUNT(DISTINCT o."OrderID") AS order_count, SUM(` + lineTotal + `) AS total_sales`).
+		Group(year + `, ` + quarter).
+		Order("year, quarter").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) OrderSubtotals(ctx context.Context, dr models.DateRange) ([]models.OrderSubtotal, error) {
+	var rows []models.OrderSubtotal
+	err := inRange(r.orderLines(ctx), `o."OrderDate"`, dr).
+		Select(`o."OrderID" AS order_id, SUM(` + lineTotal + `) AS subtotal`).
+		Group(`o."OrderID"`).
+		Order("order_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) MostExpensiveProducts(ctx context.Context, limit int) ([]models.ExpensiveProduct, error) {
+	var rows []models.ExpensiveProduct
+	err := r.db.WithContext(ctx).Table("Products").
+		Select(`"ProductID" AS product_id, "ProductName" AS product_name, "UnitPrice" AS unit_price`).
+		Where(`"DeletedAt" IS NULL`).
+		Order(`"UnitPrice" DESC`).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
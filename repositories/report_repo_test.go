@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqliteDB is an in-memory database with the tables the reports read
+func sqliteDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=1"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(ErrorTranslator{}))
+	require.NoError(t, db.AutoMigrate(&models.Category{}, &models.Employee{}, &models.Order{}))
+	require.NoError(t, db.Exec(`CREATE TABLE "Products" (
+		"ProductID" INTEGER PRIMARY KEY, "ProductName" TEXT, "SupplierID" INTEGER,
+		"CategoryID" INTEGER REFERENCES "Categories"("CategoryID"), "QuantityPerUnit" TEXT,
+		"UnitPrice" REAL, "UnitsInStock" INTEGER, "UnitsOnOrder" INTEGER, "ReorderLevel" INTEGER,
+		"Discontinued" NUMERIC, "DeletedAt" DATETIME, "DeletedBy" INTEGER)`).Error)
+	require.NoError(t, db.AutoMigrate(&models.OrderDetail{}))
+	return db
+}
+
+func TestReports_SQLite(t *testing.T) {
+	db := sqliteDB(t)
+	categoryID := 1
+	require.NoError(t, db.Create(&models.Category{CategoryID: categoryID, CategoryName: "Beverages"}).Error)
+	require.NoError(t, db.Create(&models.Product{ProductID: 1, ProductName: "Chai", CategoryID: &categoryID, UnitPrice: 18}).Error)
+	for id, day := range map[int]time.Time{
+		10248: time.Date(1996, 7, 4, 0, 0, 0, 0, time.UTC),
+		10249: time.Date(1996, 11, 5, 0, 0, 0, 0, time.UTC),
+	} {
+		day := day
+		require.NoError(t, db.Create(&models.Order{OrderID: id, OrderDate: &day, ShippedDate: &day}).Error)
+		require.NoError(t, db.Create(&models.OrderDetail{OrderID: id, ProductID: 1, UnitPrice: 10, Quantity: 2}).Error)
+	}
+	repo := NewReportRepository(db)
+
+	quarters, err := repo.QuarterlyOrders(t.Context(), models.DateRange{})
+	require.NoError(t, err)
+	assert.Equal(t, []models.QuarterlyOrders{
+		{Year: 1996, Quarter: 3, OrderCount: 1, TotalSales: 20},
+		{Year: 1996, Quarter: 4, OrderCount: 1, TotalSales: 20},
+	}, quarters)
+
+	sales, err := repo.SalesByCategory(t.Context(), models.DateRange{From: time.Date(1996, 10, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	require.Len(t, sales, 1)
+	assert.Equal(t, "Beverages", sales[0].CategoryName)
+	assert.Equal(t, 20.0, sales[0].TotalSales)
+}
+
+func TestPurge_ReferencedRowIsTranslated(t *testing.T) {
+	db := sqliteDB(t)
+	categoryID := 1
+	require.NoError(t, db.Create(&models.Category{CategoryID: categoryID, CategoryName: "Beverages"}).Error)
+	require.NoError(t, db.Create(&models.Product{ProductID: 1, ProductName: "Chai", CategoryID: &categoryID}).Error)
+
+	repo := NewCategoryRepository(db)
+	require.NoError(t, repo.Delete(t.Context(), categoryID, 7))
+	err := repo.Purge(t.Context(), categoryID)
+
+	var cerr *ConstraintError
+	require.True(t, errors.As(err, &cerr), "got %v", err)
+	assert.Equal(t, ViolationReference, cerr.Violation)
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// selectColumns narrows the SELECT to columns, or leaves it untouched when none are given
+func selectColumns(db *gorm.DB, columns []string) *gorm.DB {
+	if len(columns) == 0 {
+		return db
+	}
+	return db.Select(columns)
+}
+
+// streamRows runs the query with Rows() and hands each scanned row to fn,
+// so large exports never hold the full result set in memory
+func streamRows[T any](db *gorm.DB, columns []string, fn func(T) error) error {
+	var model T
+	rows, err := selectColumns(db.Model(&model), columns).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// updateColumns writes only the given columns of model, zero values included.
+// It is a no-op when nothing changed.
+func updateColumns(db *gorm.DB, model interface{}, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	return db.Model(model).Select(columns).Updates(model).Error
+}
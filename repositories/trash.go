@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// trash implements the soft delete lifecycle for a model embedding models.SoftDelete.
+// key is the primary key column; repositories embed it to get Trash, Restore and Purge.
+type trash[T any] struct {
+	db  *gorm.DB
+	key string
+}
+
+// softDelete stamps DeletedAt and DeletedBy on a live row, gorm.ErrRecordNotFound if there is none
+func (t trash[T]) softDelete(tx *gorm.DB, id, by int) error {
+	var model T
+	res := tx.Model(&model).Where(clause.Eq{Column: t.key, Value: id}).Updates(map[string]interface{}{
+		"DeletedAt": time.Now(),
+		"DeletedBy": by,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Trash lists deleted rows, most recently deleted first
+func (t trash[T]) Trash(ctx context.Context, page, limit int) ([]T, int64, error) {
+	var rows []T
+	var total int64
+	offset := (page - 1) * limit
+
+	query := t.db.WithContext(ctx).Unscoped().Model(new(T)).Where(`"DeletedAt" IS NOT NULL`)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order(`"DeletedAt" DESC`).Offset(offset).Limit(limit).Find(&rows).Error
+	return rows, total, err
+}
+
+// Restore brings a deleted row back, gorm.ErrRecordNotFound if it is not in the trash
+func (t trash[T]) Restore(ctx context.Context, id int) error {
+	res := t.db.WithContext(ctx).Unscoped().Model(new(T)).
+		Where(clause.Eq{Column: t.key, Value: id}).Where(`"DeletedAt" IS NOT NULL`).
+		Updates(map[string]interface{}{"DeletedAt": nil, "DeletedBy": nil})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge removes a row for good. Only rows already in the trash can be purged.
+func (t trash[T]) Purge(ctx context.Context, id int) error {
+	return t.purge(t.db.WithContext(ctx), id)
+}
+
+func (t trash[T]) purge(tx *gorm.DB, id int) error {
+	res := tx.Unscoped().Where(clause.Eq{Column: t.key, Value: id}).Where(`"DeletedAt" IS NOT NULL`).Delete(new(T))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
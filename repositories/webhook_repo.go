@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"gorm.io/gorm"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	ListSubscriptions(ctx context.Context, userID int) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, userID, id int) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, userID, id int) error
+	ActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+
+	EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID int, status string, page, limit int) ([]models.WebhookDelivery, int64, error)
+	Requeue(ctx context.Context, subscriptionID, id int, at time.Time) error
+}
+
+type webhookRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepo{db}
+}
+
+func (r *webhookRepo) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(sub).Error
+}
+
+func (r *webhookRepo) ListSubscriptions(ctx context.Context, userID int) ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	err := r.db.WithContext(ctx).Where(`"UserID" = ?`, userID).Order(`"ID"`).Find(&subs).Error
+	return subs, err
+}
+
+func (r *webhookRepo) GetSubscription(ctx context.Context, userID, id int) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := r.db.WithContext(ctx).Where(`"UserID" = ?`, userID).First(&sub, id).Error
+	return &sub, err
+}
+
+func (r *webhookRepo) DeleteSubscription(ctx context.Context, userID, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where(`"UserID" = ?`, userID).Delete(&models.WebhookSubscription{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where(`"SubscriptionID" = ?`, id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+func (r *webhookRepo) ActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	err := r.db.WithContext(ctx).Where(`"Active" = ?`, true).Find(&subs).Error
+	return subs, err
+}
+
+func (r *webhookRepo) EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&deliveries).Error
+}
+
+// ClaimDue leases up to limit pending deliveries that are due. A row is only
+// claimed when the conditional UPDATE wins, so several workers can share the queue.
+func (r *webhookRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	db := r.db.WithContext(ctx)
+	var due []models.WebhookDelivery
+	err := db.Select("ID").
+		Where(`"Status" = ? AND "NextAttemptAt" <= ?`, models.DeliveryPending, now).
+		Where(`"LockedUntil" IS NULL OR "LockedUntil" < ?`, now).
+		Order(`"NextAttemptAt"`).Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	until := now.Add(lease)
+	var ids []int
+	for _, d := range due {
+		res := db.Model(&models.WebhookDelivery{}).
+			Where(`"ID" = ? AND ("LockedUntil" IS NULL OR "LockedUntil" < ?)`, d.ID, now).
+			Update("LockedUntil", until)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			ids = append(ids, d.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var claimed []models.WebhookDelivery
+	err = db.Preload("Subscription").Order(`"NextAttemptAt"`).Find(&claimed, ids).Error
+	return claimed, err
+}
+
+// SaveAttempt stores the outcome of a delivery attempt and releases its lease
+func (r *webhookRepo) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.LockedUntil = nil
+	return r.db.WithContext(ctx).Model(delivery).
+		Select("Status", "Attempts", "NextAttemptAt", "LockedUntil", "ResponseStatus", "LastError", "DeliveredAt").
+		Updates(delivery).Error
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, subscriptionID int, status string, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where(`"SubscriptionID" = ?`, subscriptionID)
+	if status != "" {
+		query = query.Where(`"Status" = ?`, status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order(`"ID" DESC`).Offset(offset).Limit(limit).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// Requeue puts a delivery, typically a dead one, back in the queue with fresh retries
+func (r *webhookRepo) Requeue(ctx context.Context, subscriptionID, id int, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where(`"ID" = ? AND "SubscriptionID" = ?`, id, subscriptionID).
+		Updates(map[string]interface{}{
+			"Status":        models.DeliveryPending,
+			"Attempts":      0,
+			"NextAttemptAt": at,
+			"LockedUntil":   nil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
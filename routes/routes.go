@@ -1,9 +1,11 @@
 package routes
 
 import (
-	"github.com/aronipurwanto/go-api-northwind/config"
-	"github.com/aronipurwanto/go-api-northwind/controllers"
-	"github.com/aronipurwanto/go-api-northwind/middlewares"
+	"github.com/azizmasr1001/go-api-northwind/config"
+	"github.com/azizmasr1001/go-api-northwind/controllers"
+	"github.com/azizmasr1001/go-api-northwind/middlewares"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/redis/go-redis/v9"
 )
@@ -13,9 +15,24 @@ func SetupRoutes(app *fiber.App, cfg config.Config, redis *redis.Client,
 	empCtrl *controllers.EmployeeController,
 	catCtrl *controllers.CategoryController,
 	prodCtrl *controllers.ProductController,
-	orderCtrl *controllers.OrderController) {
+	orderCtrl *controllers.OrderController,
+	reportCtrl *controllers.ReportController,
+	dashboardCtrl *controllers.DashboardController,
+	graphqlCtrl *controllers.GraphQLController,
+	webhookCtrl *controllers.WebhookController,
+	streamCtrl *controllers.StreamController,
+	batchCtrl *controllers.BatchController,
+	healthCtrl *controllers.HealthController) {
+
+	// probes stay outside /api and need no token
+	app.Get("/healthz", healthCtrl.Live)
+	app.Get("/readyz", healthCtrl.Ready)
+
+	app.Post("/graphql", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis), graphqlCtrl.Handle)
+	app.Get("/graphql", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis), graphqlCtrl.Handle)
 
 	api := app.Group("/api")
+	idempotent := middlewares.Idempotency(redis, cfg.IdempotencyTTL)
 
 	api.Post("/login", authCtrl.Login)
 	api.Post("/refresh", authCtrl.Refresh)
@@ -26,31 +43,108 @@ func SetupRoutes(app *fiber.App, cfg config.Config, redis *redis.Client,
 	api.Post("/verify-otp", authCtrl.VerifyOTP)
 	api.Post("/reset-password", authCtrl.ResetPassword)
 
-	employee := api.Group("/employees", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis))
-	employee.Get("/", empCtrl.GetAll)
-	employee.Get("/:id", empCtrl.GetByID)
-	employee.Post("/", empCtrl.Create)
-	employee.Put("/:id", empCtrl.Update)
-	employee.Delete("/:id", empCtrl.Delete)
-
-	category := api.Group("/categories", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis))
-	category.Get("/", catCtrl.GetAll)
-	category.Post("/", catCtrl.Create)
-	category.Get("/:id", catCtrl.GetByID)
-	category.Put("/:id", catCtrl.Update)
-	category.Delete("/:id", catCtrl.Delete)
-
-	product := api.Group("/products", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis))
-	product.Get("/", middlewares.ValidateQueryPagination(1, 10), prodCtrl.GetAll)
-	product.Post("/", prodCtrl.Create)
-	product.Get("/:id", middlewares.ValidateIDParam("id"), prodCtrl.GetByID)
-	product.Put("/:id", middlewares.ValidateIDParam("id"), prodCtrl.Update)
-	product.Delete("/:id", middlewares.ValidateIDParam("id"), prodCtrl.Delete)
-
-	order := api.Group("/orders", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis))
-	order.Get("/", middlewares.ValidateQueryPagination(1, 10), orderCtrl.GetAll)
-	order.Get("/:id", middlewares.ValidateIDParam("id"), orderCtrl.GetByID)
-	order.Post("/", orderCtrl.Create)
-	order.Put("/:id", middlewares.ValidateIDParam("id"), orderCtrl.Update)
-	order.Delete("/:id", middlewares.ValidateIDParam("id"), orderCtrl.Delete)
+	protected := middlewares.ProtectedWithRedis(cfg.JWTSecret, redis)
+	resourceRoutes(api, protected, idempotent, Resources{
+		Employees:  empCtrl,
+		Categories: catCtrl,
+		Products:   prodCtrl,
+		Orders:     orderCtrl,
+	})
+
+	api.Post("/batch", protected, idempotent, batchCtrl.Batch)
+
+	report := api.Group("/reports", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis))
+	report.Get("/sales-by-category", reportCtrl.SalesByCategory)
+	report.Get("/product-sales", reportCtrl.ProductSales)
+	report.Get("/employee-sales", reportCtrl.SalesByEmployee)
+	report.Get("/quarterly-orders", reportCtrl.QuarterlyOrders)
+	report.Get("/order-subtotals", reportCtrl.OrderSubtotals)
+	report.Get("/top-products", reportCtrl.MostExpensiveProducts)
+
+	api.Get("/dashboard", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis), dashboardCtrl.Get)
+
+	api.Get("/stream", middlewares.TokenFromQuery("access_token"), middlewares.ProtectedWithRedis(cfg.JWTSecret, redis), streamCtrl.Stream)
+
+	// subscribers receive every matching event, not just their own data
+	webhook := api.Group("/webhooks", middlewares.ProtectedWithRedis(cfg.JWTSecret, redis), middlewares.RequireRole(models.RoleAdmin))
+	webhook.Get("/", webhookCtrl.List)
+	webhook.Post("/", idempotent, webhookCtrl.Create)
+	webhook.Get("/events", webhookCtrl.Events)
+	webhook.Get("/:id", middlewares.ValidateIDParam("id"), webhookCtrl.GetByID)
+	webhook.Delete("/:id", middlewares.ValidateIDParam("id"), webhookCtrl.Delete)
+	webhook.Get("/:id/deliveries", middlewares.ValidateIDParam("id"), middlewares.ValidateQueryPagination(1, 10), webhookCtrl.Deliveries)
+	webhook.Post("/:id/deliveries/:deliveryId/redeliver", middlewares.ValidateIDParam("id"), webhookCtrl.Redeliver)
+}
+
+// Resources are the controllers served both directly and through /api/batch
+type Resources struct {
+	Employees  *controllers.EmployeeController
+	Categories *controllers.CategoryController
+	Products   *controllers.ProductController
+	Orders     *controllers.OrderController
+}
+
+// resourceRoutes registers the employee, category, product and order routes.
+// guard authenticates each group, idempotent wraps the creating POSTs.
+func resourceRoutes(api fiber.Router, guard, idempotent fiber.Handler, res Resources) {
+	adminOnly := middlewares.RequireRole(models.RoleAdmin)
+	paged := middlewares.ValidateQueryPagination(1, 10)
+
+	employee := api.Group("/employees", guard)
+	employee.Get("/", middlewares.ValidateFields(models.Employee{}, nil), res.Employees.GetAll)
+	employee.Get("/trash", paged, res.Employees.Trash)
+	employee.Get("/:id", middlewares.ValidateFields(models.Employee{}, nil), res.Employees.GetByID)
+	employee.Post("/", idempotent, res.Employees.Create)
+	employee.Post("/import", idempotent, res.Employees.Import)
+	employee.Put("/:id", res.Employees.Update)
+	employee.Patch("/:id", res.Employees.Patch)
+	employee.Delete("/:id", res.Employees.Delete)
+	employee.Post("/:id/restore", middlewares.ValidateIDParam("id"), res.Employees.Restore)
+	employee.Delete("/:id/purge", adminOnly, middlewares.ValidateIDParam("id"), res.Employees.Purge)
+
+	category := api.Group("/categories", guard)
+	category.Get("/", middlewares.ValidateFields(models.Category{}, nil), res.Categories.GetAll)
+	category.Get("/trash", paged, res.Categories.Trash)
+	category.Post("/", idempotent, res.Categories.Create)
+	category.Post("/import", idempotent, res.Categories.Import)
+	category.Get("/:id", middlewares.ValidateFields(models.Category{}, nil), res.Categories.GetByID)
+	category.Put("/:id", res.Categories.Update)
+	category.Delete("/:id", res.Categories.Delete)
+	category.Post("/:id/restore", middlewares.ValidateIDParam("id"), res.Categories.Restore)
+	category.Delete("/:id/purge", adminOnly, middlewares.ValidateIDParam("id"), res.Categories.Purge)
+
+	product := api.Group("/products", guard)
+	product.Get("/", middlewares.ValidateQueryPagination(1, 10), middlewares.ValidateFields(models.Product{}, nil), res.Products.GetAll)
+	product.Get("/trash", paged, res.Products.Trash)
+	product.Post("/", idempotent, res.Products.Create)
+	product.Post("/import", idempotent, res.Products.Import)
+	product.Get("/:id", middlewares.ValidateIDParam("id"), middlewares.ValidateFields(models.Product{}, nil), res.Products.GetByID)
+	product.Put("/:id", middlewares.ValidateIDParam("id"), res.Products.Update)
+	product.Patch("/:id", middlewares.ValidateIDParam("id"), res.Products.Patch)
+	product.Delete("/:id", middlewares.ValidateIDParam("id"), res.Products.Delete)
+	product.Post("/:id/restore", middlewares.ValidateIDParam("id"), res.Products.Restore)
+	product.Delete("/:id/purge", adminOnly, middlewares.ValidateIDParam("id"), res.Products.Purge)
+
+	orderIncludes := map[string]interface{}{"details": models.OrderDetail{}}
+	order := api.Group("/orders", guard)
+	order.Get("/", middlewares.ValidateQueryPagination(1, 10), middlewares.ValidateFields(models.Order{}, nil), res.Orders.GetAll)
+	order.Get("/trash", paged, res.Orders.Trash)
+	order.Get("/:id", middlewares.ValidateIDParam("id"), middlewares.ValidateFields(models.Order{}, orderIncludes), res.Orders.GetByID)
+	order.Post("/", idempotent, res.Orders.Create)
+	order.Put("/:id", middlewares.ValidateIDParam("id"), res.Orders.Update)
+	order.Delete("/:id", middlewares.ValidateIDParam("id"), res.Orders.Delete)
+	order.Post("/:id/restore", middlewares.ValidateIDParam("id"), res.Orders.Restore)
+	order.Delete("/:id/purge", adminOnly, middlewares.ValidateIDParam("id"), res.Orders.Purge)
+}
+
+// MountBatch serves the resource routes from svc for the operations of one batch.
+// The batch request itself was authenticated and deduplicated already.
+func MountBatch(router fiber.Router, svc services.Services) {
+	next := func(c *fiber.Ctx) error { return c.Next() }
+	resourceRoutes(router, next, next, Resources{
+		Employees:  controllers.NewEmployeeController(svc.Employees),
+		Categories: controllers.NewCategoryController(svc.Categories),
+		Products:   controllers.NewProductController(svc.Products),
+		Orders:     controllers.NewOrderController(svc.Orders),
+	})
 }
@@ -3,14 +3,18 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/azizmasr1001/go-api-northwind/internal/metrics"
 	"github.com/azizmasr1001/go-api-northwind/internal/redis"
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"github.com/azizmasr1001/go-api-northwind/utils"
 	"github.com/golang-jwt/jwt/v5"
 	redislib "github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AuthService interface {
@@ -32,34 +36,45 @@ type AuthServiceImpl struct {
 	repo   repositories.AuthRepository
 	secret string
 	redis  *redislib.Client
+	outbox *Outbox
 }
 
-func NewAuthService(repo repositories.AuthRepository, secret string, redis *redislib.Client) AuthService {
-	return &AuthServiceImpl{repo: repo, secret: secret, redis: redis}
+func NewAuthService(repo repositories.AuthRepository, secret string, redis *redislib.Client, outbox *Outbox) AuthService {
+	return &AuthServiceImpl{repo: repo, secret: secret, redis: redis, outbox: outbox}
+}
+
+// userEvent is the data of user.registered, the email stays out of webhooks and logs
+type userEvent struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
 }
 
 func (s *AuthServiceImpl) Login(ctx context.Context, identifier, password string) (*models.User, error) {
-	user, err := s.repo.FindByUsername(identifier)
+	user, err := s.repo.FindByUsername(ctx, identifier)
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		metrics.Logins.WithLabelValues(metrics.LoginFailed).Inc()
+		return nil, Unauthorized(CodeInvalidCredentials, "Invalid credentials")
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		metrics.Logins.WithLabelValues(metrics.LoginFailed).Inc()
+		return nil, Unauthorized(CodeInvalidCredentials, "Invalid credentials")
 	}
+	metrics.Logins.WithLabelValues(metrics.LoginSucceeded).Inc()
 	return user, nil
 }
 
 func (s *AuthServiceImpl) Register(ctx context.Context, input *models.RegisterRequest) (*models.User, error) {
-	if exists, _ := s.repo.CheckUsernameExists(input.Username); exists {
-		return nil, fmt.Errorf("username already taken")
+	if exists, _ := s.repo.CheckUsernameExists(ctx, input.Username); exists {
+		return nil, Conflict(CodeUsernameTaken, "Username already taken", utils.ErrorDetail{Field: "username", Message: "already taken"})
 	}
-	if exists, _ := s.repo.CheckEmailExists(input.Email); exists {
-		return nil, fmt.Errorf("email already taken")
+	if exists, _ := s.repo.CheckEmailExists(ctx, input.Email); exists {
+		return nil, Conflict(CodeEmailTaken, "Email already taken", utils.ErrorDetail{Field: "email", Message: "already taken"})
 	}
 
 	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %v", err)
+		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	user := &models.User{
@@ -67,11 +82,17 @@ func (s *AuthServiceImpl) Register(ctx context.Context, input *models.RegisterRe
 		Email:        input.Email,
 		PasswordHash: string(hashed),
 		IsActive:     true,
-		Role:         "user",
+		Role:         models.RoleUser,
 		EmployeeID:   input.EmployeeID,
 	}
 
-	if err := s.repo.CreateUser(user); err != nil {
+	err = s.outbox.Transaction(ctx, func(tx *gorm.DB, _ CacheInvalidator, events EventPublisher) error {
+		if err := repositories.NewAuthRepository(tx).CreateUser(ctx, user); err != nil {
+			return err
+		}
+		return publish(ctx, events, models.EventUserRegistered, userEvent{UserID: user.UserID, Username: user.Username, Role: user.Role})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return user, nil
@@ -81,6 +102,7 @@ func (s *AuthServiceImpl) GenerateAccessToken(user *models.User) (string, error)
 	claims := jwt.MapClaims{
 		"user_id":   user.UserID,
 		"firstName": user.Username,
+		"role":      user.Role,
 		"exp":       time.Now().Add(15 * time.Minute).Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -91,6 +113,7 @@ func (s *AuthServiceImpl) GenerateRefreshToken(user *models.User) (string, error
 	claims := jwt.MapClaims{
 		"user_id":   user.UserID,
 		"firstName": user.Username,
+		"role":      user.Role,
 		"exp":       time.Now().Add(24 * time.Hour).Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -103,7 +126,7 @@ func (s *AuthServiceImpl) RefreshToken(ctx context.Context, refreshToken string)
 		return []byte(s.secret), nil
 	})
 	if err != nil || !token.Valid {
-		return "", fmt.Errorf("invalid refresh token")
+		return "", Unauthorized(CodeInvalidToken, "Invalid refresh token")
 	}
 
 	isBlacklisted, err := s.IsTokenBlacklisted(ctx, refreshToken)
@@ -111,19 +134,25 @@ func (s *AuthServiceImpl) RefreshToken(ctx context.Context, refreshToken string)
 		return "", err
 	}
 	if isBlacklisted {
-		return "", fmt.Errorf("refresh token revoked")
+		return "", Unauthorized(CodeTokenRevoked, "Refresh token revoked")
 	}
 
 	user := &models.User{
 		UserID:   int(claims["user_id"].(float64)),
 		Username: claims["firstName"].(string),
 	}
+	// tokens issued before roles were added carry no role claim
+	user.Role, _ = claims["role"].(string)
 
 	return s.GenerateAccessToken(user)
 }
 
 func (s *AuthServiceImpl) Logout(ctx context.Context, token string, exp time.Duration) error {
-	return redis.SetBlacklistToken(ctx, s.redis, token, exp)
+	if err := redis.SetBlacklistToken(ctx, s.redis, token, exp); err != nil {
+		return err
+	}
+	metrics.TokensRevoked.Inc()
+	return nil
 }
 
 func (s *AuthServiceImpl) IsTokenBlacklisted(ctx context.Context, token string) (bool, error) {
@@ -137,9 +166,9 @@ func (s *AuthServiceImpl) GetSecret() string {
 // Reset Password OTP
 
 func (s *AuthServiceImpl) SendResetOTP(ctx context.Context, email string) error {
-	_, err := s.repo.FindByUsername(email)
+	_, err := s.repo.FindByUsername(ctx, email)
 	if err != nil {
-		return fmt.Errorf("email not found")
+		return NotFound(CodeNotFound, "Email not found")
 	}
 	otp := fmt.Sprintf("%06d", time.Now().UnixNano()%1000000)
 	key := fmt.Sprintf("otp:%s", email)
@@ -148,7 +177,9 @@ func (s *AuthServiceImpl) SendResetOTP(ctx context.Context, email string) error
 		return err
 	}
 
-	fmt.Printf("[DEBUG] Send OTP to %s: %s\n", email, otp)
+	// there is no mailer yet, the OTP is only readable in debug logs
+	slog.DebugContext(ctx, "Reset OTP issued", "email", email, "otp", otp)
+	metrics.OTPsSent.Inc()
 	return nil
 }
 
@@ -156,7 +187,7 @@ func (s *AuthServiceImpl) VerifyResetOTP(ctx context.Context, email, otp string)
 	key := fmt.Sprintf("otp:%s", email)
 	storedOTP, err := s.redis.Get(ctx, key).Result()
 	if err == redislib.Nil {
-		return false, fmt.Errorf("OTP expired or not found")
+		return false, Invalid(CodeInvalidOTP, "OTP expired or not found")
 	} else if err != nil {
 		return false, err
 	}
@@ -164,14 +195,14 @@ func (s *AuthServiceImpl) VerifyResetOTP(ctx context.Context, email, otp string)
 }
 
 func (s *AuthServiceImpl) ResetPassword(ctx context.Context, email, newPassword string) error {
-	user, err := s.repo.FindByUsername(email)
+	user, err := s.repo.FindByUsername(ctx, email)
 	if err != nil {
-		return fmt.Errorf("user not found")
+		return NotFound(CodeNotFound, "User not found")
 	}
 	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return fmt.Errorf("failed to hash password")
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
 	user.PasswordHash = string(hashed)
-	return s.repo.UpdatePassword(user)
+	return s.repo.UpdatePassword(ctx, user)
 }
@@ -1,16 +1,27 @@
 package services
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/repositories"
 )
 
 type CategoryService interface {
-	GetAll() ([]models.Category, error)
-	GetByID(id int) (*models.Category, error)
-	Create(cat *models.Category) (models.Category, error)
-	Update(cat *models.Category) (models.Category, error)
-	Delete(id int) error
+	GetAll(ctx context.Context, columns ...string) ([]models.Category, error)
+	GetByID(ctx context.Context, id int, columns ...string) (*models.Category, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.Category, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Category) error) error
+	Create(ctx context.Context, cat *models.Category) (models.Category, error)
+	Update(ctx context.Context, cat *models.Category) (models.Category, error)
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Category, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []models.Category, opts models.ImportOptions) ([]ImportOutcome, error)
+	// Locked runs fn against a service bound to one transaction that holds the write
+	// lock of category id, so a check and the write that depends on it cannot race
+	Locked(ctx context.Context, id int, fn func(CategoryService) error) error
 }
 
 type categoryService struct {
@@ -21,22 +32,57 @@ func NewCategoryService(repo repositories.CategoryRepository) CategoryService {
 	return &categoryService{repo}
 }
 
-func (s *categoryService) GetAll() ([]models.Category, error) {
-	return s.repo.GetAll()
+func (s *categoryService) GetAll(ctx context.Context, columns ...string) ([]models.Category, error) {
+	return s.repo.GetAll(ctx, columns...)
+}
+
+func (s *categoryService) StreamAll(ctx context.Context, columns []string, fn func(models.Category) error) error {
+	return s.repo.StreamAll(ctx, columns, fn)
+}
+
+func (s *categoryService) GetByIDs(ctx context.Context, ids []int) ([]models.Category, error) {
+	return s.repo.GetByIDs(ctx, ids)
+}
+
+func (s *categoryService) GetByID(ctx context.Context, id int, columns ...string) (*models.Category, error) {
+	return s.repo.GetByID(ctx, id, columns...)
+}
+
+func (s *categoryService) Create(ctx context.Context, cat *models.Category) (models.Category, error) {
+	cat.SoftDelete = models.SoftDelete{}
+	return s.repo.Create(ctx, cat)
+}
+
+func (s *categoryService) Update(ctx context.Context, cat *models.Category) (models.Category, error) {
+	cat.SoftDelete = models.SoftDelete{}
+	return s.repo.Update(ctx, cat)
+}
+
+func (s *categoryService) Delete(ctx context.Context, id, deletedBy int) error {
+	return s.repo.Delete(ctx, id, deletedBy)
+}
+
+func (s *categoryService) Trash(ctx context.Context, page, limit int) ([]models.Category, int64, error) {
+	return s.repo.Trash(ctx, page, limit)
 }
 
-func (s *categoryService) GetByID(id int) (*models.Category, error) {
-	return s.repo.GetByID(id)
+func (s *categoryService) Restore(ctx context.Context, id int) error {
+	return s.repo.Restore(ctx, id)
 }
 
-func (s *categoryService) Create(cat *models.Category) (models.Category, error) {
-	return s.repo.Create(cat)
+func (s *categoryService) Purge(ctx context.Context, id int) error {
+	return s.repo.Purge(ctx, id)
 }
 
-func (s *categoryService) Update(cat *models.Category) (models.Category, error) {
-	return s.repo.Update(cat)
+func (s *categoryService) Import(ctx context.Context, rows []models.Category, opts models.ImportOptions) ([]ImportOutcome, error) {
+	for i := range rows {
+		rows[i].SoftDelete = models.SoftDelete{}
+	}
+	return s.repo.Import(ctx, rows, opts)
 }
 
-func (s *categoryService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *categoryService) Locked(ctx context.Context, id int, fn func(CategoryService) error) error {
+	return s.repo.Locked(ctx, id, func(repo repositories.CategoryRepository) error {
+		return fn(NewCategoryService(repo))
+	})
 }
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/redis"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	redislib "github.com/redis/go-redis/v9"
+)
+
+const (
+	dashboardKeyPrefix = "dashboard:"
+	dashboardTopN      = 5
+)
+
+// CacheInvalidator is implemented by services that cache data derived from other writes
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context) error
+}
+
+// invalidate clears a dependent cache; a stale cache is not worth failing a committed write,
+// nor is a client that went away after the commit
+func invalidate(ctx context.Context, cache CacheInvalidator) {
+	if cache == nil {
+		return
+	}
+	if err := cache.Invalidate(context.WithoutCancel(ctx)); err != nil {
+		slog.ErrorContext(ctx, "Failed to invalidate cache", "error", err)
+	}
+}
+
+type DashboardService interface {
+	CacheInvalidator
+	Get(ctx context.Context, asOf time.Time) (*models.Dashboard, error)
+}
+
+type dashboardService struct {
+	repo  repositories.DashboardRepository
+	redis *redislib.Client
+	ttl   time.Duration
+}
+
+func NewDashboardService(repo repositories.DashboardRepository, redis *redislib.Client, ttl time.Duration) DashboardService {
+	return &dashboardService{repo: repo, redis: redis, ttl: ttl}
+}
+
+// Get returns the KPIs for the month containing asOf, served from Redis while fresh
+func (s *dashboardService) Get(ctx context.Context, asOf time.Time) (*models.Dashboard, error) {
+	key := dashboardKeyPrefix + asOf.Format("2006-01-02")
+
+	var cached models.Dashboard
+	if hit, err := redis.GetJSON(ctx, s.redis, key, &cached); err == nil && hit {
+		return &cached, nil
+	}
+
+	dashboard, err := s.compute(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write only costs the next caller a recompute
+	_ = redis.SetJSON(ctx, s.redis, key, dashboard, s.ttl)
+	return dashboard, nil
+}
+
+// Invalidate drops every cached dashboard, called after orders or products change
+func (s *dashboardService) Invalidate(ctx context.Context) error {
+	return redis.DeleteByPattern(ctx, s.redis, dashboardKeyPrefix+"*")
+}
+
+func (s *dashboardService) compute(ctx context.Context, asOf time.Time) (*models.Dashboard, error) {
+	monthStart := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, asOf.Location())
+	dayEnd := time.Date(asOf.Year(), asOf.Month(), asOf.Day()+1, 0, 0, 0, 0, asOf.Location())
+	thisMonth := models.DateRange{From: monthStart, To: dayEnd}
+	lastMonth := models.DateRange{From: monthStart.AddDate(0, -1, 0), To: monthStart}
+
+	revenue, orders, err := s.repo.Revenue(ctx, thisMonth)
+	if err != nil {
+		return nil, err
+	}
+	lastRevenue, _, err := s.repo.Revenue(ctx, lastMonth)
+	if err != nil {
+		return nil, err
+	}
+	topProducts, err := s.repo.TopProducts(ctx, thisMonth, dashboardTopN)
+	if err != nil {
+		return nil, err
+	}
+	topCustomers, err := s.repo.TopCustomers(ctx, thisMonth, dashboardTopN)
+	if err != nil {
+		return nil, err
+	}
+	late, err := s.repo.LateShipments(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	lowStock, err := s.repo.LowStockCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &models.Dashboard{
+		RevenueThisMonth: revenue,
+		RevenueLastMonth: lastRevenue,
+		OrderCount:       orders,
+		TopProducts:      topProducts,
+		TopCustomers:     topCustomers,
+		LateShipments:    late,
+		LowStockCount:    lowStock,
+		GeneratedAt:      time.Now(),
+	}
+	if orders > 0 {
+		dashboard.AverageOrderValue = revenue / float64(orders)
+	}
+	return dashboard, nil
+}
@@ -1,17 +1,29 @@
 package services
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/repositories"
 )
 
 type EmployeeService interface {
-	GetAll() ([]models.Employee, error)
-	GetAllPaginated(page, limit int) ([]models.Employee, int64, error)
-	GetByID(id int) (*models.Employee, error)
-	Create(emp *models.Employee) (models.Employee, error)
-	Update(emp *models.Employee) (models.Employee, error)
-	Delete(id int) error
+	GetAll(ctx context.Context, columns ...string) ([]models.Employee, error)
+	GetAllPaginated(ctx context.Context, page, limit int, columns ...string) ([]models.Employee, int64, error)
+	GetByID(ctx context.Context, id int, columns ...string) (*models.Employee, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.Employee, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Employee) error) error
+	Create(ctx context.Context, emp *models.Employee) (models.Employee, error)
+	Update(ctx context.Context, emp *models.Employee) (models.Employee, error)
+	Patch(ctx context.Context, emp *models.Employee, columns []string) (models.Employee, error)
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Employee, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []models.Employee, opts models.ImportOptions) ([]ImportOutcome, error)
+	// Locked runs fn against a service bound to one transaction that holds the write
+	// lock of employee id, so a check such as If-Match and the write cannot race
+	Locked(ctx context.Context, id int, fn func(EmployeeService) error) error
 }
 
 type employeeService struct {
@@ -22,26 +34,66 @@ func NewEmployeeService(repo repositories.EmployeeRepository) EmployeeService {
 	return &employeeService{repo}
 }
 
-func (s *employeeService) GetAll() ([]models.Employee, error) {
-	return s.repo.GetAll()
+func (s *employeeService) GetAll(ctx context.Context, columns ...string) ([]models.Employee, error) {
+	return s.repo.GetAll(ctx, columns...)
+}
+
+func (s *employeeService) GetAllPaginated(ctx context.Context, page, limit int, columns ...string) ([]models.Employee, int64, error) {
+	return s.repo.GetPaginated(ctx, page, limit, columns...)
+}
+
+func (s *employeeService) StreamAll(ctx context.Context, columns []string, fn func(models.Employee) error) error {
+	return s.repo.StreamAll(ctx, columns, fn)
+}
+
+func (s *employeeService) GetByIDs(ctx context.Context, ids []int) ([]models.Employee, error) {
+	return s.repo.GetByIDs(ctx, ids)
+}
+
+func (s *employeeService) GetByID(ctx context.Context, id int, columns ...string) (*models.Employee, error) {
+	return s.repo.GetByID(ctx, id, columns...)
+}
+
+func (s *employeeService) Create(ctx context.Context, emp *models.Employee) (models.Employee, error) {
+	emp.SoftDelete = models.SoftDelete{}
+	return s.repo.Create(ctx, emp)
+}
+
+func (s *employeeService) Update(ctx context.Context, emp *models.Employee) (models.Employee, error) {
+	emp.SoftDelete = models.SoftDelete{}
+	return s.repo.Update(ctx, emp)
+}
+
+func (s *employeeService) Patch(ctx context.Context, emp *models.Employee, columns []string) (models.Employee, error) {
+	emp.SoftDelete = models.SoftDelete{}
+	return s.repo.UpdateColumns(ctx, emp, columns)
+}
+
+func (s *employeeService) Delete(ctx context.Context, id, deletedBy int) error {
+	return s.repo.Delete(ctx, id, deletedBy)
 }
 
-func (s *employeeService) GetAllPaginated(page, limit int) ([]models.Employee, int64, error) {
-	return s.repo.GetPaginated(page, limit)
+func (s *employeeService) Trash(ctx context.Context, page, limit int) ([]models.Employee, int64, error) {
+	return s.repo.Trash(ctx, page, limit)
 }
 
-func (s *employeeService) GetByID(id int) (*models.Employee, error) {
-	return s.repo.GetByID(id)
+func (s *employeeService) Restore(ctx context.Context, id int) error {
+	return s.repo.Restore(ctx, id)
 }
 
-func (s *employeeService) Create(emp *models.Employee) (models.Employee, error) {
-	return s.repo.Create(emp)
+func (s *employeeService) Purge(ctx context.Context, id int) error {
+	return s.repo.Purge(ctx, id)
 }
 
-func (s *employeeService) Update(emp *models.Employee) (models.Employee, error) {
-	return s.repo.Update(emp)
+func (s *employeeService) Import(ctx context.Context, rows []models.Employee, opts models.ImportOptions) ([]ImportOutcome, error) {
+	for i := range rows {
+		rows[i].SoftDelete = models.SoftDelete{}
+	}
+	return s.repo.Import(ctx, rows, opts)
 }
 
-func (s *employeeService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *employeeService) Locked(ctx context.Context, id int, fn func(EmployeeService) error) error {
+	return s.repo.Locked(ctx, id, func(repo repositories.EmployeeRepository) error {
+		return fn(NewEmployeeService(repo))
+	})
 }
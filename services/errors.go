@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+	"gorm.io/gorm"
+)
+
+// Kind classifies a domain error; the HTTP error handler and the gRPC
+// interceptor each map a kind to their own status code
+type Kind string
+
+const (
+	KindInvalid       Kind = "invalid"
+	KindUnauthorized  Kind = "unauthorized"
+	KindForbidden     Kind = "forbidden"
+	KindNotFound      Kind = "not_found"
+	KindConflict      Kind = "conflict"
+	KindPrecondition  Kind = "precondition_failed"
+	KindTooLarge      Kind = "too_large"
+	KindUnsupported   Kind = "unsupported"
+	KindUnprocessable Kind = "unprocessable"
+)
+
+// Error lets a Kind be used as errors.Is target, e.g. errors.Is(err, services.KindNotFound)
+func (k Kind) Error() string { return string(k) }
+
+// Stable machine readable error codes, clients may switch on them
+const (
+	CodeInvalidBody         = "invalid_body"
+	CodeInvalidParameter    = "invalid_parameter"
+	CodeValidationFailed    = "validation_failed"
+	CodeUnauthorized        = "unauthorized"
+	CodeInvalidCredentials  = "invalid_credentials"
+	CodeInvalidToken        = "invalid_token"
+	CodeTokenRevoked        = "token_revoked"
+	CodeInvalidOTP          = "invalid_otp"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeDuplicateValue      = "duplicate_value"
+	CodeInvalidReference    = "invalid_reference"
+	CodeStillReferenced     = "still_referenced"
+	CodeConstraintViolated  = "constraint_violated"
+	CodeUsernameTaken       = "username_taken"
+	CodeEmailTaken          = "email_taken"
+	CodePreconditionFailed  = "precondition_failed"
+	CodeTooManyOperations   = "too_many_operations"
+	CodeUnsupportedPatch    = "unsupported_patch_format"
+	CodeImportRejected      = "import_rejected"
+	CodeBatchRolledBack     = "batch_rolled_back"
+	CodeIdempotencyMismatch = "idempotency_key_mismatch"
+	CodeTimeout             = "timeout"
+	CodeInternal            = "internal_error"
+)
+
+// Error is a failure that is safe to show to clients. Code is stable, Message is
+// meant for people and Err keeps the cause for the logs only.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details []utils.ErrorDetail
+	Err     error
+}
+
+func NewError(kind Kind, code, message string, details ...utils.ErrorDetail) *Error {
+	return &Error{Kind: kind, Code: code, Message: message, Details: details}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Message + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is matches the error's Kind
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == e.Kind
+}
+
+// Wrap returns a copy of e caused by err
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+func Invalid(code, message string, details ...utils.ErrorDetail) *Error {
+	return NewError(KindInvalid, code, message, details...)
+}
+
+func Unauthorized(code, message string) *Error {
+	return NewError(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return NewError(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return NewError(KindNotFound, code, message)
+}
+
+func Conflict(code, message string, details ...utils.ErrorDetail) *Error {
+	return NewError(KindConflict, code, message, details...)
+}
+
+func Unprocessable(code, message string, details ...utils.ErrorDetail) *Error {
+	return NewError(KindUnprocessable, code, message, details...)
+}
+
+// AsError returns err as a service error, translating the typed repository errors:
+// a missing row is not found, a unique violation a conflict on the offending column,
+// a foreign key or check violation unprocessable and a delete of a row other tables
+// still point at a conflict. It returns nil for unexpected errors, those must be
+// logged rather than shown.
+func AsError(err error) *Error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound(CodeNotFound, "Record not found").Wrap(err)
+	}
+
+	var cerr *repositories.ConstraintError
+	if !errors.As(err, &cerr) {
+		return nil
+	}
+	field := cerr.Column
+	switch cerr.Violation {
+	case repositories.ViolationUnique:
+		if field == "" {
+			field = "id"
+		}
+		message := "already exists"
+		if cerr.Value != "" {
+			message = fmt.Sprintf("(%s) already exists", cerr.Value)
+		}
+		return Conflict(CodeDuplicateValue, "Duplicate value",
+			utils.ErrorDetail{Field: field, Message: message}).Wrap(err)
+	case repositories.ViolationForeignKey:
+		return Unprocessable(CodeInvalidReference, "Referenced row does not exist",
+			utils.ErrorDetail{Field: field, Message: "no matching row in " + cerr.Table}).Wrap(err)
+	case repositories.ViolationReference:
+		return Conflict(CodeStillReferenced, "Row is still referenced by "+cerr.Table,
+			utils.ErrorDetail{Field: field, Message: "still used by rows in " + cerr.Table}).Wrap(err)
+	default:
+		return Unprocessable(CodeConstraintViolated, "Value rejected by the database",
+			utils.ErrorDetail{Field: field, Message: "violates " + cerr.Constraint}).Wrap(err)
+	}
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+)
+
+// EventPublisher receives the domain events raised by the services
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+// Publishers hands every event to each publisher, e.g. webhooks and the live stream
+type Publishers []EventPublisher
+
+func (ps Publishers) Publish(ctx context.Context, event models.Event) error {
+	var errs []error
+	for _, p := range ps {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publish raises an event from inside the transaction of its write, see Outbox.
+// A failure to queue its webhook deliveries fails the write.
+func publish(ctx context.Context, publisher EventPublisher, eventType string, data interface{}) error {
+	if publisher == nil {
+		return nil
+	}
+	event := models.Event{ID: newEventID(), Type: eventType, OccurredAt: time.Now().UTC(), Data: data}
+	return publisher.Publish(ctx, event)
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
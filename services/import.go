@@ -0,0 +1,11 @@
+package services
+
+import "github.com/azizmasr1001/go-api-northwind/repositories"
+
+// ImportOutcome reports what a bulk import did with one row
+type ImportOutcome = repositories.ImportOutcome
+
+const (
+	ImportCreated = repositories.ImportCreated
+	ImportUpdated = repositories.ImportUpdated
+)
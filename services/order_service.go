@@ -1,51 +1,151 @@
 package services
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"gorm.io/gorm"
 )
 
 type OrderService interface {
-	Create(order *models.Order, details []models.OrderDetail) (*models.Order, error)
-	GetAll(page, limit int) ([]models.Order, int64, error)
-	GetByID(id int) (*models.Order, []models.OrderDetail, error)
-	Update(id int, order *models.Order, details []models.OrderDetail) (*models.Order, error)
-	Delete(id int) error
+	Create(ctx context.Context, order *models.Order, details []models.OrderDetail) (*models.Order, error)
+	GetAll(ctx context.Context, page, limit int, columns ...string) ([]models.Order, int64, error)
+	GetByID(ctx context.Context, id int, orderColumns, detailColumns []string) (*models.Order, []models.OrderDetail, error)
+	GetDetailsByOrderIDs(ctx context.Context, ids []int) ([]models.OrderDetail, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Order) error) error
+	Update(ctx context.Context, id int, order *models.Order, details []models.OrderDetail) (*models.Order, error)
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Order, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	// Locked runs fn against a service bound to one transaction that holds the write
+	// lock of order id, so a check such as If-Match and the write cannot race
+	Locked(ctx context.Context, id int, fn func(OrderService) error) error
 }
 
 type orderService struct {
-	repo repositories.OrderRepository
+	repo   repositories.OrderRepository
+	cache  CacheInvalidator
+	events EventPublisher
+	// outbox is nil once the service is bound to a transaction
+	outbox *Outbox
+}
+
+func NewOrderService(repo repositories.OrderRepository, cache CacheInvalidator, outbox *Outbox) OrderService {
+	return &orderService{repo: repo, cache: cache, outbox: outbox}
+}
+
+// atomic runs fn against the service bound to a transaction, the open one or a
+// new one from the outbox, so the events fn raises commit with its write
+func (s *orderService) atomic(ctx context.Context, fn func(*orderService) error) error {
+	if s.outbox == nil {
+		return fn(s)
+	}
+	return s.outbox.Transaction(ctx, func(tx *gorm.DB, cache CacheInvalidator, events EventPublisher) error {
+		return fn(&orderService{repo: repositories.NewOrderRepository(tx), cache: cache, events: events})
+	})
 }
 
-func NewOrderService(repo repositories.OrderRepository) OrderService {
-	return &orderService{repo}
+// orderEvent is the data of order.* events
+type orderEvent struct {
+	Order   *models.Order        `json:"order"`
+	Details []models.OrderDetail `json:"details"`
 }
 
-func (s *orderService) Create(order *models.Order, details []models.OrderDetail) (*models.Order, error) {
-	err := s.repo.CreateOrderWithDetails(order, details)
+func (s *orderService) Create(ctx context.Context, order *models.Order, details []models.OrderDetail) (*models.Order, error) {
+	order.SoftDelete = models.SoftDelete{}
+	err := s.atomic(ctx, func(s *orderService) error {
+		if err := s.repo.CreateOrderWithDetails(ctx, order, details); err != nil {
+			return err
+		}
+		invalidate(ctx, s.cache)
+		return publish(ctx, s.events, models.EventOrderCreated, orderEvent{Order: order, Details: withOrderID(details, order.OrderID)})
+	})
 	if err != nil {
 		return nil, err
 	}
 	return order, nil
 }
 
-func (s *orderService) GetAll(page, limit int) ([]models.Order, int64, error) {
-	return s.repo.GetAll(page, limit)
+func (s *orderService) GetAll(ctx context.Context, page, limit int, columns ...string) ([]models.Order, int64, error) {
+	return s.repo.GetAll(ctx, page, limit, columns...)
 }
 
-func (s *orderService) GetByID(id int) (*models.Order, []models.OrderDetail, error) {
-	return s.repo.GetByID(id)
+func (s *orderService) StreamAll(ctx context.Context, columns []string, fn func(models.Order) error) error {
+	return s.repo.StreamAll(ctx, columns, fn)
 }
 
-func (s *orderService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *orderService) GetByID(ctx context.Context, id int, orderColumns, detailColumns []string) (*models.Order, []models.OrderDetail, error) {
+	return s.repo.GetByID(ctx, id, orderColumns, detailColumns)
 }
 
-func (s *orderService) Update(id int, order *models.Order, details []models.OrderDetail) (*models.Order, error) {
-	err := s.repo.Update(id, order, details)
+func (s *orderService) GetDetailsByOrderIDs(ctx context.Context, ids []int) ([]models.OrderDetail, error) {
+	return s.repo.GetDetailsByOrderIDs(ctx, ids)
+}
+
+func (s *orderService) Delete(ctx context.Context, id, deletedBy int) error {
+	if err := s.repo.Delete(ctx, id, deletedBy); err != nil {
+		return err
+	}
+	invalidate(ctx, s.cache)
+	return nil
+}
+
+func (s *orderService) Trash(ctx context.Context, page, limit int) ([]models.Order, int64, error) {
+	return s.repo.Trash(ctx, page, limit)
+}
+
+func (s *orderService) Restore(ctx context.Context, id int) error {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return err
+	}
+	invalidate(ctx, s.cache)
+	return nil
+}
+
+func (s *orderService) Purge(ctx context.Context, id int) error {
+	return s.repo.Purge(ctx, id)
+}
+
+func (s *orderService) Locked(ctx context.Context, id int, fn func(OrderService) error) error {
+	return s.atomic(ctx, func(s *orderService) error {
+		return s.repo.Locked(ctx, id, func(repo repositories.OrderRepository) error {
+			return fn(&orderService{repo: repo, cache: s.cache, events: s.events})
+		})
+	})
+}
+
+func (s *orderService) Update(ctx context.Context, id int, order *models.Order, details []models.OrderDetail) (*models.Order, error) {
+	order.SoftDelete = models.SoftDelete{}
+	err := s.atomic(ctx, func(s *orderService) error {
+		before, _, _ := s.repo.GetByID(ctx, id, []string{"OrderID", "ShippedDate"}, []string{"OrderID", "ProductID"})
+		if err := s.repo.Update(ctx, id, order, details); err != nil {
+			return err
+		}
+		invalidate(ctx, s.cache)
+		order.OrderID = id
+		data := orderEvent{Order: order, Details: withOrderID(details, id)}
+		if err := publish(ctx, s.events, models.EventOrderUpdated, data); err != nil {
+			return err
+		}
+		if order.ShippedDate != nil && (before == nil || before.ShippedDate == nil) {
+			return publish(ctx, s.events, models.EventOrderShipped, data)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	order.OrderID = id
 	return order, nil
 }
+
+// withOrderID copies details with the parent id set, the repository writes them by value
+func withOrderID(details []models.OrderDetail, id int) []models.OrderDetail {
+	out := make([]models.OrderDetail, len(details))
+	for i, d := range details {
+		d.OrderID = id
+		out[i] = d
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"gorm.io/gorm"
+)
+
+// Outbox opens the transactions of the writes that raise events. The webhook
+// deliveries of an event are inserted in the transaction of its write, so they
+// commit or roll back with it. Cache invalidation and the live publishers (the
+// stream and metrics) wait for the commit.
+type Outbox struct {
+	db    *gorm.DB
+	cache CacheInvalidator
+	live  EventPublisher
+}
+
+func NewOutbox(db *gorm.DB, cache CacheInvalidator, live EventPublisher) *Outbox {
+	return &Outbox{db: db, cache: cache, live: live}
+}
+
+// Transaction runs fn on tx with a cache invalidator and a publisher bound to it.
+// An error from fn, publishing included, rolls everything back.
+func (o *Outbox) Transaction(ctx context.Context, fn func(tx *gorm.DB, cache CacheInvalidator, events EventPublisher) error) error {
+	pending := &afterCommit{}
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(tx, pending, txEvents{
+			webhooks: NewWebhookService(repositories.NewWebhookRepository(tx)),
+			pending:  pending,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	pending.flush(ctx, o.cache, o.live)
+	return nil
+}
+
+// txEvents queues the webhook deliveries of an event on the transaction and
+// keeps the event for the live publishers
+type txEvents struct {
+	webhooks EventPublisher
+	pending  *afterCommit
+}
+
+func (e txEvents) Publish(ctx context.Context, event models.Event) error {
+	if err := e.webhooks.Publish(ctx, event); err != nil {
+		return err
+	}
+	return e.pending.Publish(ctx, event)
+}
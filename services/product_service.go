@@ -1,52 +1,259 @@
 package services
 
 import (
+	"context"
+
 	"github.com/azizmasr1001/go-api-northwind/models"
 	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"gorm.io/gorm"
 )
 
 type ProductService interface {
-	GetAll() ([]models.Product, error)
-	GetAllPaginated(page, limit int) ([]models.Product, int64, error)
-	SearchByName(name string, page, limit int) ([]models.Product, int64, error)
-	GetByID(id int) (*models.Product, error)
-	Create(prod *models.Product) (*models.Product, error)
-	Update(prod *models.Product) (*models.Product, error)
-	Delete(id int) error
+	GetAll(ctx context.Context, columns ...string) ([]models.Product, error)
+	GetAllPaginated(ctx context.Context, page, limit int, columns ...string) ([]models.Product, int64, error)
+	SearchByName(ctx context.Context, name string, page, limit int, columns ...string) ([]models.Product, int64, error)
+	GetByID(ctx context.Context, id int, columns ...string) (*models.Product, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.Product, error)
+	StreamAll(ctx context.Context, columns []string, fn func(models.Product) error) error
+	Create(ctx context.Context, prod *models.Product) (*models.Product, error)
+	Update(ctx context.Context, prod *models.Product) (*models.Product, error)
+	Patch(ctx context.Context, prod *models.Product, columns []string) (*models.Product, error)
+	Delete(ctx context.Context, id, deletedBy int) error
+	Trash(ctx context.Context, page, limit int) ([]models.Product, int64, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	Import(ctx context.Context, rows []models.Product, opts models.ImportOptions) ([]ImportOutcome, error)
+	// Locked runs fn against a service bound to one transaction that holds the write
+	// lock of product id, so a check such as If-Match and the write cannot race
+	Locked(ctx context.Context, id int, fn func(ProductService) error) error
 }
 
 type productServiceImpl struct {
-	repo repositories.ProductRepository
+	repo   repositories.ProductRepository
+	cache  CacheInvalidator
+	events EventPublisher
+	// outbox is nil once the service is bound to a transaction
+	outbox *Outbox
+}
+
+func NewProductService(repo repositories.ProductRepository, cache CacheInvalidator, outbox *Outbox) ProductService {
+	return &productServiceImpl{repo: repo, cache: cache, outbox: outbox}
+}
+
+// atomic runs fn against the service bound to a transaction, the open one or a
+// new one from the outbox, so the events fn raises commit with its write
+func (s *productServiceImpl) atomic(ctx context.Context, fn func(*productServiceImpl) error) error {
+	if s.outbox == nil {
+		return fn(s)
+	}
+	return s.outbox.Transaction(ctx, func(tx *gorm.DB, cache CacheInvalidator, events EventPublisher) error {
+		return fn(&productServiceImpl{repo: repositories.NewProductRepository(tx), cache: cache, events: events})
+	})
+}
+
+func (s *productServiceImpl) GetAll(ctx context.Context, columns ...string) ([]models.Product, error) {
+	return s.repo.GetAll(ctx, columns...)
+}
+
+func (s *productServiceImpl) GetAllPaginated(ctx context.Context, page, limit int, columns ...string) ([]models.Product, int64, error) {
+	return s.repo.GetPaginated(ctx, page, limit, columns...)
+}
+
+func (s *productServiceImpl) SearchByName(ctx context.Context, name string, page, limit int, columns ...string) ([]models.Product, int64, error) {
+	return s.repo.SearchByName(ctx, name, page, limit, columns...)
+}
+
+func (s *productServiceImpl) StreamAll(ctx context.Context, columns []string, fn func(models.Product) error) error {
+	return s.repo.StreamAll(ctx, columns, fn)
 }
 
-func NewProductService(repo repositories.ProductRepository) ProductService {
-	return &productServiceImpl{repo}
+func (s *productServiceImpl) GetByIDs(ctx context.Context, ids []int) ([]models.Product, error) {
+	return s.repo.GetByIDs(ctx, ids)
 }
 
-func (s *productServiceImpl) GetAll() ([]models.Product, error) {
-	return s.repo.GetAll()
+func (s *productServiceImpl) GetByID(ctx context.Context, id int, columns ...string) (*models.Product, error) {
+	return s.repo.GetByID(ctx, id, columns...)
 }
 
-func (s *productServiceImpl) GetAllPaginated(page, limit int) ([]models.Product, int64, error) {
-	return s.repo.GetPaginated(page, limit)
+func (s *productServiceImpl) Create(ctx context.Context, prod *models.Product) (*models.Product, error) {
+	prod.SoftDelete = models.SoftDelete{}
+	created, err := s.repo.Create(ctx, prod)
+	if err != nil {
+		return nil, err
+	}
+	invalidate(ctx, s.cache)
+	return created, nil
 }
 
-func (s *productServiceImpl) SearchByName(name string, page, limit int) ([]models.Product, int64, error) {
-	return s.repo.SearchByName(name, page, limit)
+func (s *productServiceImpl) Update(ctx context.Context, prod *models.Product) (*models.Product, error) {
+	prod.SoftDelete = models.SoftDelete{}
+	var updated *models.Product
+	err := s.atomic(ctx, func(s *productServiceImpl) error {
+		before, err := s.repo.GetByID(ctx, prod.ProductID, "ProductID", "UnitsInStock")
+		known := err == nil
+
+		updated, err = s.repo.Update(ctx, prod)
+		if err != nil {
+			return err
+		}
+		invalidate(ctx, s.cache)
+		if !known || before.UnitsInStock != updated.UnitsInStock {
+			return s.publishStock(ctx, updated, !known || before.UnitsInStock > 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *productServiceImpl) Patch(ctx context.Context, prod *models.Product, columns []string) (*models.Product, error) {
+	prod.SoftDelete = models.SoftDelete{}
+	var updated *models.Product
+	err := s.atomic(ctx, func(s *productServiceImpl) error {
+		var err error
+		updated, err = s.repo.UpdateColumns(ctx, prod, columns)
+		if err != nil {
+			return err
+		}
+		if len(columns) > 0 {
+			invalidate(ctx, s.cache)
+		}
+		for _, column := range columns {
+			if column == "UnitsInStock" {
+				return s.publishStock(ctx, updated, true)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// publishStock raises product.stock_changed, plus product.out_of_stock when
+// the write took the last units of a product that had stock
+func (s *productServiceImpl) publishStock(ctx context.Context, prod *models.Product, hadStock bool) error {
+	if err := publish(ctx, s.events, models.EventProductStockChanged, prod); err != nil {
+		return err
+	}
+	if hadStock && prod.UnitsInStock <= 0 {
+		return publish(ctx, s.events, models.EventProductOutOfStock, prod)
+	}
+	return nil
+}
+
+func (s *productServiceImpl) Delete(ctx context.Context, id, deletedBy int) error {
+	if err := s.repo.Delete(ctx, id, deletedBy); err != nil {
+		return err
+	}
+	invalidate(ctx, s.cache)
+	return nil
 }
 
-func (s *productServiceImpl) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
+func (s *productServiceImpl) Trash(ctx context.Context, page, limit int) ([]models.Product, int64, error) {
+	return s.repo.Trash(ctx, page, limit)
 }
 
-func (s *productServiceImpl) Create(prod *models.Product) (*models.Product, error) {
-	return s.repo.Create(prod)
+func (s *productServiceImpl) Restore(ctx context.Context, id int) error {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return err
+	}
+	invalidate(ctx, s.cache)
+	return nil
 }
 
-func (s *productServiceImpl) Update(prod *models.Product) (*models.Product, error) {
-	return s.repo.Update(prod)
+func (s *productServiceImpl) Purge(ctx context.Context, id int) error {
+	return s.repo.Purge(ctx, id)
 }
 
-func (s *productServiceImpl) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *productServiceImpl) Locked(ctx context.Context, id int, fn func(ProductService) error) error {
+	return s.atomic(ctx, func(s *productServiceImpl) error {
+		return s.repo.Locked(ctx, id, func(repo repositories.ProductRepository) error {
+			return fn(&productServiceImpl{repo: repo, cache: s.cache, events: s.events})
+		})
+	})
+}
+
+func (s *productServiceImpl) Import(ctx context.Context, rows []models.Product, opts models.ImportOptions) ([]ImportOutcome, error) {
+	for i := range rows {
+		rows[i].SoftDelete = models.SoftDelete{}
+	}
+	var outcomes []ImportOutcome
+	err := s.atomic(ctx, func(s *productServiceImpl) error {
+		stock, err := s.stockBefore(ctx, rows, opts.Key)
+		if err != nil {
+			return err
+		}
+		outcomes, err = s.repo.Import(ctx, rows, opts)
+		if err != nil || opts.DryRun {
+			return err
+		}
+		invalidate(ctx, s.cache)
+		for _, outcome := range outcomes {
+			if opts.Atomic && outcome.Err != nil {
+				return nil
+			}
+		}
+
+		for i, outcome := range outcomes {
+			prod := &rows[i]
+			before, known := stock[prod.ProductID]
+			switch {
+			case outcome.Action == repositories.ImportCreated:
+				stock[prod.ProductID] = prod.UnitsInStock
+			case outcome.Action == repositories.ImportUpdated:
+				stock[prod.ProductID] = prod.UnitsInStock
+				if !known || before != prod.UnitsInStock {
+					if err := s.publishStock(ctx, prod, !known || before > 0); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
+// stockBefore reads the stock of the products an import may update, by product id
+func (s *productServiceImpl) stockBefore(ctx context.Context, rows []models.Product, key string) (map[int]int, error) {
+	var (
+		existing []models.Product
+		err      error
+	)
+	switch key {
+	case "id":
+		ids := make([]int, 0, len(rows))
+		for _, row := range rows {
+			if row.ProductID != 0 {
+				ids = append(ids, row.ProductID)
+			}
+		}
+		if len(ids) > 0 {
+			existing, err = s.repo.GetByIDs(ctx, ids)
+		}
+	case "name":
+		names := make([]string, 0, len(rows))
+		for _, row := range rows {
+			names = append(names, row.ProductName)
+		}
+		if len(names) > 0 {
+			existing, err = s.repo.GetByNames(ctx, names)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stock := make(map[int]int, len(existing))
+	for _, prod := range existing {
+		stock[prod.ProductID] = prod.UnitsInStock
+	}
+	return stock, nil
 }
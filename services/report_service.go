@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+)
+
+type ReportService interface {
+	SalesByCategory(ctx context.Context, r models.DateRange) ([]models.CategorySales, error)
+	ProductSales(ctx context.Context, r models.DateRange) ([]models.ProductSales, error)
+	SalesByEmployee(ctx context.Context, r models.DateRange) ([]models.EmployeeSales, error)
+	QuarterlyOrders(ctx context.Context, r models.DateRange) ([]models.QuarterlyOrders, error)
+	OrderSubtotals(ctx context.Context, r models.DateRange) ([]models.OrderSubtotal, error)
+	MostExpensiveProducts(ctx context.Context, limit int) ([]models.ExpensiveProduct, error)
+}
+
+type reportService struct {
+	repo repositories.ReportRepository
+}
+
+func NewReportService(repo repositories.ReportRepository) ReportService {
+	return &reportService{repo}
+}
+
+func (s *reportService) SalesByCategory(ctx context.Context, r models.DateRange) ([]models.CategorySales, error) {
+	return s.repo.SalesByCategory(ctx, r)
+}
+
+func (s *reportService) ProductSales(ctx context.Context, r models.DateRange) ([]models.ProductSales, error) {
+	return s.repo.ProductSales(ctx, r)
+}
+
+func (s *reportService) SalesByEmployee(ctx context.Context, r models.DateRange) ([]models.EmployeeSales, error) {
+	return s.repo.SalesByEmployee(ctx, r)
+}
+
+func (s *reportService) QuarterlyOrders(ctx context.Context, r models.DateRange) ([]models.QuarterlyOrders, error) {
+	return s.repo.QuarterlyOrders(ctx, r)
+}
+
+func (s *reportService) OrderSubtotals(ctx context.Context, r models.DateRange) ([]models.OrderSubtotal, error) {
+	return s.repo.OrderSubtotals(ctx, r)
+}
+
+func (s *reportService) MostExpensiveProducts(ctx context.Context, limit int) ([]models.ExpensiveProduct, error) {
+	return s.repo.MostExpensiveProducts(ctx, limit)
+}
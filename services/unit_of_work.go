@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"gorm.io/gorm"
+)
+
+// Services groups the resource services a batch writes through
+type Services struct {
+	Employees  EmployeeService
+	Categories CategoryService
+	Products   ProductService
+	Orders     OrderService
+}
+
+// UnitOfWork hands out Services, either the shared ones or a set bound to one transaction
+type UnitOfWork interface {
+	Services() Services
+	// Transaction runs fn against services that share one database transaction.
+	// It commits when fn returns nil; webhook deliveries are queued in the transaction,
+	// cache invalidation and live events wait for the commit.
+	Transaction(ctx context.Context, fn func(Services) error) error
+}
+
+type unitOfWork struct {
+	outbox *Outbox
+	shared Services
+}
+
+func NewUnitOfWork(outbox *Outbox, shared Services) UnitOfWork {
+	return &unitOfWork{outbox: outbox, shared: shared}
+}
+
+func (u *unitOfWork) Services() Services {
+	return u.shared
+}
+
+func (u *unitOfWork) Transaction(ctx context.Context, fn func(Services) error) error {
+	return u.outbox.Transaction(ctx, func(tx *gorm.DB, cache CacheInvalidator, events EventPublisher) error {
+		return fn(Services{
+			Employees:  NewEmployeeService(repositories.NewEmployeeRepository(tx)),
+			Categories: NewCategoryService(repositories.NewCategoryRepository(tx)),
+			Products:   &productServiceImpl{repo: repositories.NewProductRepository(tx), cache: cache, events: events},
+			Orders:     &orderService{repo: repositories.NewOrderRepository(tx), cache: cache, events: events},
+		})
+	})
+}
+
+// afterCommit collects the side effects raised inside a transaction so a
+// rolled back write never clears caches or reaches the live publishers
+type afterCommit struct {
+	mu         sync.Mutex
+	invalidate bool
+	events     []models.Event
+}
+
+func (a *afterCommit) Invalidate(ctx context.Context) error {
+	a.mu.Lock()
+	a.invalidate = true
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *afterCommit) Publish(ctx context.Context, event models.Event) error {
+	a.mu.Lock()
+	a.events = append(a.events, event)
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *afterCommit) flush(ctx context.Context, cache CacheInvalidator, events EventPublisher) {
+	if a.invalidate {
+		invalidate(ctx, cache)
+	}
+	if events == nil {
+		return
+	}
+	for _, event := range a.events {
+		if err := events.Publish(context.WithoutCancel(ctx), event); err != nil {
+			slog.ErrorContext(ctx, "Failed to publish event", "event", event.Type, "error", err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/tracing"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers sent with every webhook. X-Webhook-Id is the event ID and stays the
+// same across retries, so receivers can drop duplicates.
+const (
+	HeaderWebhookID        = "X-Webhook-Id"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+
+	maxWebhookError = 1000
+)
+
+type WebhookDispatcherConfig struct {
+	MaxAttempts int           // attempts before a delivery is dead-lettered
+	RetryBase   time.Duration // delay after the first failure, doubled on every retry
+	RetryMax    time.Duration // cap for the retry delay
+	Lease       time.Duration // how long a worker owns a claimed batch, long enough to send all of it by default
+	BatchSize   int           // deliveries claimed per poll
+}
+
+// WebhookDispatcher drains the webhook_deliveries queue
+type WebhookDispatcher struct {
+	repo   repositories.WebhookRepository
+	client *http.Client
+	cfg    WebhookDispatcherConfig
+	now    func() time.Time
+}
+
+func NewWebhookDispatcher(repo repositories.WebhookRepository, client *http.Client, cfg WebhookDispatcherConfig) *WebhookDispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	if cfg.RetryMax <= 0 {
+		cfg.RetryMax = 6 * time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.Lease <= 0 {
+		// the batch is sent one delivery after the other
+		cfg.Lease = time.Duration(cfg.BatchSize)*sendTimeout(client) + time.Minute
+	}
+	return &WebhookDispatcher{repo: repo, client: client, cfg: cfg, now: time.Now}
+}
+
+// Run polls the queue every interval until ctx is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil {
+			slog.Error("Webhook dispatch failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue makes one attempt for every delivery that is due and returns how many it tried.
+// An attempt only starts when it can finish within the lease, the deliveries left over
+// are released for the next poll rather than sent after another worker may have claimed them.
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	claimed := d.now().UTC()
+	deliveries, err := d.repo.ClaimDue(ctx, claimed, d.cfg.Lease, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	leaseEnd := claimed.Add(d.cfg.Lease)
+	for i := range deliveries {
+		if d.now().Add(sendTimeout(d.client)).After(leaseEnd) {
+			return i, d.release(ctx, deliveries[i:])
+		}
+		d.attemptUntil(ctx, leaseEnd, &deliveries[i])
+		if err := d.repo.SaveAttempt(ctx, &deliveries[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attemptUntil makes the attempt with a deadline at the end of the lease
+func (d *WebhookDispatcher) attemptUntil(ctx context.Context, leaseEnd time.Time, delivery *models.WebhookDelivery) {
+	ctx, cancel := context.WithTimeout(ctx, leaseEnd.Sub(d.now()))
+	defer cancel()
+	d.attempt(ctx, delivery)
+}
+
+// release gives up the lease of deliveries that were not attempted
+func (d *WebhookDispatcher) release(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	for i := range deliveries {
+		if err := d.repo.SaveAttempt(ctx, &deliveries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendTimeout is how long one attempt may take, the client's timeout when it has one
+func sendTimeout(client *http.Client) time.Duration {
+	if client.Timeout > 0 {
+		return client.Timeout
+	}
+	return 30 * time.Second
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	sub := delivery.Subscription
+	if sub == nil || !sub.Active {
+		delivery.Status = models.DeliveryDead
+		delivery.LastError = "subscription is no longer active"
+		return
+	}
+
+	delivery.Attempts++
+	status, err := d.send(ctx, sub, delivery)
+	delivery.ResponseStatus = status
+	if err == nil {
+		now := d.now().UTC()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > maxWebhookError {
+		delivery.LastError = delivery.LastError[:maxWebhookError]
+	}
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		delivery.Status = models.DeliveryDead
+		return
+	}
+	delivery.NextAttemptAt = d.now().UTC().Add(RetryDelay(d.cfg.RetryBase, d.cfg.RetryMax, delivery.Attempts))
+}
+
+// send posts the delivery in a client span of its own and passes the trace on in
+// the traceparent header
+func (d *WebhookDispatcher) send(ctx context.Context, sub *models.WebhookSubscription, delivery *models.WebhookDelivery) (status *int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, http.MethodPost,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(http.MethodPost),
+			semconv.URLFull(sub.URL),
+			attribute.String("webhook.event", delivery.EventType),
+			attribute.Int("webhook.delivery_id", delivery.ID),
+			attribute.Int("webhook.attempt", delivery.Attempts),
+		))
+	defer func() {
+		if status != nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(*status))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "northwind-webhooks/1.0")
+	req.Header.Set(HeaderWebhookID, delivery.EventID)
+	req.Header.Set(HeaderWebhookEvent, delivery.EventType)
+	req.Header.Set(HeaderWebhookDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(sub.Secret, d.now().Unix(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	code := resp.StatusCode
+	if code < 200 || code > 299 {
+		return &code, fmt.Errorf("receiver answered %d", code)
+	}
+	return &code, nil
+}
+
+// SignWebhook builds the X-Webhook-Signature value: "t=<unix>,v1=<hex HMAC-SHA256>"
+// over "<unix>.<body>" with the subscription secret. Receivers should recompute it
+// and reject stale timestamps.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// RetryDelay is base * 2^(attempt-1), capped at max
+func RetryDelay(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
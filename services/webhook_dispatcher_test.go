@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// memoryWebhookRepo keeps the queue in memory, enough for the dispatcher
+type memoryWebhookRepo struct {
+	repositories.WebhookRepository
+	subs       []models.WebhookSubscription
+	deliveries []models.WebhookDelivery
+}
+
+func (r *memoryWebhookRepo) ActiveSubscriptions(context.Context) ([]models.WebhookSubscription, error) {
+	return r.subs, nil
+}
+
+func (r *memoryWebhookRepo) EnqueueDeliveries(_ context.Context, deliveries []models.WebhookDelivery) error {
+	for _, d := range deliveries {
+		d.ID = len(r.deliveries) + 1
+		r.deliveries = append(r.deliveries, d)
+	}
+	return nil
+}
+
+func (r *memoryWebhookRepo) ClaimDue(_ context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var due []models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+			for i := range r.subs {
+				if r.subs[i].ID == d.SubscriptionID {
+					d.Subscription = &r.subs[i]
+				}
+			}
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryWebhookRepo) SaveAttempt(_ context.Context, delivery *models.WebhookDelivery) error {
+	r.deliveries[delivery.ID-1] = *delivery
+	return nil
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	delay    time.Duration
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(rc.delay)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, string(body))
+	w.WriteHeader(rc.status)
+}
+
+func setupWebhooks(t *testing.T, status int, cfg WebhookDispatcherConfig) (*memoryWebhookRepo, *receiver, *WebhookDispatcher, *time.Time) {
+	rc := &receiver{status: status}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	repo := &memoryWebhookRepo{subs: []models.WebhookSubscription{
+		{ID: 1, URL: server.URL, Events: []string{models.EventOrderCreated}, Secret: "whsec_test", Active: true},
+		{ID: 2, URL: server.URL, Events: []string{models.EventUserRegistered}, Secret: "whsec_other", Active: true},
+	}}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := NewWebhookDispatcher(repo, server.Client(), cfg)
+	dispatcher.now = func() time.Time { return now }
+
+	publish(t.Context(), NewWebhookService(repo), models.EventOrderCreated, map[string]int{"order_id": 10248})
+	require.Len(t, repo.deliveries, 1)
+	repo.deliveries[0].NextAttemptAt = now
+	return repo, rc, dispatcher, &now
+}
+
+func TestWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	repo, rc, dispatcher, now := setupWebhooks(t, 204, WebhookDispatcherConfig{})
+
+	n, err := dispatcher.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, rc.requests, 1)
+	req := rc.requests[0]
+	assert.Equal(t, models.EventOrderCreated, req.Header.Get(HeaderWebhookEvent))
+	assert.Equal(t, repo.deliveries[0].EventID, req.Header.Get(HeaderWebhookID))
+	assert.Equal(t, SignWebhook("whsec_test", now.Unix(), []byte(rc.bodies[0])), req.Header.Get(HeaderWebhookSignature))
+	assert.True(t, strings.Contains(rc.bodies[0], `"order_id":10248`))
+
+	assert.Equal(t, models.DeliveryDelivered, repo.deliveries[0].Status)
+	assert.Equal(t, 204, *repo.deliveries[0].ResponseStatus)
+}
+
+func TestWebhookDispatcher_PropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	_, rc, dispatcher, _ := setupWebhooks(t, 204, WebhookDispatcherConfig{})
+
+	_, err := dispatcher.DispatchDue(context.Background())
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	sc := spans[0].SpanContext()
+	require.Len(t, rc.requests, 1)
+	assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", rc.requests[0].Header.Get("traceparent"))
+}
+
+func TestWebhookDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	cfg := WebhookDispatcherConfig{MaxAttempts: 3, RetryBase: time.Minute}
+	repo, rc, dispatcher, now := setupWebhooks(t, 500, cfg)
+
+	_, err := dispatcher.DispatchDue(context.Background())
+	require.NoError(t, err)
+	d := repo.deliveries[0]
+	assert.Equal(t, models.DeliveryPending, d.Status)
+	assert.Equal(t, now.Add(time.Minute), d.NextAttemptAt)
+
+	// not due yet
+	n, _ := dispatcher.DispatchDue(context.Background())
+	assert.Equal(t, 0, n)
+
+	*now = now.Add(time.Minute)
+	dispatcher.DispatchDue(context.Background())
+	assert.Equal(t, now.Add(2*time.Minute), repo.deliveries[0].NextAttemptAt)
+
+	*now = now.Add(2 * time.Minute)
+	dispatcher.DispatchDue(context.Background())
+	d = repo.deliveries[0]
+	assert.Equal(t, models.DeliveryDead, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, "receiver answered 500", d.LastError)
+	assert.Len(t, rc.requests, 3)
+}
+
+func TestWebhookDispatcher_SlowReceiverStaysWithinTheLease(t *testing.T) {
+	repo, rc, dispatcher, now := setupWebhooks(t, 204, WebhookDispatcherConfig{Lease: 250 * time.Millisecond})
+	for range 4 {
+		publish(t.Context(), NewWebhookService(repo), models.EventOrderCreated, map[string]int{"order_id": 10249})
+	}
+	for i := range repo.deliveries {
+		repo.deliveries[i].NextAttemptAt = *now
+	}
+	rc.delay = 60 * time.Millisecond
+	dispatcher.client.Timeout = 100 * time.Millisecond
+	dispatcher.now = time.Now
+
+	start := time.Now()
+	n, err := dispatcher.DispatchDue(context.Background())
+	require.NoError(t, err)
+	// another worker may claim the batch once the lease ends, nothing is sent after that
+	assert.Less(t, time.Since(start), 250*time.Millisecond)
+	assert.GreaterOrEqual(t, n, 1)
+	assert.Less(t, n, 5)
+	assert.Len(t, rc.requests, n)
+
+	for _, d := range repo.deliveries[n:] {
+		assert.Equal(t, models.DeliveryPending, d.Status)
+		assert.Zero(t, d.Attempts)
+		assert.Nil(t, d.LockedUntil)
+	}
+}
+
+func TestWebhookDispatcher_DefaultLeaseCoversTheBatch(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(&memoryWebhookRepo{}, &http.Client{Timeout: 10 * time.Second}, WebhookDispatcherConfig{})
+	assert.Greater(t, dispatcher.cfg.Lease, 50*10*time.Second)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, RetryDelay(30*time.Second, time.Hour, 1))
+	assert.Equal(t, 4*time.Minute, RetryDelay(30*time.Second, time.Hour, 4))
+	assert.Equal(t, time.Hour, RetryDelay(30*time.Second, time.Hour, 20))
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/azizmasr1001/go-api-northwind/internal/netguard"
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/azizmasr1001/go-api-northwind/repositories"
+	"github.com/azizmasr1001/go-api-northwind/utils"
+)
+
+type WebhookService interface {
+	EventPublisher
+	Subscribe(ctx context.Context, userID int, input models.WebhookSubscriptionInput) (*models.WebhookSubscriptionCreated, error)
+	List(ctx context.Context, userID int) ([]models.WebhookSubscription, error)
+	Get(ctx context.Context, userID, id int) (*models.WebhookSubscription, error)
+	Unsubscribe(ctx context.Context, userID, id int) error
+	Deliveries(ctx context.Context, userID, id int, status string, page, limit int) ([]models.WebhookDelivery, int64, error)
+	Redeliver(ctx context.Context, userID, id, deliveryID int) error
+}
+
+type webhookService struct {
+	repo repositories.WebhookRepository
+}
+
+func NewWebhookService(repo repositories.WebhookRepository) WebhookService {
+	return &webhookService{repo}
+}
+
+// Publish queues one delivery per active subscription listening to the event.
+// WebhookDispatcher sends them in the background.
+func (s *webhookService) Publish(ctx context.Context, event models.Event) error {
+	subs, err := s.repo.ActiveSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Wants(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  event.OccurredAt,
+			CreatedAt:      event.OccurredAt,
+		})
+	}
+	return s.repo.EnqueueDeliveries(ctx, deliveries)
+}
+
+func (s *webhookService) Subscribe(ctx context.Context, userID int, input models.WebhookSubscriptionInput) (*models.WebhookSubscriptionCreated, error) {
+	// the dispatcher refuses these too, but a subscriber should hear it now
+	if err := netguard.CheckURL(ctx, input.URL); err != nil {
+		return nil, Invalid(CodeValidationFailed, "Validation failed",
+			utils.ErrorDetail{Field: "url", Message: "must be a public http(s) address"}).Wrap(err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	sub := models.WebhookSubscription{
+		UserID:    userID,
+		URL:       input.URL,
+		Events:    input.Events,
+		Secret:    "whsec_" + hex.EncodeToString(secret),
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CreateSubscription(ctx, &sub); err != nil {
+		return nil, err
+	}
+	return &models.WebhookSubscriptionCreated{WebhookSubscription: sub, Secret: sub.Secret}, nil
+}
+
+func (s *webhookService) List(ctx context.Context, userID int) ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions(ctx, userID)
+}
+
+func (s *webhookService) Get(ctx context.Context, userID, id int) (*models.WebhookSubscription, error) {
+	return s.repo.GetSubscription(ctx, userID, id)
+}
+
+func (s *webhookService) Unsubscribe(ctx context.Context, userID, id int) error {
+	return s.repo.DeleteSubscription(ctx, userID, id)
+}
+
+func (s *webhookService) Deliveries(ctx context.Context, userID, id int, status string, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.repo.GetSubscription(ctx, userID, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListDeliveries(ctx, id, status, page, limit)
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, userID, id, deliveryID int) error {
+	if _, err := s.repo.GetSubscription(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Requeue(ctx, id, deliveryID, time.Now().UTC())
+}
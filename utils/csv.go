@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WantsCSV reports whether the client asked for CSV via ?format=csv or the Accept header
+func WantsCSV(ctx *fiber.Ctx) bool {
+	format, _ := ExportFormat(ctx)
+	return format == "csv"
+}
+
+// CSVResponse writes a slice of structs as a CSV attachment, headers come from the json tags
+func CSVResponse(ctx *fiber.Ctx, filename string, rows interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(rows))
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("csv: expected a slice, got %s", v.Kind())
+	}
+
+	ctx.Set(fiber.HeaderContentType, MIMETextCSV+"; charset=utf-8")
+	ctx.Attachment(filename)
+
+	w := csv.NewWriter(ctx.Response().BodyWriter())
+	header, fields := CSVColumns(v.Type().Elem())
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := w.Write(CSVRecord(v.Index(i), fields)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// CSVColumns returns the JSON names of t and the struct field indexes they come from.
+// Fields tagged csv:"-" are left out of exports and imports.
+func CSVColumns(t reflect.Type) ([]string, [][]int) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var header []string
+	var fields [][]int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("csv") == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			h, idx := CSVColumns(f.Type)
+			header = append(header, h...)
+			for _, sub := range idx {
+				fields = append(fields, append([]int{i}, sub...))
+			}
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		header = append(header, name)
+		fields = append(fields, []int{i})
+	}
+	return header, fields
+}
+
+// CSVRecord formats the given fields of one struct value as CSV cells
+func CSVRecord(v reflect.Value, fields [][]int) []string {
+	v = reflect.Indirect(v)
+	record := make([]string, len(fields))
+	for i, idx := range fields {
+		record[i] = formatCell(v.FieldByIndex(idx))
+	}
+	return record
+}
+
+func formatCell(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrETagMismatch is returned by CheckIfMatch when the resource changed since it was read
+var ErrETagMismatch = errors.New("resource has been modified, fetch it again and retry")
+
+// ETag returns a strong entity tag built from a content hash of v's JSON form
+func ETag(v interface{}) string {
+	raw, _ := json.Marshal(v)
+	sum := sha256.Sum256(raw)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// WeakETag tags a partial representation, e.g. a response narrowed with ?fields=.
+// Weak tags satisfy If-None-Match but never If-Match.
+func WeakETag(v interface{}) string {
+	return "W/" + ETag(v)
+}
+
+// NotModified sets the ETag header and reports whether If-None-Match already
+// matches it, in which case the caller should answer 304
+func NotModified(ctx *fiber.Ctx, etag string) bool {
+	ctx.Set(fiber.HeaderETag, etag)
+	header := ctx.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && matchETag(header, etag, false)
+}
+
+// CheckIfMatch compares If-Match with the ETag of current using strong comparison.
+// A request without If-Match always passes.
+func CheckIfMatch(ctx *fiber.Ctx, current interface{}) error {
+	header := ctx.Get(fiber.HeaderIfMatch)
+	if header == "" || matchETag(header, ETag(current), true) {
+		return nil
+	}
+	return ErrETagMismatch
+}
+
+// matchETag checks a comma separated If-Match / If-None-Match list against etag
+func matchETag(header, etag string, strong bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strong {
+			if !strings.HasPrefix(candidate, "W/") && candidate == etag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
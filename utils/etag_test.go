@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var etagProduct = models.Product{ProductID: 1, ProductName: "Chai", UnitPrice: 18}
+
+func etagApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if NotModified(c, ETag(etagProduct)) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		return c.JSON(etagProduct)
+	})
+	app.Put("/", func(c *fiber.Ctx) error {
+		if err := CheckIfMatch(c, etagProduct); err != nil {
+			return c.SendStatus(fiber.StatusPreconditionFailed)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func TestETag_IfNoneMatch(t *testing.T) {
+	app := etagApp()
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 304, resp.StatusCode)
+}
+
+func TestETag_IfMatch(t *testing.T) {
+	app := etagApp()
+	etag := ETag(etagProduct)
+
+	for header, want := range map[string]int{
+		"":                 200,
+		etag:               200,
+		`"stale", ` + etag: 200,
+		"*":                200,
+		`"stale"`:          412,
+		"W/" + etag:        412,
+	} {
+		req := httptest.NewRequest("PUT", "/", nil)
+		if header != "" {
+			req.Header.Set("If-Match", header)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, want, resp.StatusCode, header)
+	}
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	MIMETextCSV = "text/csv"
+	MIMEXLSX    = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ExportFormat returns "csv" or "xlsx" when the client asked for a file via
+// ?format= or the Accept header, and "" for the regular JSON response. Any other
+// ?format= is reported rather than answered with JSON.
+func ExportFormat(ctx *fiber.Ctx) (string, []ErrorDetail) {
+	switch format := strings.ToLower(ctx.Query("format")); format {
+	case "csv", "xlsx":
+		return format, nil
+	case "json":
+		return "", nil
+	case "":
+	default:
+		return "", []ErrorDetail{{Field: "format", Message: "must be json, csv or xlsx"}}
+	}
+	switch ctx.Accepts(fiber.MIMEApplicationJSON, MIMETextCSV, MIMEXLSX) {
+	case MIMETextCSV:
+		return "csv", nil
+	case MIMEXLSX:
+		return "xlsx", nil
+	}
+	return "", nil
+}
+
+// RowEmitter hands one model value to the export writer
+type RowEmitter func(row interface{}) error
+
+// StreamExport writes rows of model as a CSV or XLSX attachment without buffering them all.
+// Column headers are the model's JSON tags, narrowed to fields when a FieldSet is given.
+// each is called once the response starts streaming, so a failure part way through
+// truncates the file rather than changing the status code. By then fiber has released
+// ctx, so each gets the request's context taken beforehand, without its deadline: a
+// download takes as long as it takes, a client that goes away fails the next write.
+func StreamExport(ctx *fiber.Ctx, format, name string, model interface{}, fields *FieldSet, each func(reqCtx context.Context, emit RowEmitter) error) error {
+	header, indexes := CSVColumns(reflect.TypeOf(model))
+	if fields != nil && len(fields.Keys) > 0 {
+		header, indexes = narrowColumns(header, indexes, fields.Keys)
+	}
+
+	reqCtx := context.WithoutCancel(ctx.UserContext())
+	filename := fmt.Sprintf("%s.%s", name, format)
+	ctx.Attachment(filename)
+
+	var write func(bw *bufio.Writer) error
+	switch format {
+	case "csv":
+		ctx.Set(fiber.HeaderContentType, MIMETextCSV+"; charset=utf-8")
+		write = func(bw *bufio.Writer) error { return writeCSV(bw, header, indexes, reqCtx, each) }
+	case "xlsx":
+		ctx.Set(fiber.HeaderContentType, MIMEXLSX)
+		write = func(bw *bufio.Writer) error { return writeXLSX(bw, header, indexes, reqCtx, each) }
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+	ctx.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		// the status is long gone, the log is the only trace of a truncated file
+		if err := write(bw); err != nil {
+			slog.ErrorContext(reqCtx, "Export failed part way through", "file", filename, "error", err)
+		}
+	})
+	return nil
+}
+
+func writeCSV(bw *bufio.Writer, header []string, indexes [][]int, reqCtx context.Context, each func(context.Context, RowEmitter) error) error {
+	w := csv.NewWriter(bw)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	err := each(reqCtx, func(row interface{}) error {
+		if err := w.Write(CSVRecord(reflect.ValueOf(row), indexes)); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeXLSX(bw *bufio.Writer, header []string, indexes [][]int, reqCtx context.Context, each func(context.Context, RowEmitter) error) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	cells := make([]interface{}, len(header))
+	for i, h := range header {
+		cells[i] = h
+	}
+	if err := sw.SetRow("A1", cells); err != nil {
+		return err
+	}
+
+	rowNum := 2
+	err = each(reqCtx, func(row interface{}) error {
+		v := reflect.Indirect(reflect.ValueOf(row))
+		values := make([]interface{}, len(indexes))
+		for i, idx := range indexes {
+			values[i] = xlsxCell(v.FieldByIndex(idx))
+		}
+		cell, err := excelize.CoordinatesToCellName(1, rowNum)
+		if err != nil {
+			return err
+		}
+		rowNum++
+		return sw.SetRow(cell, values)
+	})
+	if err != nil {
+		return err
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(bw)
+}
+
+func narrowColumns(header []string, indexes [][]int, keys []string) ([]string, [][]int) {
+	var h []string
+	var idx [][]int
+	for _, key := range keys {
+		for i, name := range header {
+			if name == key {
+				h = append(h, name)
+				idx = append(idx, indexes[i])
+				break
+			}
+		}
+	}
+	return h, idx
+}
+
+// xlsxCell keeps numbers and booleans typed so spreadsheets can sum them
+func xlsxCell(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
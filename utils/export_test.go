@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func exportApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		fields, _ := ParseFields(c.Query("fields"), models.Category{}, nil)
+		format, _ := ExportFormat(c)
+		if format == "" {
+			return c.SendStatus(204)
+		}
+		return StreamExport(c, format, "categories", models.Category{}, fields, func(_ context.Context, emit RowEmitter) error {
+			for _, cat := range []models.Category{{CategoryID: 1, CategoryName: "Beverages"}, {CategoryID: 2, CategoryName: "Condiments"}} {
+				if err := emit(cat); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	return app
+}
+
+func TestStreamExport_CSV(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?fields=category_name", nil)
+	req.Header.Set("Accept", "text/csv")
+	resp, err := exportApp().Test(req, -1)
+	assert.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "categories.csv")
+	assert.Equal(t, "category_name\nBeverages\nCondiments\n", string(body))
+}
+
+func TestStreamExport_XLSX(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?format=xlsx", nil)
+	resp, err := exportApp().Test(req, -1)
+	assert.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	f, err := excelize.OpenReader(bytes.NewReader(body))
+	assert.NoError(t, err)
+	rows, err := f.GetRows(f.GetSheetName(0))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"category_id", "category_name", "description"}, rows[0])
+	assert.Equal(t, "Condiments", rows[2][1])
+}
+
+func TestStreamExport_LogsAFailurePartWayThrough(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		format, _ := ExportFormat(c)
+		return StreamExport(c, format, "categories", models.Category{}, nil, func(_ context.Context, emit RowEmitter) error {
+			if err := emit(models.Category{CategoryID: 1, CategoryName: "Beverages"}); err != nil {
+				return err
+			}
+			return errors.New("connection reset by peer")
+		})
+	})
+
+	for _, format := range []string{"csv", "xlsx"} {
+		logs.Reset()
+		resp, err := app.Test(httptest.NewRequest("GET", "/?format="+format, nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Contains(t, logs.String(), "Export failed part way through", format)
+		assert.Contains(t, logs.String(), "categories."+format)
+		assert.Contains(t, logs.String(), "connection reset by peer")
+	}
+}
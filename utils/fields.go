@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FieldSet is the parsed form of a "?fields=" query for one model
+type FieldSet struct {
+	Keys     []string             // JSON names to keep in the response
+	Columns  []string             // DB columns to SELECT (always includes the primary key)
+	Includes map[string]*FieldSet // per include path, e.g. "details"
+}
+
+// ParseFields maps comma separated JSON field names to the columns of model.
+// Dotted names ("details.quantity") are resolved against the matching include model.
+func ParseFields(raw string, model interface{}, includes map[string]interface{}) (*FieldSet, []ErrorDetail) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	root := &FieldSet{}
+	var errs []ErrorDetail
+	columns := fieldColumns(model)
+
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if path, key, ok := strings.Cut(name, "."); ok {
+			incModel, found := includes[path]
+			if !found {
+				errs = append(errs, ErrorDetail{Field: "fields", Message: fmt.Sprintf("unknown include path: %s", path)})
+				continue
+			}
+			if root.Includes == nil {
+				root.Includes = map[string]*FieldSet{}
+			}
+			inc := root.Includes[path]
+			if inc == nil {
+				inc = &FieldSet{}
+				root.Includes[path] = inc
+			}
+			col, found := fieldColumns(incModel)[key]
+			if !found {
+				errs = append(errs, ErrorDetail{Field: "fields", Message: fmt.Sprintf("unknown field: %s", name)})
+				continue
+			}
+			inc.add(key, col)
+			continue
+		}
+
+		col, found := columns[name]
+		if !found {
+			errs = append(errs, ErrorDetail{Field: "fields", Message: fmt.Sprintf("unknown field: %s", name)})
+			continue
+		}
+		root.add(name, col)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	if len(root.Keys) > 0 {
+		root.Columns = withPrimaryKeys(root.Columns, model)
+	}
+	for path, inc := range root.Includes {
+		inc.Columns = withPrimaryKeys(inc.Columns, includes[path])
+	}
+	return root, nil
+}
+
+// GetFields returns the FieldSet stored by middlewares.ValidateFields, or nil
+func GetFields(ctx *fiber.Ctx) *FieldSet {
+	fs, _ := ctx.Locals("fields").(*FieldSet)
+	return fs
+}
+
+// SelectColumns returns the columns to SELECT, nil means all columns
+func (fs *FieldSet) SelectColumns() []string {
+	if fs == nil {
+		return nil
+	}
+	return fs.Columns
+}
+
+// Include returns the FieldSet for an include path, nil if none was requested
+func (fs *FieldSet) Include(path string) *FieldSet {
+	if fs == nil {
+		return nil
+	}
+	return fs.Includes[path]
+}
+
+// Pick trims data (a struct, pointer or slice) down to the requested JSON keys
+func (fs *FieldSet) Pick(data interface{}) (interface{}, error) {
+	if fs == nil || len(fs.Keys) == 0 {
+		return data, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if v := reflect.Indirect(reflect.ValueOf(data)); v.Kind() == reflect.Slice {
+		var rows []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, err
+		}
+		picked := make([]map[string]json.RawMessage, 0, len(rows))
+		for _, row := range rows {
+			picked = append(picked, fs.pickRow(row))
+		}
+		return picked, nil
+	}
+
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return nil, err
+	}
+	return fs.pickRow(row), nil
+}
+
+func (fs *FieldSet) pickRow(row map[string]json.RawMessage) map[string]json.RawMessage {
+	picked := make(map[string]json.RawMessage, len(fs.Keys))
+	for _, key := range fs.Keys {
+		if v, ok := row[key]; ok {
+			picked[key] = v
+		}
+	}
+	return picked
+}
+
+func (fs *FieldSet) add(key, column string) {
+	for _, k := range fs.Keys {
+		if k == key {
+			return
+		}
+	}
+	fs.Keys = append(fs.Keys, key)
+	fs.Columns = append(fs.Columns, column)
+}
+
+// fieldColumns builds a JSON name -> column name map from the json and gorm tags of model
+func fieldColumns(model interface{}) map[string]string {
+	out := map[string]string{}
+	t := reflect.Indirect(reflect.ValueOf(model)).Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			for k, v := range fieldColumns(reflect.New(f.Type).Elem().Interface()) {
+				out[k] = v
+			}
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		column := gormColumn(f.Tag.Get("gorm"))
+		if name == "" || name == "-" || column == "" {
+			continue
+		}
+		out[name] = column
+	}
+	return out
+}
+
+func withPrimaryKeys(columns []string, model interface{}) []string {
+	var keys []string
+	t := reflect.Indirect(reflect.ValueOf(model)).Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("gorm")
+		if !strings.Contains(tag, "primaryKey") {
+			continue
+		}
+		pk := gormColumn(tag)
+		found := false
+		for _, c := range columns {
+			if c == pk {
+				found = true
+				break
+			}
+		}
+		if !found {
+			keys = append(keys, pk)
+		}
+	}
+	return append(keys, columns...)
+}
+
+func gormColumn(tag string) string {
+	for _, part := range strings.Split(tag, ";") {
+		if strings.HasPrefix(part, "column:") {
+			return strings.TrimPrefix(part, "column:")
+		}
+	}
+	return ""
+}
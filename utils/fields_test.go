@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFields_MapsJSONNamesToColumns(t *testing.T) {
+	fs, errs := ParseFields("first_name, last_name", models.Employee{}, nil)
+
+	assert.Nil(t, errs)
+	assert.Equal(t, []string{"first_name", "last_name"}, fs.Keys)
+	assert.Equal(t, []string{"EmployeeID", "FirstName", "LastName"}, fs.Columns)
+}
+
+func TestParseFields_UnknownField(t *testing.T) {
+	fs, errs := ParseFields("first_name,salary", models.Employee{}, nil)
+
+	assert.Nil(t, fs)
+	assert.Len(t, errs, 1)
+}
+
+func TestParseFields_IncludePath(t *testing.T) {
+	includes := map[string]interface{}{"details": models.OrderDetail{}}
+	fs, errs := ParseFields("order_id,details.quantity", models.Order{}, includes)
+
+	assert.Nil(t, errs)
+	assert.Equal(t, []string{"order_id"}, fs.Keys)
+	assert.Equal(t, []string{"quantity"}, fs.Include("details").Keys)
+	assert.Equal(t, []string{"OrderID", "ProductID", "Quantity"}, fs.Include("details").Columns)
+}
+
+func TestFieldSet_Pick(t *testing.T) {
+	fs, _ := ParseFields("employee_id,first_name", models.Employee{}, nil)
+	data, err := fs.Pick([]models.Employee{{EmployeeID: 1, FirstName: "Nancy", Notes: "long text"}})
+
+	assert.NoError(t, err)
+	rows := data.([]map[string]json.RawMessage)
+	assert.Len(t, rows, 1)
+	assert.Len(t, rows[0], 2)
+	assert.Equal(t, `"Nancy"`, string(rows[0]["first_name"]))
+}
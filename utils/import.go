@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RowErrors collects errors per 1-based input row
+type RowErrors map[int][]ErrorDetail
+
+// DecodeRows reads a JSON array or, for Content-Type text/csv, a CSV file whose header
+// row uses the model's JSON names. Elements and cells that cannot be converted become
+// row errors.
+func DecodeRows[T any](ctx *fiber.Ctx) ([]T, RowErrors, error) {
+	if !strings.HasPrefix(ctx.Get(fiber.HeaderContentType), MIMETextCSV) {
+		// element by element, so one element of the wrong shape is a row error
+		// next to the validator's instead of failing the whole upload
+		var raw []json.RawMessage
+		if err := json.Unmarshal(ctx.Body(), &raw); err != nil {
+			return nil, nil, err
+		}
+		rows := make([]T, len(raw))
+		rowErrs := RowErrors{}
+		for i, element := range raw {
+			if err := json.Unmarshal(element, &rows[i]); err != nil {
+				rowErrs[i+1] = []ErrorDetail{jsonRowError(err)}
+			}
+		}
+		return rows, rowErrs, nil
+	}
+
+	r := csv.NewReader(strings.NewReader(string(ctx.Body())))
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("csv: missing header row: %v", err)
+	}
+
+	var model T
+	names, indexes := CSVColumns(reflect.TypeOf(model))
+	columns := make([][]int, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		for j, name := range names {
+			if name == h {
+				columns[i] = indexes[j]
+			}
+		}
+		if columns[i] == nil {
+			return nil, nil, fmt.Errorf("csv: unknown column %q", h)
+		}
+	}
+
+	var rows []T
+	rowErrs := RowErrors{}
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var row T
+		v := reflect.ValueOf(&row).Elem()
+		for i, cell := range record {
+			if err := setCell(v.FieldByIndex(columns[i]), cell); err != nil {
+				rowErrs[line] = append(rowErrs[line], ErrorDetail{Field: header[i], Message: err.Error()})
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrs, nil
+}
+
+// jsonRowError names the field of a value of the wrong type, other errors (a
+// non-object element, a bad date) are about the whole row
+func jsonRowError(err error) ErrorDetail {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return ErrorDetail{Field: typeErr.Field, Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+	}
+	return ErrorDetail{Message: strings.TrimPrefix(err.Error(), "json: ")}
+}
+
+// ValidateRows runs validate.Struct on every row not already rejected while decoding
+func ValidateRows[T any](rows []T, validate *validator.Validate, rowErrs RowErrors) {
+	for i, row := range rows {
+		line := i + 1
+		if _, bad := rowErrs[line]; bad {
+			continue
+		}
+		if err := validate.Struct(row); err != nil {
+			if errs, ok := err.(validator.ValidationErrors); ok {
+				rowErrs[line] = FormatValidationErrors(errs)
+				continue
+			}
+			rowErrs[line] = []ErrorDetail{{Message: err.Error()}}
+		}
+	}
+}
+
+func setCell(v reflect.Value, cell string) error {
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, cell); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid date %q", cell)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(cell)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", cell)
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", cell)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", cell)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported column type %s", v.Kind())
+	}
+	return nil
+}
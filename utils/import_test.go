@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRows_CSV(t *testing.T) {
+	var rows []models.Product
+	var rowErrs RowErrors
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		var err error
+		rows, rowErrs, err = DecodeRows[models.Product](c)
+		if err != nil {
+			return err
+		}
+		ValidateRows(rows, validator.New(), rowErrs)
+		return c.SendStatus(204)
+	})
+
+	body := "product_name,category_id,unit_price,discontinued\n" +
+		"Chai,1,18,false\n" +
+		",1,10,false\n" +
+		"Chang,x,19,false\n"
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	resp, err := app.Test(req, -1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "Chai", rows[0].ProductName)
+	assert.Equal(t, 1, *rows[0].CategoryID)
+	assert.Equal(t, 18.0, rows[0].UnitPrice)
+	assert.NotContains(t, rowErrs, 1)
+	assert.Equal(t, "ProductName", rowErrs[2][0].Field)
+	assert.Equal(t, "category_id", rowErrs[3][0].Field)
+}
+
+func TestDecodeRows_JSONElementErrors(t *testing.T) {
+	var rows []models.Product
+	var rowErrs RowErrors
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		var err error
+		rows, rowErrs, err = DecodeRows[models.Product](c)
+		if err != nil {
+			return err
+		}
+		ValidateRows(rows, validator.New(), rowErrs)
+		return c.SendStatus(204)
+	})
+
+	body := `[{"product_name":"Chai","category_id":1},{"product_name":"Chang","category_id":"x"},{"unit_price":1},7]`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, "Chai", rows[0].ProductName)
+	assert.NotContains(t, rowErrs, 1)
+	assert.Equal(t, []ErrorDetail{{Field: "category_id", Message: "expected int, got string"}}, rowErrs[2])
+	assert.Equal(t, "ProductName", rowErrs[3][0].Field)
+	assert.Len(t, rowErrs[4], 1)
+	assert.Empty(t, rowErrs[4][0].Field)
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// ErrUnsupportedPatch is returned for a Content-Type that is neither patch format
+var ErrUnsupportedPatch = errors.New("unsupported patch content type")
+
+// ApplyPatch applies body to the JSON form of original and decodes the result into target.
+// application/json-patch+json is treated as RFC 6902, application/merge-patch+json
+// and plain application/json as RFC 7396.
+func ApplyPatch(contentType string, body []byte, original, target interface{}) error {
+	doc, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var patched []byte
+	switch mediaType {
+	case MIMEJSONPatch:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return err
+		}
+		patched, err = patch.Apply(doc)
+		if err != nil {
+			return err
+		}
+	case MIMEMergePatch, "application/json", "":
+		if !json.Valid(body) || !strings.HasPrefix(strings.TrimSpace(string(body)), "{") {
+			return errors.New("merge patch must be a JSON object")
+		}
+		patched, err = jsonpatch.MergePatch(doc, body)
+		if err != nil {
+			return err
+		}
+	default:
+		return ErrUnsupportedPatch
+	}
+
+	return json.Unmarshal(patched, target)
+}
+
+// ChangedColumns lists the DB columns whose values differ between two models of the same type.
+// Primary keys are never reported.
+func ChangedColumns(before, after interface{}) []string {
+	b := reflect.Indirect(reflect.ValueOf(before))
+	a := reflect.Indirect(reflect.ValueOf(after))
+
+	var columns []string
+	for i := 0; i < b.NumField(); i++ {
+		if f := b.Type().Field(i); f.Anonymous && f.Type.Kind() == reflect.Struct {
+			columns = append(columns, ChangedColumns(b.Field(i).Interface(), a.Field(i).Interface())...)
+			continue
+		}
+		tag := b.Type().Field(i).Tag.Get("gorm")
+		column := gormColumn(tag)
+		if column == "" || strings.Contains(tag, "primaryKey") {
+			continue
+		}
+		if !reflect.DeepEqual(b.Field(i).Interface(), a.Field(i).Interface()) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/azizmasr1001/go-api-northwind/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPatch_MergePatchKeepsOmittedFields(t *testing.T) {
+	original := models.Product{ProductID: 1, ProductName: "Chai", UnitPrice: 18, UnitsInStock: 39}
+	var patched models.Product
+
+	err := ApplyPatch(MIMEMergePatch, []byte(`{"unit_price": 20}`), original, &patched)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Chai", patched.ProductName)
+	assert.Equal(t, 20.0, patched.UnitPrice)
+	assert.Equal(t, 39, patched.UnitsInStock)
+	assert.Equal(t, []string{"UnitPrice"}, ChangedColumns(original, patched))
+}
+
+func TestApplyPatch_MergePatchNullClearsField(t *testing.T) {
+	category := 1
+	original := models.Product{ProductID: 1, ProductName: "Chai", CategoryID: &category}
+	var patched models.Product
+
+	err := ApplyPatch("application/json", []byte(`{"category_id": null}`), original, &patched)
+
+	assert.NoError(t, err)
+	assert.Nil(t, patched.CategoryID)
+	assert.Equal(t, []string{"CategoryID"}, ChangedColumns(original, patched))
+}
+
+func TestApplyPatch_JSONPatch(t *testing.T) {
+	original := models.Employee{EmployeeID: 1, FirstName: "Nancy", LastName: "Davolio"}
+	var patched models.Employee
+
+	err := ApplyPatch(MIMEJSONPatch, []byte(`[{"op":"replace","path":"/last_name","value":"Smith"}]`), original, &patched)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Smith", patched.LastName)
+	assert.Equal(t, []string{"LastName"}, ChangedColumns(original, patched))
+}
+
+func TestChangedColumns_EmbeddedStruct(t *testing.T) {
+	by := 7
+	before := models.Category{CategoryID: 1, CategoryName: "Beverages"}
+	after := before
+	after.DeletedBy = &by
+
+	assert.Equal(t, []string{"DeletedBy"}, ChangedColumns(before, after))
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	var patched models.Employee
+
+	assert.ErrorIs(t, ApplyPatch("text/plain", []byte(`{}`), models.Employee{}, &patched), ErrUnsupportedPatch)
+	assert.Error(t, ApplyPatch(MIMEMergePatch, []byte(`[1]`), models.Employee{}, &patched))
+	assert.Error(t, ApplyPatch(MIMEJSONPatch, []byte(`[{"op":"test","path":"/first_name","value":"x"}]`), models.Employee{}, &patched))
+}
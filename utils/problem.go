@@ -0,0 +1,37 @@
+package utils
+
+import "github.com/gofiber/fiber/v2"
+
+const MIMEProblemJSON = "application/problem+json"
+
+// problemTypePrefix turns an error code into the problem type URI
+const problemTypePrefix = "urn:northwind:problem:"
+
+// Problem is an RFC 7807 error body. Code is the stable machine readable error code,
+// Errors lists the offending fields when there are any.
+type Problem struct {
+	Type     string        `json:"type"`
+	Title    string        `json:"title"`
+	Status   int           `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Instance string        `json:"instance,omitempty"`
+	Code     string        `json:"code"`
+	Errors   []ErrorDetail `json:"errors,omitempty"`
+}
+
+// NewProblem builds the problem for code, the instance is the request path
+func NewProblem(ctx *fiber.Ctx, status int, code, title string, errors []ErrorDetail) Problem {
+	return Problem{
+		Type:     problemTypePrefix + code,
+		Title:    title,
+		Status:   status,
+		Instance: ctx.Path(),
+		Code:     code,
+		Errors:   errors,
+	}
+}
+
+// WriteProblem sends p as application/problem+json
+func WriteProblem(ctx *fiber.Ctx, p Problem) error {
+	return ctx.Status(p.Status).JSON(p, MIMEProblemJSON)
+}
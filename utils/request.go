@@ -46,3 +46,14 @@ func FormatValidationErrors(errs validator.ValidationErrors) []ErrorDetail {
 	}
 	return details
 }
+
+// GetUserID returns the user_id claim stored by middlewares.ProtectedWithRedis, 0 if absent
+func GetUserID(ctx *fiber.Ctx) int {
+	switch id := ctx.Locals("user_id").(type) {
+	case float64:
+		return int(id)
+	case int:
+		return id
+	}
+	return 0
+}
@@ -13,13 +13,6 @@ type ErrorDetail struct {
 	Message string `json:"message"`
 }
 
-type StandardErrorResponse struct {
-	Status  string        `json:"status"`
-	Code    int           `json:"code"`
-	Message string        `json:"message"`
-	Errors  []ErrorDetail `json:"errors,omitempty"`
-}
-
 type StandardListResponse struct {
 	Status  string      `json:"status"`
 	Code    int         `json:"code"`
@@ -46,12 +39,3 @@ func ListResponse(ctx *fiber.Ctx, statusCode int, message string, data interface
 		"meta":    meta,
 	})
 }
-
-func ErrorResponse(ctx *fiber.Ctx, statusCode int, message string, errors []ErrorDetail) error {
-	return ctx.Status(statusCode).JSON(fiber.Map{
-		"status":  "error",
-		"code":    statusCode,
-		"message": message,
-		"errors":  errors,
-	})
-}